@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -12,8 +14,14 @@ import (
 )
 
 var (
-	sourceURI     string
-	sourceDialect string
+	sourceURI      string
+	sourceDialect  string
+	strictParse    bool
+	includeSchemas []string
+	excludeSchemas []string
+	filterFile     string
+	timeout        time.Duration
+	parallelism    int
 )
 
 var analyzeCmd = &cobra.Command{
@@ -22,8 +30,9 @@ var analyzeCmd = &cobra.Command{
 	Long: `Connect to a database or read a SQL file and extract its schema structure.
 
 The source can be:
-  - A database connection string (postgres://, mysql://, sqlserver://)
+  - A database connection string (postgres://, mysql://, sqlserver://, sqlite://)
   - A path to a SQL schema file (.sql)
+  - A directory of migration files, replayed in version order
 
 Output formats:
   - text: Human-readable table format (default)
@@ -36,27 +45,52 @@ Output formats:
   # Analyze a SQL file
   migrate analyze --source ./schema.sql --dialect postgres
 
+  # Replay a directory of migrations to get the resulting schema
+  migrate analyze --source ./migrations --dialect postgres
+
   # Output as JSON
-  migrate analyze --source postgres://localhost/mydb -o json`,
+  migrate analyze --source postgres://localhost/mydb -o json
+
+  # Only the objects in the sales and billing schemas
+  migrate analyze --source postgres://localhost/mydb --schemas sales,billing
+
+  # Leave out the objects a filter file excludes
+  migrate analyze --source postgres://localhost/mydb --filter migrate-filter.yaml
+
+  # Fail on the first unparseable statement instead of warning
+  migrate analyze --source ./schema.sql --dialect postgres --strict
+
+  # Give up after two minutes, reading eight tables at a time
+  migrate analyze --source postgres://localhost/mydb --timeout 2m --parallelism 8`,
 	RunE: runAnalyze,
 }
 
 func init() {
-	analyzeCmd.Flags().StringVar(&sourceURI, "source", "", "Database connection string or SQL file path (required)")
-	analyzeCmd.Flags().StringVar(&sourceDialect, "dialect", "", "SQL dialect for file parsing: postgres, mysql, sqlserver")
+	analyzeCmd.Flags().StringVar(&sourceURI, "source", "", "Database connection string, SQL file or migrations directory (required)")
+	analyzeCmd.Flags().StringVar(&sourceDialect, "dialect", "", "SQL dialect for file parsing: postgres, mysql, sqlserver, sqlite")
+	analyzeCmd.Flags().BoolVar(&strictParse, "strict", false, "Fail on the first SQL parse error instead of printing warnings")
+	analyzeCmd.Flags().StringSliceVar(&includeSchemas, "schemas", nil, "Only include objects in these schemas (comma-separated)")
+	analyzeCmd.Flags().StringSliceVar(&excludeSchemas, "exclude-schemas", nil, "Leave out objects in these schemas (comma-separated)")
+	analyzeCmd.Flags().StringVar(&filterFile, "filter", "", "YAML or JSON file of table, view, index and column patterns to include or exclude")
+	analyzeCmd.Flags().DurationVar(&timeout, "timeout", 0, "Give up reading a database after this long (e.g. 30s, 5m; 0 waits indefinitely)")
+	analyzeCmd.Flags().IntVar(&parallelism, "parallelism", db.DefaultParallelism, "Number of tables to read from a database at the same time")
 	_ = analyzeCmd.MarkFlagRequired("source")
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) error {
+	filter, err := loadFilter()
+	if err != nil {
+		return err
+	}
+
 	var s *schema.Schema
-	var err error
 
 	// Determine if source is a file or connection string
-	if isFile(sourceURI) {
+	if isSchemaPath(sourceURI) {
 		if sourceDialect == "" {
 			return fmt.Errorf("--dialect is required when analyzing a SQL file")
 		}
-		s, err = schema.ParseFile(sourceURI, sourceDialect)
+		s, err = parseSchemaFile(sourceURI, sourceDialect)
 		if err != nil {
 			return fmt.Errorf("failed to parse SQL file: %w", err)
 		}
@@ -67,16 +101,17 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(os.Stderr, "Connecting to %s database...\n", detectedDialect)
 		}
 
-		introspector, err := db.NewIntrospector(sourceURI)
+		s, err = introspect(cmd.Context(), sourceURI)
 		if err != nil {
-			return fmt.Errorf("failed to connect to database: %w", err)
+			return err
 		}
-		defer introspector.Close()
+	}
 
-		s, err = introspector.Introspect()
-		if err != nil {
-			return fmt.Errorf("failed to introspect database: %w", err)
-		}
+	if len(includeSchemas) > 0 || len(excludeSchemas) > 0 {
+		s = s.FilterSchemas(includeSchemas, excludeSchemas, schema.DefaultSchema(dialectOf(sourceURI)))
+	}
+	if !filter.IsEmpty() {
+		s = filter.Apply(s)
 	}
 
 	// Output in requested format
@@ -86,31 +121,53 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	case "yaml":
 		return schema.WriteYAML(os.Stdout, s)
 	case "sql":
-		dialect := sourceDialect
-		if dialect == "" {
-			dialect = detectDialect(sourceURI)
-		}
-		return schema.WriteSQL(os.Stdout, s, dialect)
+		return schema.WriteSQL(os.Stdout, s, dialectOf(sourceURI))
 	default:
 		return schema.WriteText(os.Stdout, s)
 	}
 }
 
-func isFile(path string) bool {
+// parseSchemaFile parses a SQL file or a directory of migration files. In strict mode the first parse error
+// is returned; otherwise parse errors are printed as warnings.
+func parseSchemaFile(path, dialect string) (*schema.Schema, error) {
+	s, diagnostics, err := schema.ParsePathWithOptions(path, dialect, schema.ParseOptions{Strict: strictParse})
+	if err != nil {
+		var pe *schema.ParseError
+		if errors.As(err, &pe) && pe.Context() != "" {
+			return nil, fmt.Errorf("%w\n%s", err, pe.Context())
+		}
+		return nil, err
+	}
+
+	for _, d := range diagnostics {
+		fmt.Fprintf(os.Stderr, "⚠ %s\n", d)
+		if ctx := d.Context(); ctx != "" {
+			fmt.Fprintf(os.Stderr, "%s\n", indent(ctx, "    "))
+		}
+	}
+	return s, nil
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
+
+// isSchemaPath reports whether path names a SQL file or migrations
+// directory rather than a connection string.
+func isSchemaPath(path string) bool {
 	// Check if it looks like a connection string
 	if strings.HasPrefix(path, "postgres://") ||
 		strings.HasPrefix(path, "postgresql://") ||
 		strings.HasPrefix(path, "mysql://") ||
-		strings.HasPrefix(path, "sqlserver://") {
+		strings.HasPrefix(path, "sqlserver://") ||
+		strings.HasPrefix(path, "sqlite://") ||
+		strings.HasPrefix(path, "sqlite3://") {
 		return false
 	}
 
-	// Check if file exists
-	info, err := os.Stat(path)
-	if err != nil {
-		return false
-	}
-	return !info.IsDir()
+	// Check if file or directory exists
+	_, err := os.Stat(path)
+	return err == nil
 }
 
 func detectDialect(connStr string) string {
@@ -121,7 +178,26 @@ func detectDialect(connStr string) string {
 		return "mysql"
 	case strings.HasPrefix(connStr, "sqlserver://"):
 		return "sqlserver"
+	case strings.HasPrefix(connStr, "sqlite://"), strings.HasPrefix(connStr, "sqlite3://"):
+		return "sqlite"
 	default:
 		return "unknown"
 	}
 }
+
+// loadFilter reads the --filter file, if one was given.
+func loadFilter() (*schema.Filter, error) {
+	if filterFile == "" {
+		return nil, nil
+	}
+	return schema.LoadFilter(filterFile)
+}
+
+// dialectOf returns the --dialect flag, or the dialect of a connection
+// string when it is not given.
+func dialectOf(uri string) string {
+	if sourceDialect != "" {
+		return sourceDialect
+	}
+	return detectDialect(uri)
+}
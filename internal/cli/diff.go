@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 
@@ -12,7 +14,11 @@ import (
 )
 
 var (
-	targetURI string
+	targetURI         string
+	schemaMap         map[string]string
+	renames           map[string]string
+	noRenameDetection bool
+	matchByStructure  bool
 )
 
 var diffCmd = &cobra.Command{
@@ -23,42 +29,85 @@ var diffCmd = &cobra.Command{
 Both source and target can be:
   - A database connection string
   - A path to a SQL schema file
+  - A directory of migration files
 
 The diff shows:
   - Added tables, columns, indexes, constraints
   - Removed tables, columns, indexes, constraints
-  - Modified columns (type changes, nullability, defaults)`,
+  - Modified columns (type changes, nullability, defaults)
+  - Modified indexes and foreign keys (columns, uniqueness, predicate,
+    referential actions), matched by name unless --match-by-structure
+    is given
+  - Renamed tables and columns, guessed from removed and added ones that
+    look alike unless --no-rename-detection is given`,
 	Example: `  # Compare two SQL files
   migrate diff --source schema_v1.sql --target schema_v2.sql --dialect postgres
 
+  # Check a migrations directory against the desired schema
+  migrate diff --source ./migrations --target schema.sql --dialect postgres
+
   # Compare two databases
   migrate diff --source postgres://localhost/db_old --target postgres://localhost/db_new
 
   # Generate migration SQL
-  migrate diff --source schema_v1.sql --target schema_v2.sql --dialect postgres -o sql`,
+  migrate diff --source schema_v1.sql --target schema_v2.sql --dialect postgres -o sql
+
+  # Compare only the sales schema, which the target keeps as app
+  migrate diff --source old.sql --target new.sql --dialect postgres --schemas app --map-schema sales=app
+
+  # Rename users.name to full_name instead of dropping it
+  migrate diff --source old.sql --target new.sql --dialect postgres -o sql --rename users.name=full_name`,
 	RunE: runDiff,
 }
 
 func init() {
-	diffCmd.Flags().StringVar(&sourceURI, "source", "", "Source schema (connection string or file path)")
-	diffCmd.Flags().StringVar(&targetURI, "target", "", "Target schema (connection string or file path)")
-	diffCmd.Flags().StringVar(&sourceDialect, "dialect", "", "SQL dialect for file parsing: postgres, mysql, sqlserver")
+	diffCmd.Flags().StringVar(&sourceURI, "source", "", "Source schema (connection string, file or migrations directory)")
+	diffCmd.Flags().StringVar(&targetURI, "target", "", "Target schema (connection string, file or migrations directory)")
+	diffCmd.Flags().StringVar(&sourceDialect, "dialect", "", "SQL dialect for file parsing: postgres, mysql, sqlserver, sqlite")
+	diffCmd.Flags().BoolVar(&strictParse, "strict", false, "Fail on the first SQL parse error instead of printing warnings")
+	diffCmd.Flags().StringSliceVar(&includeSchemas, "schemas", nil, "Only compare objects in these schemas (comma-separated)")
+	diffCmd.Flags().StringSliceVar(&excludeSchemas, "exclude-schemas", nil, "Leave out objects in these schemas (comma-separated)")
+	diffCmd.Flags().StringVar(&filterFile, "filter", "", "YAML or JSON file of table, view, index and column patterns to include or exclude")
+	diffCmd.Flags().StringToStringVar(&schemaMap, "map-schema", nil, "Compare source schema old as target schema new (old=new, repeatable)")
+	diffCmd.Flags().StringToStringVar(&renames, "rename", nil, "Treat a source table or column as renamed (table=new or table.column=new, repeatable)")
+	diffCmd.Flags().BoolVar(&noRenameDetection, "no-rename-detection", false, "Do not guess renamed tables and columns; only apply --rename")
+	diffCmd.Flags().BoolVar(&matchByStructure, "match-by-structure", false, "Pair indexes, foreign keys and constraints by definition rather than name")
+	diffCmd.Flags().DurationVar(&timeout, "timeout", 0, "Give up reading a database after this long (e.g. 30s, 5m; 0 waits indefinitely)")
+	diffCmd.Flags().IntVar(&parallelism, "parallelism", db.DefaultParallelism, "Number of tables to read from a database at the same time")
 	_ = diffCmd.MarkFlagRequired("source")
 	_ = diffCmd.MarkFlagRequired("target")
 }
 
 func runDiff(cmd *cobra.Command, args []string) error {
-	source, err := loadSchema(sourceURI, sourceDialect)
+	filter, err := loadFilter()
+	if err != nil {
+		return err
+	}
+
+	source, err := loadSchema(cmd.Context(), sourceURI, sourceDialect)
 	if err != nil {
 		return fmt.Errorf("failed to load source schema: %w", err)
 	}
 
-	target, err := loadSchema(targetURI, sourceDialect)
+	target, err := loadSchema(cmd.Context(), targetURI, sourceDialect)
 	if err != nil {
 		return fmt.Errorf("failed to load target schema: %w", err)
 	}
 
-	differ := diff.NewDiffer(source, target)
+	dialect := dialectOf(sourceURI)
+	differ := diff.NewDifferWithOptions(source, target, diff.Options{
+		DefaultSchema:     schema.DefaultSchema(dialect),
+		SchemaMap:         schemaMap,
+		Schemas:           includeSchemas,
+		ExcludeSchemas:    excludeSchemas,
+		Filter:            filter,
+		Renames:           renames,
+		NoRenameDetection: noRenameDetection,
+		MatchByStructure:  matchByStructure,
+	})
+	if err := differ.Validate(); err != nil {
+		return err
+	}
 	changes := differ.Compare()
 
 	// Output in requested format
@@ -68,10 +117,6 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	case "yaml":
 		return diff.WriteYAML(os.Stdout, changes)
 	case "sql":
-		dialect := sourceDialect
-		if dialect == "" {
-			dialect = detectDialect(sourceURI)
-		}
 		sqlGen := diff.NewSQLGenerator(dialect)
 		return sqlGen.WriteSQL(os.Stdout, changes)
 	default:
@@ -79,19 +124,42 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	}
 }
 
-func loadSchema(uri, dialect string) (*schema.Schema, error) {
-	if isFile(uri) {
+func loadSchema(ctx context.Context, uri, dialect string) (*schema.Schema, error) {
+	if isSchemaPath(uri) {
 		if dialect == "" {
 			return nil, fmt.Errorf("--dialect is required when using SQL files")
 		}
-		return schema.ParseFile(uri, dialect)
+		return parseSchemaFile(uri, dialect)
+	}
+	return introspect(ctx, uri)
+}
+
+// introspect reads the schema of a database within --timeout, reading
+// --parallelism tables at a time.
+func introspect(ctx context.Context, uri string) (*schema.Schema, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	introspector, err := db.NewIntrospector(uri)
+	introspector, err := db.NewIntrospectorWithOptions(ctx, uri, db.Options{Parallelism: parallelism})
 	if err != nil {
-		return nil, err
+		return nil, timeoutError(fmt.Errorf("failed to connect to database: %w", err))
 	}
 	defer introspector.Close()
 
-	return introspector.Introspect()
+	s, err := introspector.Introspect(ctx)
+	if err != nil {
+		return nil, timeoutError(fmt.Errorf("failed to introspect database: %w", err))
+	}
+	return s, nil
+}
+
+// timeoutError points out that err is due to --timeout.
+func timeoutError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s: %w", timeout, err)
+	}
+	return err
 }
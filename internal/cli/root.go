@@ -2,6 +2,10 @@
 package cli
 
 import (
+	"context"
+	"os"
+	"os/signal"
+
 	"github.com/spf13/cobra"
 )
 
@@ -21,6 +25,7 @@ Supported databases:
   - PostgreSQL
   - MySQL
   - SQL Server
+  - SQLite
 
 Examples:
   # Analyze a database schema
@@ -48,7 +53,10 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 }
 
-// Execute runs the root command.
+// Execute runs the root command. Interrupting the process cancels reading
+// a database.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return rootCmd.ExecuteContext(ctx)
 }
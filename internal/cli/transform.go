@@ -25,6 +25,7 @@ Supported transformations:
   - PostgreSQL ↔ MySQL
   - PostgreSQL ↔ SQL Server
   - MySQL ↔ SQL Server
+  - SQLite ↔ PostgreSQL, MySQL and SQL Server
 
 The transformer handles:
   - Data type mappings (e.g., SERIAL → AUTO_INCREMENT)
@@ -40,8 +41,10 @@ The transformer handles:
 
 func init() {
 	transformCmd.Flags().StringVar(&inputFile, "input", "", "Input SQL file path (required)")
-	transformCmd.Flags().StringVar(&fromDialect, "from", "", "Source dialect: postgres, mysql, sqlserver (required)")
-	transformCmd.Flags().StringVar(&toDialect, "to", "", "Target dialect: postgres, mysql, sqlserver (required)")
+	transformCmd.Flags().StringVar(&fromDialect, "from", "", "Source dialect: postgres, mysql, sqlserver, sqlite (required)")
+	transformCmd.Flags().StringVar(&toDialect, "to", "", "Target dialect: postgres, mysql, sqlserver, sqlite (required)")
+	transformCmd.Flags().BoolVar(&strictParse, "strict", false, "Fail on the first SQL parse error instead of printing warnings")
+	transformCmd.Flags().StringVar(&filterFile, "filter", "", "YAML or JSON file of table, view, index and column patterns to include or exclude")
 	_ = transformCmd.MarkFlagRequired("input")
 	_ = transformCmd.MarkFlagRequired("from")
 	_ = transformCmd.MarkFlagRequired("to")
@@ -50,17 +53,25 @@ func init() {
 func runTransform(cmd *cobra.Command, args []string) error {
 	// Validate dialects
 	if !isValidDialect(fromDialect) {
-		return fmt.Errorf("invalid source dialect: %s (use: postgres, mysql, sqlserver)", fromDialect)
+		return fmt.Errorf("invalid source dialect: %s (use: postgres, mysql, sqlserver, sqlite)", fromDialect)
 	}
 	if !isValidDialect(toDialect) {
-		return fmt.Errorf("invalid target dialect: %s (use: postgres, mysql, sqlserver)", toDialect)
+		return fmt.Errorf("invalid target dialect: %s (use: postgres, mysql, sqlserver, sqlite)", toDialect)
+	}
+
+	filter, err := loadFilter()
+	if err != nil {
+		return err
 	}
 
 	// Parse the input schema
-	s, err := schema.ParseFile(inputFile, fromDialect)
+	s, err := parseSchemaFile(inputFile, fromDialect)
 	if err != nil {
 		return fmt.Errorf("failed to parse input file: %w", err)
 	}
+	if !filter.IsEmpty() {
+		s = filter.Apply(s)
+	}
 
 	// Transform to target dialect
 	transformer := dialect.NewTransformer(fromDialect, toDialect)
@@ -81,7 +92,7 @@ func runTransform(cmd *cobra.Command, args []string) error {
 
 func isValidDialect(d string) bool {
 	switch d {
-	case "postgres", "mysql", "sqlserver":
+	case "postgres", "mysql", "sqlserver", "sqlite":
 		return true
 	default:
 		return false
@@ -4,11 +4,19 @@ package dialect
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/egoughnour/migrate/internal/schema"
 )
 
+// MySQL limits the length of comments.
+const (
+	maxMySQLTableComment  = 2048
+	maxMySQLColumnComment = 1024
+)
+
 // Transformer converts schemas between SQL dialects.
 type Transformer struct {
 	from string
@@ -26,21 +34,48 @@ func (t *Transformer) Transform(s *schema.Schema) (*schema.Schema, []string) {
 	var warnings []string
 
 	result := &schema.Schema{
+		Schemas: append([]string(nil), s.Schemas...),
 		Tables:  make([]schema.Table, len(s.Tables)),
 		Indexes: make([]schema.Index, len(s.Indexes)),
 		Views:   make([]schema.View, len(s.Views)),
 	}
 
+	// Enums and domains are kept as types in PostgreSQL and inlined into
+	// the columns that use them elsewhere
+	if t.to == "postgres" {
+		result.Enums = append(result.Enums, s.Enums...)
+		for _, d := range s.Domains {
+			domain := d
+			domain.Type, _ = t.transformType(d.Type, false, d.Name, "")
+			result.Domains = append(result.Domains, domain)
+		}
+	}
+
+	// Sequences exist in PostgreSQL and SQL Server only
+	for _, seq := range s.Sequences {
+		if t.to == "mysql" || t.to == "sqlite" {
+			warnings = append(warnings, fmt.Sprintf("sequence %s: %s has no sequences; dropped", seq.Name, dialectName(t.to)))
+			continue
+		}
+		sequence := seq
+		if seq.Type != "" {
+			sequence.Type, _ = t.transformType(seq.Type, false, seq.Name, "")
+		}
+		result.Sequences = append(result.Sequences, sequence)
+	}
+
 	// Transform tables
 	for i, table := range s.Tables {
-		transformed, tableWarnings := t.transformTable(&table)
+		transformed, tableWarnings := t.transformTable(s, result, &table)
 		result.Tables[i] = *transformed
 		warnings = append(warnings, tableWarnings...)
 	}
 
 	// Transform standalone indexes
 	for i, idx := range s.Indexes {
-		result.Indexes[i] = t.transformIndex(&idx)
+		transformed, idxWarnings := t.transformIndex(&idx)
+		result.Indexes[i] = transformed
+		warnings = append(warnings, idxWarnings...)
 	}
 
 	// Transform views (with warnings about potential incompatibilities)
@@ -50,10 +85,74 @@ func (t *Transformer) Transform(s *schema.Schema) (*schema.Schema, []string) {
 		warnings = append(warnings, viewWarnings...)
 	}
 
+	// Procedural code is not translated between dialects
+	if t.from == t.to {
+		result.Routines = append(result.Routines, s.Routines...)
+		result.Triggers = append(result.Triggers, s.Triggers...)
+	} else {
+		for _, r := range s.Routines {
+			warnings = append(warnings, fmt.Sprintf("%s %s: body must be rewritten for %s; dropped", strings.ToLower(r.Kind), r.Name, t.to))
+		}
+		for _, trigger := range s.Triggers {
+			warnings = append(warnings, fmt.Sprintf("trigger %s: body must be rewritten for %s; dropped", trigger.Name, t.to))
+		}
+	}
+
+	if t.to == "sqlite" {
+		warnings = append(warnings, dropSchemas(result)...)
+	}
+
 	return result, warnings
 }
 
-func (t *Transformer) transformTable(table *schema.Table) (*schema.Table, []string) {
+// dropSchemas moves every object of s into SQLite's main schema. SQLite's
+// other schemas are attached database files, which a script cannot create.
+func dropSchemas(s *schema.Schema) []string {
+	var warnings []string
+	for _, name := range s.Schemas {
+		warnings = append(warnings, fmt.Sprintf("schema %s: SQLite has no schemas; its objects are created in main", name))
+	}
+	s.Schemas = nil
+
+	for i := range s.Tables {
+		table := &s.Tables[i]
+		table.Schema = ""
+		for j := range table.ForeignKeys {
+			table.ForeignKeys[j].ReferencedSchema = ""
+		}
+		for j := range table.Indexes {
+			table.Indexes[j].Schema = ""
+		}
+	}
+	for i := range s.Indexes {
+		s.Indexes[i].Schema = ""
+	}
+	for i := range s.Views {
+		s.Views[i].Schema = ""
+	}
+	for i := range s.Triggers {
+		s.Triggers[i].Schema = ""
+	}
+	return warnings
+}
+
+// dialectName returns the display name of a dialect.
+func dialectName(dialect string) string {
+	switch dialect {
+	case "postgres":
+		return "PostgreSQL"
+	case "mysql":
+		return "MySQL"
+	case "sqlserver":
+		return "SQL Server"
+	case "sqlite":
+		return "SQLite"
+	default:
+		return dialect
+	}
+}
+
+func (t *Transformer) transformTable(source, target *schema.Schema, table *schema.Table) (*schema.Table, []string) {
 	var warnings []string
 
 	result := &schema.Table{
@@ -64,11 +163,47 @@ func (t *Transformer) transformTable(table *schema.Table) (*schema.Table, []stri
 		ForeignKeys: make([]schema.ForeignKey, len(table.ForeignKeys)),
 		Indexes:     make([]schema.Index, len(table.Indexes)),
 		Constraints: make([]schema.Constraint, len(table.Constraints)),
+		Comment:     table.Comment,
+	}
+	if t.from == "mysql" && t.to == "mysql" {
+		result.Engine, result.Charset, result.Collation = table.Engine, table.Charset, table.Collation
+	}
+	if t.from == "sqlite" && t.to == "sqlite" {
+		result.WithoutRowID, result.Strict = table.WithoutRowID, table.Strict
+	}
+	if t.to == "sqlite" && table.Comment != "" {
+		result.Comment = ""
+		warnings = append(warnings, fmt.Sprintf("table %s: SQLite has no comments; table comment dropped", table.Name))
+	}
+	if t.to == "mysql" && utf8.RuneCountInString(table.Comment) > maxMySQLTableComment {
+		result.Comment = string([]rune(table.Comment)[:maxMySQLTableComment])
+		warnings = append(warnings, fmt.Sprintf("table %s: comment truncated to MySQL's %d characters", table.Name, maxMySQLTableComment))
 	}
 
 	// Transform columns
 	for i, col := range table.Columns {
-		transformed, colWarnings := t.transformColumn(&col, table.Name)
+		var transformed *schema.Column
+		var colWarnings []string
+
+		enum := source.LookupEnum(col.Type)
+		domain := source.LookupDomain(col.Type)
+		switch {
+		case enum != nil && t.to != "postgres":
+			transformed, colWarnings = t.inlineEnum(result, &col, enum.Name, enum.Values)
+		case len(col.EnumValues) > 0 && t.to == "postgres":
+			transformed, colWarnings = t.extractEnum(target, table, &col)
+		case len(col.EnumValues) > 0 && (t.to == "sqlserver" || t.to == "sqlite"):
+			transformed, colWarnings = t.inlineEnum(result, &col, "", col.EnumValues)
+		case domain != nil && t.to != "postgres":
+			transformed, colWarnings = t.inlineDomain(result, &col, domain)
+		default:
+			transformed, colWarnings = t.transformColumn(&col, table)
+		}
+
+		if t.to == "sqlite" {
+			colWarnings = append(colWarnings, t.sqliteColumn(table, transformed)...)
+		}
+
 		result.Columns[i] = *transformed
 		warnings = append(warnings, colWarnings...)
 	}
@@ -78,17 +213,175 @@ func (t *Transformer) transformTable(table *schema.Table) (*schema.Table, []stri
 
 	// Transform indexes
 	for i, idx := range table.Indexes {
-		result.Indexes[i] = t.transformIndex(&idx)
+		transformed, idxWarnings := t.transformIndex(&idx)
+		result.Indexes[i] = transformed
+		warnings = append(warnings, idxWarnings...)
 	}
 
 	// Copy constraints
 	copy(result.Constraints, table.Constraints)
 
+	// STRICT tables only accept the basic storage class types
+	if result.Strict {
+		for _, col := range result.Columns {
+			if !isSQLiteStrictType(col.Type) {
+				warnings = append(warnings, fmt.Sprintf("table %s: STRICT dropped - column %s has type %s", table.Name, col.Name, col.Type))
+				result.Strict = false
+				break
+			}
+		}
+	}
+
 	return result, warnings
 }
 
-func (t *Transformer) transformColumn(col *schema.Column, tableName string) (*schema.Column, []string) {
+// isSQLiteStrictType reports whether a column of a STRICT table may have
+// the given type.
+func isSQLiteStrictType(dataType string) bool {
+	switch strings.ToUpper(dataType) {
+	case "INT", "INTEGER", "REAL", "TEXT", "BLOB", "ANY":
+		return true
+	}
+	return false
+}
+
+// sqliteColumn drops what a SQLite column cannot have: comments, and
+// autoincrement anywhere but on a single-column INTEGER PRIMARY KEY.
+func (t *Transformer) sqliteColumn(table *schema.Table, col *schema.Column) []string {
 	var warnings []string
+	if col.Comment != "" {
+		col.Comment = ""
+		warnings = append(warnings, fmt.Sprintf("%s.%s: SQLite has no comments; column comment dropped", table.Name, col.Name))
+	}
+	if col.IsIdentity && (table.PrimaryKey == nil || len(table.PrimaryKey.Columns) != 1 || !col.IsPrimaryKey) {
+		col.IsIdentity = false
+		warnings = append(warnings, fmt.Sprintf("%s.%s: SQLite only autoincrements a single-column INTEGER PRIMARY KEY; AUTOINCREMENT dropped", table.Name, col.Name))
+	}
+	return warnings
+}
+
+// inlineEnum replaces a column's enum type with a MySQL ENUM column, or
+// with a string column and a CHECK constraint listing the allowed values.
+func (t *Transformer) inlineEnum(table *schema.Table, col *schema.Column, enumName string, values []string) (*schema.Column, []string) {
+	result := *col
+	result.EnumValues = nil
+	if col.Default != nil {
+		def := stripCast(*col.Default, enumName)
+		result.Default = &def
+	}
+
+	quoted := make([]string, len(values))
+	maxLen := 1
+	for i, v := range values {
+		quoted[i] = schema.QuoteString(v)
+		if len(v) > maxLen {
+			maxLen = len(v)
+		}
+	}
+
+	source := "inline ENUM"
+	if enumName != "" {
+		source = "enum type " + enumName
+	}
+
+	if t.to == "mysql" {
+		result.Type = fmt.Sprintf("ENUM(%s)", strings.Join(quoted, ","))
+		result.EnumValues = values
+		return &result, []string{fmt.Sprintf("%s.%s: %s inlined as a MySQL ENUM column", table.Name, col.Name, source)}
+	}
+
+	if t.to == "sqlite" {
+		result.Type = "TEXT"
+	} else {
+		result.Type = fmt.Sprintf("NVARCHAR(%d)", maxLen)
+		for i := range quoted {
+			quoted[i] = "N" + quoted[i]
+		}
+	}
+	table.Constraints = append(table.Constraints, schema.Constraint{
+		Name:       fmt.Sprintf("CK_%s_%s", table.Name, col.Name),
+		Type:       "CHECK",
+		Columns:    []string{col.Name},
+		Expression: fmt.Sprintf("%s IN (%s)", t.quoteIdent(col.Name), strings.Join(quoted, ", ")),
+	})
+	return &result, []string{fmt.Sprintf("%s.%s: %s mapped to %s with a CHECK constraint - no enum types in %s",
+		table.Name, col.Name, source, result.Type, t.to)}
+}
+
+// extractEnum turns a MySQL inline ENUM column into a PostgreSQL enum type
+// named after the table and column.
+func (t *Transformer) extractEnum(target *schema.Schema, table *schema.Table, col *schema.Column) (*schema.Column, []string) {
+	name := table.Name + "_" + col.Name
+	target.Enums = append(target.Enums, schema.Enum{
+		Name:   name,
+		Schema: table.Schema,
+		Values: col.EnumValues,
+	})
+
+	result := *col
+	result.Type = name
+	result.EnumValues = nil
+	return &result, []string{fmt.Sprintf("%s.%s: inline ENUM mapped to enum type %s", table.Name, col.Name, name)}
+}
+
+// inlineDomain replaces a column's domain with its base type, moving the
+// domain's NOT NULL, default and CHECK constraints onto the column.
+func (t *Transformer) inlineDomain(table *schema.Table, col *schema.Column, domain *schema.Domain) (*schema.Column, []string) {
+	base := *col
+	base.Type = domain.Type
+	if domain.NotNull {
+		base.Nullable = false
+	}
+	if base.Default == nil && domain.Default != nil {
+		base.Default = domain.Default
+	}
+
+	result, warnings := t.transformColumn(&base, table)
+
+	valueRef := regexp.MustCompile(`(?i)\bVALUE\b`)
+	for _, c := range domain.Checks {
+		name := c.Name
+		if name != "" {
+			name = fmt.Sprintf("%s_%s", col.Name, name)
+		}
+		table.Constraints = append(table.Constraints, schema.Constraint{
+			Name:       name,
+			Type:       "CHECK",
+			Columns:    []string{col.Name},
+			Expression: valueRef.ReplaceAllString(c.Expression, t.quoteIdent(col.Name)),
+		})
+	}
+
+	warnings = append(warnings, fmt.Sprintf("%s.%s: domain %s inlined as %s - no domains in %s",
+		table.Name, col.Name, domain.Name, result.Type, t.to))
+	return result, warnings
+}
+
+// stripCast removes a trailing ::type cast from a default value.
+func stripCast(def, typeName string) string {
+	if typeName == "" {
+		return def
+	}
+	if i := strings.LastIndex(def, "::"); i >= 0 && strings.EqualFold(strings.Trim(def[i+2:], `"`), typeName) {
+		return def[:i]
+	}
+	return def
+}
+
+func (t *Transformer) quoteIdent(name string) string {
+	switch t.to {
+	case "mysql":
+		return "`" + name + "`"
+	case "sqlserver":
+		return "[" + name + "]"
+	default:
+		return `"` + name + `"`
+	}
+}
+
+func (t *Transformer) transformColumn(col *schema.Column, table *schema.Table) (*schema.Column, []string) {
+	var warnings []string
+	tableName := table.Name
 
 	result := &schema.Column{
 		Name:         col.Name,
@@ -97,24 +390,166 @@ func (t *Transformer) transformColumn(col *schema.Column, tableName string) (*sc
 		IsPrimaryKey: col.IsPrimaryKey,
 		IsUnique:     col.IsUnique,
 		IsIdentity:   col.IsIdentity,
+		EnumValues:   col.EnumValues,
 		Comment:      col.Comment,
 	}
+	if t.from == "mysql" && t.to == "mysql" {
+		result.Charset, result.Collation = col.Charset, col.Collation
+	}
+	if t.from == "sqlite" && t.to == "sqlite" {
+		result.Collation = col.Collation
+	}
+	if t.from == "sqlserver" && t.to == "sqlserver" {
+		result.DefaultName = col.DefaultName
+	}
 
 	// Transform data type
 	result.Type, warnings = t.transformType(col.Type, col.IsIdentity, tableName, col.Name)
+	if col.OnUpdate != "" {
+		if t.to == "mysql" {
+			result.OnUpdate = col.OnUpdate
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s.%s: ON UPDATE %s dropped - not supported in %s", tableName, col.Name, col.OnUpdate, t.to))
+		}
+	}
+
+	if t.to == "mysql" && utf8.RuneCountInString(col.Comment) > maxMySQLColumnComment {
+		result.Comment = string([]rune(col.Comment)[:maxMySQLColumnComment])
+		warnings = append(warnings, fmt.Sprintf("%s.%s: comment truncated to MySQL's %d characters", tableName, col.Name, maxMySQLColumnComment))
+	}
 
 	// Transform default value if needed
 	if col.Default != nil {
 		result.Default = t.transformDefault(*col.Default)
 	}
 
+	if col.Generated != nil {
+		generated, generatedWarnings := t.transformGenerated(col, tableName)
+		result.Generated = generated
+		warnings = append(warnings, generatedWarnings...)
+	}
+
+	if col.Identity != nil {
+		identity, identityWarnings := t.transformIdentity(col.Identity, tableName, col.Name)
+		result.Identity = identity
+		warnings = append(warnings, identityWarnings...)
+		if identity != nil {
+			// The generator adds the identity clause to the base type
+			result.Type, _ = t.transformType(col.Type, false, tableName, col.Name)
+		}
+	}
+
+	// nextval() defaults only exist in PostgreSQL. SQL Server draws from
+	// the sequence with NEXT VALUE FOR; MySQL and SQLite fall back to
+	// AUTO_INCREMENT, which they allow on one key column only.
+	if seqSchema, seqName, ok := schema.SequenceDefaultName(defaultString(col.Default)); ok && t.to != "postgres" {
+		switch t.to {
+		case "sqlserver":
+			def := "NEXT VALUE FOR " + t.quoteIdent(seqName)
+			if seqSchema != "" {
+				def = "NEXT VALUE FOR " + t.quoteIdent(seqSchema) + "." + t.quoteIdent(seqName)
+			}
+			result.Default = &def
+			result.IsIdentity = false
+			result.Type, _ = t.transformType(col.Type, false, tableName, col.Name)
+		default:
+			result.Default = nil
+			if !soleKeyColumn(table, col.Name) {
+				warnings = append(warnings, fmt.Sprintf("%s.%s: sequence default %s dropped - sequences not supported in %s", tableName, col.Name, *col.Default, t.to))
+				break
+			}
+			keyword := "AUTO_INCREMENT"
+			if t.to == "sqlite" {
+				keyword = "AUTOINCREMENT"
+			}
+			result.IsIdentity = true
+			result.Type, _ = t.transformType(col.Type, true, tableName, col.Name)
+			warnings = append(warnings, fmt.Sprintf("%s.%s: sequence default %s replaced by %s", tableName, col.Name, *col.Default, keyword))
+		}
+	}
+
 	return result, warnings
 }
 
+// soleKeyColumn reports whether a column is the whole primary key of
+// its table.
+func soleKeyColumn(table *schema.Table, name string) bool {
+	pk := table.PrimaryKey
+	return pk != nil && len(pk.Columns) == 1 && strings.EqualFold(pk.Columns[0], name)
+}
+
+// transformGenerated carries a generated column over to the target
+// dialect. PostgreSQL only has STORED generated columns, and SQL Server
+// computed columns have no declared type for other dialects to use.
+func (t *Transformer) transformGenerated(col *schema.Column, tableName string) (*schema.Generated, []string) {
+	var warnings []string
+	result := *col.Generated
+
+	if t.to == "postgres" && !result.Stored {
+		result.Stored = true
+		warnings = append(warnings, fmt.Sprintf("%s.%s: PostgreSQL has no VIRTUAL generated columns; converted to STORED", tableName, col.Name))
+	}
+	if col.Type == "" && t.to != "sqlserver" {
+		warnings = append(warnings, fmt.Sprintf("%s.%s: computed column has no declared type; add one", tableName, col.Name))
+	}
+	if t.from != t.to {
+		warnings = append(warnings, fmt.Sprintf("%s.%s: generation expression may contain %s-specific SQL that requires manual review", tableName, col.Name, t.from))
+	}
+
+	return &result, warnings
+}
+
+// transformIdentity carries identity options over to the target dialect.
+// MySQL's AUTO_INCREMENT takes no options; SQL Server's IDENTITY only has
+// a seed and an increment.
+func (t *Transformer) transformIdentity(identity *schema.Identity, tableName, colName string) (*schema.Identity, []string) {
+	result := *identity
+
+	switch t.to {
+	case "mysql":
+		if identity.Generation == "ALWAYS" || !identity.IsDefault() {
+			return nil, []string{fmt.Sprintf("%s.%s: MySQL AUTO_INCREMENT does not support identity options; dropped", tableName, colName)}
+		}
+		return nil, nil
+
+	case "sqlite":
+		if identity.Generation == "ALWAYS" || !identity.IsDefault() {
+			return nil, []string{fmt.Sprintf("%s.%s: SQLite AUTOINCREMENT does not support identity options; dropped", tableName, colName)}
+		}
+		return nil, nil
+
+	case "sqlserver":
+		var warnings []string
+		if identity.Generation == "ALWAYS" {
+			warnings = append(warnings, fmt.Sprintf("%s.%s: SQL Server identity columns are always generated; GENERATED ALWAYS is implied", tableName, colName))
+		}
+		if identity.MinValue != nil || identity.MaxValue != nil || identity.Cache != nil || identity.Cycle {
+			warnings = append(warnings, fmt.Sprintf("%s.%s: SQL Server IDENTITY only supports a seed and increment; other options dropped", tableName, colName))
+		}
+		result.Generation = ""
+		result.SequenceOptions = schema.SequenceOptions{Start: identity.Start, Increment: identity.Increment}
+		return &result, warnings
+	}
+
+	return &result, nil
+}
+
+func defaultString(def *string) string {
+	if def == nil {
+		return ""
+	}
+	return *def
+}
+
 func (t *Transformer) transformType(dataType string, isIdentity bool, tableName, colName string) (string, []string) {
 	var warnings []string
 	upper := strings.ToUpper(dataType)
 
+	// Types with string literals, such as ENUM('a','b'), are kept as written
+	if strings.Contains(dataType, "'") {
+		return dataType, nil
+	}
+
 	// Handle identity/auto-increment types
 	if isIdentity {
 		return t.mapIdentityType(upper), nil
@@ -122,6 +557,9 @@ func (t *Transformer) transformType(dataType string, isIdentity bool, tableName,
 
 	// Map types based on source and target dialects
 	mapped := t.mapDataType(upper)
+	if dataType == "" && t.from == "sqlite" && t.to != "sqlite" {
+		warnings = append(warnings, fmt.Sprintf("%s.%s: column has no type in SQLite; mapped to %s", tableName, colName, mapped))
+	}
 
 	// Check for potential data loss
 	if warning := t.checkDataLoss(upper, mapped, tableName, colName); warning != "" {
@@ -132,24 +570,38 @@ func (t *Transformer) transformType(dataType string, isIdentity bool, tableName,
 }
 
 func (t *Transformer) mapIdentityType(dataType string) string {
-	isBig := strings.Contains(dataType, "BIG")
+	upper := strings.ToUpper(dataType)
+	isBig := strings.Contains(upper, "BIG") || upper == "SERIAL8" || upper == "INT8"
+	isSmall := strings.Contains(upper, "SMALL") || upper == "SERIAL2" || upper == "INT2"
 
 	switch t.to {
 	case "postgres":
 		if isBig {
 			return "BIGSERIAL"
 		}
+		if isSmall {
+			return "SMALLSERIAL"
+		}
 		return "SERIAL"
 	case "mysql":
 		if isBig {
 			return "BIGINT AUTO_INCREMENT"
 		}
+		if isSmall {
+			return "SMALLINT AUTO_INCREMENT"
+		}
 		return "INT AUTO_INCREMENT"
 	case "sqlserver":
 		if isBig {
 			return "BIGINT IDENTITY(1,1)"
 		}
+		if isSmall {
+			return "SMALLINT IDENTITY(1,1)"
+		}
 		return "INT IDENTITY(1,1)"
+	case "sqlite":
+		// SQLite integers are 64-bit whatever their declared size
+		return "INTEGER"
 	default:
 		return dataType
 	}
@@ -199,6 +651,9 @@ func (t *Transformer) normalizeType(dataType string) string {
 	case dataType == "CHAR" || strings.HasPrefix(dataType, "CHAR(") ||
 		strings.HasPrefix(dataType, "NCHAR"):
 		return dataType
+	case strings.HasPrefix(dataType, "CHARACTER("):
+		// PostgreSQL's format_type spelling of CHAR(n)
+		return "CHAR" + strings.TrimPrefix(dataType, "CHARACTER")
 
 	// Date/Time types
 	case dataType == "TIMESTAMP" || dataType == "TIMESTAMP WITHOUT TIME ZONE" ||
@@ -236,11 +691,35 @@ func (t *Transformer) normalizeType(dataType string) string {
 	case dataType == "UUID" || dataType == "UNIQUEIDENTIFIER":
 		return "UUID"
 
+	case t.from == "sqlite" && t.to != "sqlite":
+		return sqliteAffinityType(dataType)
+
 	default:
 		return dataType
 	}
 }
 
+// sqliteAffinityType maps a SQLite type name none of the other dialects
+// share, such as UNSIGNED BIG INT or CLOB, by the rules SQLite uses to
+// determine a column's affinity from its declared type.
+func sqliteAffinityType(dataType string) string {
+	switch {
+	case strings.Contains(dataType, "INT"):
+		if strings.Contains(dataType, "BIG") {
+			return "BIGINT"
+		}
+		return "INTEGER"
+	case strings.Contains(dataType, "CHAR") || strings.Contains(dataType, "CLOB") || strings.Contains(dataType, "TEXT"):
+		return "TEXT"
+	case dataType == "" || strings.Contains(dataType, "BLOB"):
+		return "BINARY"
+	case strings.Contains(dataType, "REAL") || strings.Contains(dataType, "FLOA") || strings.Contains(dataType, "DOUB"):
+		return "DOUBLE"
+	default:
+		return "NUMERIC"
+	}
+}
+
 func (t *Transformer) toTargetType(normalized string) string {
 	switch t.to {
 	case "postgres":
@@ -249,6 +728,8 @@ func (t *Transformer) toTargetType(normalized string) string {
 		return t.toMySQL(normalized)
 	case "sqlserver":
 		return t.toSQLServer(normalized)
+	case "sqlite":
+		return t.toSQLite(normalized)
 	default:
 		return normalized
 	}
@@ -326,6 +807,28 @@ func (t *Transformer) toSQLServer(normalized string) string {
 	}
 }
 
+// toSQLite maps types to the names of SQLite's storage classes, which
+// determine how values are stored. Dates and times are stored as ISO 8601
+// text, as SQLite's date functions produce.
+func (t *Transformer) toSQLite(normalized string) string {
+	switch {
+	case normalized == "INTEGER" || normalized == "BIGINT" || normalized == "SMALLINT" || normalized == "BOOLEAN":
+		return "INTEGER"
+	case normalized == "REAL" || normalized == "DOUBLE":
+		return "REAL"
+	case strings.HasPrefix(normalized, "DECIMAL") || strings.HasPrefix(normalized, "NUMERIC"):
+		return "NUMERIC"
+	case normalized == "BINARY":
+		return "BLOB"
+	case normalized == "TEXT" || normalized == "JSON" || normalized == "UUID" ||
+		normalized == "TIMESTAMP" || normalized == "TIMESTAMP_TZ" || normalized == "DATE" || normalized == "TIME" ||
+		strings.HasPrefix(normalized, "VARCHAR") || strings.HasPrefix(normalized, "CHAR") || strings.HasPrefix(normalized, "NCHAR"):
+		return "TEXT"
+	default:
+		return normalized
+	}
+}
+
 func (t *Transformer) transformDefault(defaultVal string) *string {
 	upper := strings.ToUpper(defaultVal)
 
@@ -340,6 +843,8 @@ func (t *Transformer) transformDefault(defaultVal string) *string {
 			result = "CURRENT_TIMESTAMP"
 		case "sqlserver":
 			result = "GETDATE()"
+		case "sqlite":
+			result = "CURRENT_TIMESTAMP"
 		}
 		return &result
 
@@ -352,7 +857,7 @@ func (t *Transformer) transformDefault(defaultVal string) *string {
 			} else {
 				result = "0"
 			}
-		case "sqlserver":
+		case "sqlserver", "sqlite":
 			if upper == "TRUE" {
 				result = "1"
 			} else {
@@ -372,23 +877,102 @@ func (t *Transformer) transformDefault(defaultVal string) *string {
 			result = "UUID()"
 		case "sqlserver":
 			result = "NEWID()"
+		case "sqlite":
+			result = "(lower(hex(randomblob(16))))"
 		}
 		return &result
 	}
 
+	// SQLite only takes literals and CURRENT_* keywords as bare defaults
+	if t.to == "sqlite" && !isLiteralDefault(defaultVal) {
+		wrapped := "(" + defaultVal + ")"
+		return &wrapped
+	}
+
 	return &defaultVal
 }
 
-func (t *Transformer) transformIndex(idx *schema.Index) schema.Index {
-	return schema.Index{
+// isLiteralDefault reports whether a default is a literal, a CURRENT_*
+// keyword or an expression already in parentheses.
+func isLiteralDefault(def string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(def))
+	switch {
+	case upper == "" || upper == "NULL" || upper == "TRUE" || upper == "FALSE" ||
+		upper == "CURRENT_TIMESTAMP" || upper == "CURRENT_DATE" || upper == "CURRENT_TIME":
+		return true
+	case strings.HasPrefix(upper, "(") && strings.HasSuffix(upper, ")"):
+		return true
+	case strings.HasPrefix(upper, "'") || strings.HasPrefix(upper, "X'"):
+		return strings.HasSuffix(upper, "'") && !strings.Contains(upper, "'::")
+	}
+	_, err := strconv.ParseFloat(upper, 64)
+	return err == nil
+}
+
+func (t *Transformer) transformIndex(idx *schema.Index) (schema.Index, []string) {
+	var warnings []string
+
+	result := schema.Index{
 		Name:      idx.Name,
 		Table:     idx.Table,
 		Schema:    idx.Schema,
-		Columns:   idx.Columns,
+		Include:   idx.Include,
+		Where:     idx.Where,
 		IsUnique:  idx.IsUnique,
 		IsPrimary: idx.IsPrimary,
 		Type:      t.mapIndexType(idx.Type),
 	}
+
+	// Drop key options the target dialect has no syntax for
+	keys := append([]schema.IndexColumn(nil), idx.KeyColumns()...)
+	for i, k := range keys {
+		if t.to != "postgres" {
+			if k.OpClass != "" {
+				warnings = append(warnings, fmt.Sprintf("index %s: operator class %s dropped - not supported in %s", idx.Name, k.OpClass, t.to))
+				k.OpClass = ""
+			}
+			if k.Nulls != "" {
+				warnings = append(warnings, fmt.Sprintf("index %s: NULLS %s ordering dropped - not supported in %s", idx.Name, k.Nulls, t.to))
+				k.Nulls = ""
+			}
+			if k.Collation != "" && t.to != "sqlite" {
+				warnings = append(warnings, fmt.Sprintf("index %s: collation %s dropped from key - not supported in %s", idx.Name, k.Collation, t.to))
+				k.Collation = ""
+			}
+		}
+		if k.Expression != "" && t.to == "sqlserver" {
+			warnings = append(warnings, fmt.Sprintf("index %s: expression key %s needs a computed column in SQL Server", idx.Name, k.Expression))
+		}
+		if k.Length > 0 && t.to != "mysql" {
+			warnings = append(warnings, fmt.Sprintf("index %s: prefix length on %s dropped - indexes the full column in %s", idx.Name, k.Name, t.to))
+			k.Length = 0
+		}
+		keys[i] = k
+	}
+	result.SetKeys(keys)
+
+	if t.to == "sqlite" {
+		if idx.Type != "" {
+			warnings = append(warnings, fmt.Sprintf("index %s: index type %s dropped - SQLite only has B-tree indexes", idx.Name, idx.Type))
+		}
+		if len(idx.Include) > 0 {
+			warnings = append(warnings, fmt.Sprintf("index %s: INCLUDE columns dropped - not supported in SQLite", idx.Name))
+			result.Include = nil
+		}
+	}
+
+	if t.to == "mysql" {
+		if idx.Where != "" {
+			warnings = append(warnings, fmt.Sprintf("index %s: partial index predicate dropped - MySQL has no filtered indexes", idx.Name))
+			result.Where = ""
+		}
+		if len(idx.Include) > 0 {
+			warnings = append(warnings, fmt.Sprintf("index %s: INCLUDE columns dropped - not supported in MySQL", idx.Name))
+			result.Include = nil
+		}
+	}
+
+	return result, warnings
 }
 
 func (t *Transformer) mapIndexType(indexType string) string {
@@ -411,6 +995,8 @@ func (t *Transformer) mapIndexType(indexType string) string {
 			return "" // Let SQL Server choose default
 		}
 		return ""
+	case "sqlite":
+		return ""
 	default:
 		return indexType
 	}
@@ -449,6 +1035,12 @@ func (t *Transformer) checkDataLoss(original, mapped, tableName, colName string)
 
 	case original == "DOUBLE PRECISION" && t.to == "sqlserver":
 		return fmt.Sprintf("%s.%s: DOUBLE PRECISION mapped to FLOAT - verify precision requirements", tableName, colName)
+
+	case t.to == "sqlite" && mapped == "TEXT" && (strings.HasPrefix(original, "TIME") || strings.HasPrefix(original, "DATE")):
+		return fmt.Sprintf("%s.%s: %s stored as ISO 8601 TEXT - SQLite has no date/time types", tableName, colName, original)
+
+	case t.to == "sqlite" && mapped == "NUMERIC" && strings.Contains(original, ","):
+		return fmt.Sprintf("%s.%s: %s mapped to NUMERIC - SQLite does not keep decimal precision", tableName, colName, original)
 	}
 
 	return ""
@@ -456,7 +1048,7 @@ func (t *Transformer) checkDataLoss(original, mapped, tableName, colName string)
 
 // SupportedDialects returns the list of supported SQL dialects.
 func SupportedDialects() []string {
-	return []string{"postgres", "mysql", "sqlserver"}
+	return []string{"postgres", "mysql", "sqlserver", "sqlite"}
 }
 
 // IsSupported checks if a dialect is supported.
@@ -11,69 +11,302 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// Changes represents the differences between two schemas.
+// Changes represents the differences between two schemas. Added objects
+// are listed in target order, removed and modified ones in source order.
 type Changes struct {
-	AddedTables    []schema.Table  `json:"added_tables,omitempty" yaml:"added_tables,omitempty"`
-	RemovedTables  []schema.Table  `json:"removed_tables,omitempty" yaml:"removed_tables,omitempty"`
-	ModifiedTables []TableChanges  `json:"modified_tables,omitempty" yaml:"modified_tables,omitempty"`
-	AddedIndexes   []schema.Index  `json:"added_indexes,omitempty" yaml:"added_indexes,omitempty"`
-	RemovedIndexes []schema.Index  `json:"removed_indexes,omitempty" yaml:"removed_indexes,omitempty"`
-	AddedViews     []schema.View   `json:"added_views,omitempty" yaml:"added_views,omitempty"`
-	RemovedViews   []schema.View   `json:"removed_views,omitempty" yaml:"removed_views,omitempty"`
-	ModifiedViews  []ViewChanges   `json:"modified_views,omitempty" yaml:"modified_views,omitempty"`
+	AddedSchemas      []string          `json:"added_schemas,omitempty" yaml:"added_schemas,omitempty"`
+	RemovedSchemas    []string          `json:"removed_schemas,omitempty" yaml:"removed_schemas,omitempty"`
+	RenamedTables     []TableRename     `json:"renamed_tables,omitempty" yaml:"renamed_tables,omitempty"`
+	AddedTables       []schema.Table    `json:"added_tables,omitempty" yaml:"added_tables,omitempty"`
+	RemovedTables     []schema.Table    `json:"removed_tables,omitempty" yaml:"removed_tables,omitempty"`
+	ModifiedTables    []TableChanges    `json:"modified_tables,omitempty" yaml:"modified_tables,omitempty"`
+	AddedIndexes      []schema.Index    `json:"added_indexes,omitempty" yaml:"added_indexes,omitempty"`
+	RemovedIndexes    []schema.Index    `json:"removed_indexes,omitempty" yaml:"removed_indexes,omitempty"`
+	ModifiedIndexes   []IndexChanges    `json:"modified_indexes,omitempty" yaml:"modified_indexes,omitempty"`
+	AddedViews        []schema.View     `json:"added_views,omitempty" yaml:"added_views,omitempty"`
+	RemovedViews      []schema.View     `json:"removed_views,omitempty" yaml:"removed_views,omitempty"`
+	ModifiedViews     []ViewChanges     `json:"modified_views,omitempty" yaml:"modified_views,omitempty"`
+	AddedEnums        []schema.Enum     `json:"added_enums,omitempty" yaml:"added_enums,omitempty"`
+	RemovedEnums      []schema.Enum     `json:"removed_enums,omitempty" yaml:"removed_enums,omitempty"`
+	ModifiedEnums     []EnumChanges     `json:"modified_enums,omitempty" yaml:"modified_enums,omitempty"`
+	AddedDomains      []schema.Domain   `json:"added_domains,omitempty" yaml:"added_domains,omitempty"`
+	RemovedDomains    []schema.Domain   `json:"removed_domains,omitempty" yaml:"removed_domains,omitempty"`
+	ModifiedDomains   []DomainChanges   `json:"modified_domains,omitempty" yaml:"modified_domains,omitempty"`
+	AddedSequences    []schema.Sequence `json:"added_sequences,omitempty" yaml:"added_sequences,omitempty"`
+	RemovedSequences  []schema.Sequence `json:"removed_sequences,omitempty" yaml:"removed_sequences,omitempty"`
+	ModifiedSequences []SequenceChanges `json:"modified_sequences,omitempty" yaml:"modified_sequences,omitempty"`
+	AddedRoutines     []schema.Routine  `json:"added_routines,omitempty" yaml:"added_routines,omitempty"`
+	RemovedRoutines   []schema.Routine  `json:"removed_routines,omitempty" yaml:"removed_routines,omitempty"`
+	ModifiedRoutines  []RoutineChanges  `json:"modified_routines,omitempty" yaml:"modified_routines,omitempty"`
+	AddedTriggers     []schema.Trigger  `json:"added_triggers,omitempty" yaml:"added_triggers,omitempty"`
+	RemovedTriggers   []schema.Trigger  `json:"removed_triggers,omitempty" yaml:"removed_triggers,omitempty"`
+	ModifiedTriggers  []TriggerChanges  `json:"modified_triggers,omitempty" yaml:"modified_triggers,omitempty"`
 }
 
 // TableChanges represents changes to a specific table.
 type TableChanges struct {
-	Name              string              `json:"name" yaml:"name"`
-	AddedColumns      []schema.Column     `json:"added_columns,omitempty" yaml:"added_columns,omitempty"`
-	RemovedColumns    []schema.Column     `json:"removed_columns,omitempty" yaml:"removed_columns,omitempty"`
-	ModifiedColumns   []ColumnChanges     `json:"modified_columns,omitempty" yaml:"modified_columns,omitempty"`
-	AddedIndexes      []schema.Index      `json:"added_indexes,omitempty" yaml:"added_indexes,omitempty"`
-	RemovedIndexes    []schema.Index      `json:"removed_indexes,omitempty" yaml:"removed_indexes,omitempty"`
-	AddedForeignKeys  []schema.ForeignKey `json:"added_foreign_keys,omitempty" yaml:"added_foreign_keys,omitempty"`
-	RemovedForeignKeys []schema.ForeignKey `json:"removed_foreign_keys,omitempty" yaml:"removed_foreign_keys,omitempty"`
-	AddedConstraints  []schema.Constraint `json:"added_constraints,omitempty" yaml:"added_constraints,omitempty"`
-	RemovedConstraints []schema.Constraint `json:"removed_constraints,omitempty" yaml:"removed_constraints,omitempty"`
-	PrimaryKeyChanged bool                `json:"primary_key_changed,omitempty" yaml:"primary_key_changed,omitempty"`
+	Name                string              `json:"name" yaml:"name"`
+	Schema              string              `json:"schema,omitempty" yaml:"schema,omitempty"`
+	AddedColumns        []schema.Column     `json:"added_columns,omitempty" yaml:"added_columns,omitempty"`
+	RemovedColumns      []schema.Column     `json:"removed_columns,omitempty" yaml:"removed_columns,omitempty"`
+	RenamedColumns      []ColumnRename      `json:"renamed_columns,omitempty" yaml:"renamed_columns,omitempty"`
+	ModifiedColumns     []ColumnChanges     `json:"modified_columns,omitempty" yaml:"modified_columns,omitempty"`
+	AddedIndexes        []schema.Index      `json:"added_indexes,omitempty" yaml:"added_indexes,omitempty"`
+	RemovedIndexes      []schema.Index      `json:"removed_indexes,omitempty" yaml:"removed_indexes,omitempty"`
+	ModifiedIndexes     []IndexChanges      `json:"modified_indexes,omitempty" yaml:"modified_indexes,omitempty"`
+	AddedForeignKeys    []schema.ForeignKey `json:"added_foreign_keys,omitempty" yaml:"added_foreign_keys,omitempty"`
+	RemovedForeignKeys  []schema.ForeignKey `json:"removed_foreign_keys,omitempty" yaml:"removed_foreign_keys,omitempty"`
+	ModifiedForeignKeys []ForeignKeyChanges `json:"modified_foreign_keys,omitempty" yaml:"modified_foreign_keys,omitempty"`
+	AddedConstraints    []schema.Constraint `json:"added_constraints,omitempty" yaml:"added_constraints,omitempty"`
+	RemovedConstraints  []schema.Constraint `json:"removed_constraints,omitempty" yaml:"removed_constraints,omitempty"`
+	PrimaryKeyChanged   bool                `json:"primary_key_changed,omitempty" yaml:"primary_key_changed,omitempty"`
+	OldPrimaryKey       *schema.PrimaryKey  `json:"old_primary_key,omitempty" yaml:"old_primary_key,omitempty"`
+	NewPrimaryKey       *schema.PrimaryKey  `json:"new_primary_key,omitempty" yaml:"new_primary_key,omitempty"`
+
+	// DependentForeignKeys are the foreign keys, in this table or others,
+	// that refer to the primary key, which must be dropped before it
+	// changes and added after. Some are also listed with their own table's
+	// changes.
+	DependentForeignKeys []DependentForeignKey `json:"dependent_foreign_keys,omitempty" yaml:"dependent_foreign_keys,omitempty"`
+	CommentChanged       bool                  `json:"comment_changed,omitempty" yaml:"comment_changed,omitempty"`
+	OldComment           string                `json:"old_comment,omitempty" yaml:"old_comment,omitempty"`
+	NewComment           string                `json:"new_comment,omitempty" yaml:"new_comment,omitempty"`
+
+	// Definition is the complete target table, and StandaloneIndexes and
+	// Triggers the target's indexes and triggers on it, for dialects that
+	// rebuild a table to change it
+	Definition *schema.Table `json:"-" yaml:"-"`
+	// Source is the table before the change, from which the names the
+	// database gave its unnamed constraints are derived
+	Source            *schema.Table    `json:"-" yaml:"-"`
+	StandaloneIndexes []schema.Index   `json:"-" yaml:"-"`
+	Triggers          []schema.Trigger `json:"-" yaml:"-"`
+}
+
+// DependentForeignKey is a foreign key that refers to a primary key that
+// changes. Old, when set, is dropped before the key changes and New, when
+// set, added after it.
+type DependentForeignKey struct {
+	Schema string             `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Table  string             `json:"table" yaml:"table"`
+	Old    *schema.ForeignKey `json:"old,omitempty" yaml:"old,omitempty"`
+	New    *schema.ForeignKey `json:"new,omitempty" yaml:"new,omitempty"`
+
+	// Source is the table before the change, which names an unnamed Old
+	Source *schema.Table `json:"-" yaml:"-"`
+}
+
+// TableRename records a table the target has under another name. The
+// table's other changes are listed under its new name.
+type TableRename struct {
+	Schema  string `json:"schema,omitempty" yaml:"schema,omitempty"`
+	OldName string `json:"old_name" yaml:"old_name"`
+	NewName string `json:"new_name" yaml:"new_name"`
+}
+
+// ColumnRename records a column the target has under another name. The
+// column's other changes are listed under its new name.
+type ColumnRename struct {
+	OldName string `json:"old_name" yaml:"old_name"`
+	NewName string `json:"new_name" yaml:"new_name"`
 }
 
 // ColumnChanges represents changes to a specific column.
 type ColumnChanges struct {
-	Name            string  `json:"name" yaml:"name"`
-	OldType         string  `json:"old_type,omitempty" yaml:"old_type,omitempty"`
-	NewType         string  `json:"new_type,omitempty" yaml:"new_type,omitempty"`
-	NullableChanged bool    `json:"nullable_changed,omitempty" yaml:"nullable_changed,omitempty"`
-	OldNullable     bool    `json:"old_nullable,omitempty" yaml:"old_nullable,omitempty"`
-	NewNullable     bool    `json:"new_nullable,omitempty" yaml:"new_nullable,omitempty"`
-	DefaultChanged  bool    `json:"default_changed,omitempty" yaml:"default_changed,omitempty"`
-	OldDefault      *string `json:"old_default,omitempty" yaml:"old_default,omitempty"`
-	NewDefault      *string `json:"new_default,omitempty" yaml:"new_default,omitempty"`
+	Name            string           `json:"name" yaml:"name"`
+	OldType         string           `json:"old_type,omitempty" yaml:"old_type,omitempty"`
+	NewType         string           `json:"new_type,omitempty" yaml:"new_type,omitempty"`
+	NullableChanged bool             `json:"nullable_changed,omitempty" yaml:"nullable_changed,omitempty"`
+	OldNullable     bool             `json:"old_nullable,omitempty" yaml:"old_nullable,omitempty"`
+	NewNullable     bool             `json:"new_nullable,omitempty" yaml:"new_nullable,omitempty"`
+	DefaultChanged  bool             `json:"default_changed,omitempty" yaml:"default_changed,omitempty"`
+	OldDefault      *string          `json:"old_default,omitempty" yaml:"old_default,omitempty"`
+	NewDefault      *string          `json:"new_default,omitempty" yaml:"new_default,omitempty"`
+	OldDefaultName  string           `json:"old_default_name,omitempty" yaml:"old_default_name,omitempty"`
+	NewDefaultName  string           `json:"new_default_name,omitempty" yaml:"new_default_name,omitempty"`
+	OnUpdateChanged bool             `json:"on_update_changed,omitempty" yaml:"on_update_changed,omitempty"`
+	OldOnUpdate     string           `json:"old_on_update,omitempty" yaml:"old_on_update,omitempty"`
+	NewOnUpdate     string           `json:"new_on_update,omitempty" yaml:"new_on_update,omitempty"`
+	IdentityChanged bool             `json:"identity_changed,omitempty" yaml:"identity_changed,omitempty"`
+	OldIdentity     *schema.Identity `json:"old_identity,omitempty" yaml:"old_identity,omitempty"`
+	NewIdentity     *schema.Identity `json:"new_identity,omitempty" yaml:"new_identity,omitempty"`
+	CommentChanged  bool             `json:"comment_changed,omitempty" yaml:"comment_changed,omitempty"`
+	OldComment      string           `json:"old_comment,omitempty" yaml:"old_comment,omitempty"`
+	NewComment      string           `json:"new_comment,omitempty" yaml:"new_comment,omitempty"`
+
+	// Definition is the complete target column, for dialects that restate
+	// the whole column to change any part of it
+	Definition *schema.Column `json:"-" yaml:"-"`
+}
+
+// IndexChanges represents a changed index definition. The index is
+// dropped and created again.
+type IndexChanges struct {
+	Name string       `json:"name" yaml:"name"`
+	Old  schema.Index `json:"old" yaml:"old"`
+	New  schema.Index `json:"new" yaml:"new"`
+}
+
+// ForeignKeyChanges represents a changed foreign key definition. The
+// foreign key is dropped and added again.
+type ForeignKeyChanges struct {
+	Name string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Old  schema.ForeignKey `json:"old" yaml:"old"`
+	New  schema.ForeignKey `json:"new" yaml:"new"`
 }
 
 // ViewChanges represents changes to a specific view.
 type ViewChanges struct {
 	Name          string `json:"name" yaml:"name"`
+	Schema        string `json:"schema,omitempty" yaml:"schema,omitempty"`
 	OldDefinition string `json:"old_definition,omitempty" yaml:"old_definition,omitempty"`
 	NewDefinition string `json:"new_definition,omitempty" yaml:"new_definition,omitempty"`
 }
 
+// EnumChanges represents added and removed values of an enum type.
+type EnumChanges struct {
+	Name          string   `json:"name" yaml:"name"`
+	Schema        string   `json:"schema,omitempty" yaml:"schema,omitempty"`
+	AddedValues   []string `json:"added_values,omitempty" yaml:"added_values,omitempty"`
+	RemovedValues []string `json:"removed_values,omitempty" yaml:"removed_values,omitempty"`
+	NewValues     []string `json:"new_values" yaml:"new_values"`
+}
+
+// DomainChanges represents a changed domain definition.
+type DomainChanges struct {
+	Name string        `json:"name" yaml:"name"`
+	Old  schema.Domain `json:"old" yaml:"old"`
+	New  schema.Domain `json:"new" yaml:"new"`
+}
+
+// SequenceChanges represents a changed sequence definition.
+type SequenceChanges struct {
+	Name string          `json:"name" yaml:"name"`
+	Old  schema.Sequence `json:"old" yaml:"old"`
+	New  schema.Sequence `json:"new" yaml:"new"`
+}
+
+// RoutineChanges represents a changed function or procedure.
+type RoutineChanges struct {
+	Name string         `json:"name" yaml:"name"`
+	Old  schema.Routine `json:"old" yaml:"old"`
+	New  schema.Routine `json:"new" yaml:"new"`
+}
+
+// TriggerChanges represents a changed trigger.
+type TriggerChanges struct {
+	Name string         `json:"name" yaml:"name"`
+	Old  schema.Trigger `json:"old" yaml:"old"`
+	New  schema.Trigger `json:"new" yaml:"new"`
+}
+
+// Options control how schemas are compared.
+type Options struct {
+	// DefaultSchema is the schema objects without one belong to, such as
+	// public for PostgreSQL, so that qualified and unqualified names of
+	// the same object match.
+	DefaultSchema string
+
+	// SchemaMap renames schemas in the source (old name to new name)
+	// before comparing, for databases that keep the same objects under
+	// different schema names.
+	SchemaMap map[string]string
+
+	// Schemas limits the comparison to objects in these schemas, when not
+	// empty; ExcludeSchemas leaves out objects in these.
+	Schemas        []string
+	ExcludeSchemas []string
+
+	// Filter leaves out the tables, views, indexes and columns it
+	// excludes, so the changes never mention them.
+	Filter *schema.Filter
+
+	// Renames names the source tables and columns the target has under
+	// another name (old name to new name), as table=new_table or
+	// table.column=new_column, with the table optionally qualified by its
+	// schema. Renamed objects are compared with their new counterparts
+	// instead of being dropped and recreated.
+	Renames map[string]string
+
+	// NoRenameDetection turns off guessing renames from removed and added
+	// tables and columns that look alike, leaving only Renames.
+	NoRenameDetection bool
+
+	// MatchByStructure pairs indexes, foreign keys and constraints with
+	// the same definition whatever their names, so names generated
+	// differently in each database are not reported as changes. By
+	// default only unnamed objects are matched by definition.
+	MatchByStructure bool
+}
+
 // Differ compares two schemas.
 type Differ struct {
 	source *schema.Schema
 	target *schema.Schema
+	opts   Options
+
+	// renames are applied to source before comparing
+	renames   []schema.Rename
+	renameErr error
 }
 
 // NewDiffer creates a new schema differ.
 func NewDiffer(source, target *schema.Schema) *Differ {
-	return &Differ{source: source, target: target}
+	return NewDifferWithOptions(source, target, Options{})
+}
+
+// NewDifferWithOptions creates a schema differ with the given options.
+// The schemas themselves are not modified.
+func NewDifferWithOptions(source, target *schema.Schema, opts Options) *Differ {
+	if len(opts.SchemaMap) > 0 {
+		source = source.MapSchemas(opts.SchemaMap, opts.DefaultSchema)
+	}
+	if len(opts.Schemas) > 0 || len(opts.ExcludeSchemas) > 0 {
+		source = source.FilterSchemas(opts.Schemas, opts.ExcludeSchemas, opts.DefaultSchema)
+		target = target.FilterSchemas(opts.Schemas, opts.ExcludeSchemas, opts.DefaultSchema)
+	}
+	if !opts.Filter.IsEmpty() {
+		source = opts.Filter.Apply(source)
+		target = opts.Filter.Apply(target)
+	}
+
+	d := &Differ{source: source, target: target, opts: opts}
+	d.renames, d.renameErr = d.findRenames()
+	if len(d.renames) > 0 {
+		d.source = d.source.ApplyRenames(d.renames)
+	}
+	return d
+}
+
+// Validate reports the first of Options.Renames that does not fit the
+// schemas, such as a rename of a column the source does not have. Those
+// are left out of the comparison.
+func (d *Differ) Validate() error {
+	return d.renameErr
+}
+
+// key identifies an object by its schema and name. Objects without a
+// schema belong to the default schema.
+func (d *Differ) key(schemaName, name string) string {
+	if schemaName == "" {
+		schemaName = d.opts.DefaultSchema
+	}
+	return strings.ToLower(schemaName) + "." + name
 }
 
 // Compare computes the differences between source and target schemas.
 func (d *Differ) Compare() *Changes {
 	changes := &Changes{}
 
-	// Compare tables
+	// Compare schemas
+	d.compareSchemas(changes)
+
+	// Compare tables, under their new names when renamed
+	for _, r := range d.renames {
+		if r.Column == "" {
+			changes.RenamedTables = append(changes.RenamedTables, TableRename{Schema: r.Schema, OldName: r.Table, NewName: r.NewName})
+		}
+	}
 	d.compareTables(changes)
 
 	// Compare standalone indexes
@@ -82,38 +315,68 @@ func (d *Differ) Compare() *Changes {
 	// Compare views
 	d.compareViews(changes)
 
+	// Compare enum and domain types
+	d.compareEnums(changes)
+	d.compareDomains(changes)
+
+	// Compare sequences
+	d.compareSequences(changes)
+
+	// Compare routines and triggers
+	d.compareRoutines(changes)
+	d.compareTriggers(changes)
+
 	return changes
 }
 
+func (d *Differ) compareSchemas(changes *Changes) {
+	for _, name := range d.target.Schemas {
+		if !containsFold(d.source.Schemas, name) {
+			changes.AddedSchemas = append(changes.AddedSchemas, name)
+		}
+	}
+	for _, name := range d.source.Schemas {
+		if !containsFold(d.target.Schemas, name) {
+			changes.RemovedSchemas = append(changes.RemovedSchemas, name)
+		}
+	}
+}
+
 func (d *Differ) compareTables(changes *Changes) {
 	sourceMap := make(map[string]*schema.Table)
 	for i := range d.source.Tables {
 		t := &d.source.Tables[i]
-		sourceMap[t.Name] = t
+		sourceMap[d.key(t.Schema, t.Name)] = t
 	}
 
 	targetMap := make(map[string]*schema.Table)
 	for i := range d.target.Tables {
 		t := &d.target.Tables[i]
-		targetMap[t.Name] = t
+		targetMap[d.key(t.Schema, t.Name)] = t
 	}
 
 	// Find added tables
-	for name, table := range targetMap {
+	for i := range d.target.Tables {
+		table := &d.target.Tables[i]
+		name := d.key(table.Schema, table.Name)
 		if _, exists := sourceMap[name]; !exists {
 			changes.AddedTables = append(changes.AddedTables, *table)
 		}
 	}
 
 	// Find removed tables
-	for name, table := range sourceMap {
+	for i := range d.source.Tables {
+		table := &d.source.Tables[i]
+		name := d.key(table.Schema, table.Name)
 		if _, exists := targetMap[name]; !exists {
 			changes.RemovedTables = append(changes.RemovedTables, *table)
 		}
 	}
 
 	// Find modified tables
-	for name, sourceTable := range sourceMap {
+	for i := range d.source.Tables {
+		sourceTable := &d.source.Tables[i]
+		name := d.key(sourceTable.Schema, sourceTable.Name)
 		if targetTable, exists := targetMap[name]; exists {
 			tableChanges := d.compareTable(sourceTable, targetTable)
 			if tableChanges != nil {
@@ -124,9 +387,18 @@ func (d *Differ) compareTables(changes *Changes) {
 }
 
 func (d *Differ) compareTable(source, target *schema.Table) *TableChanges {
-	changes := &TableChanges{Name: source.Name}
+	changes := &TableChanges{Name: target.Name, Schema: target.Schema}
 	hasChanges := false
 
+	// Renamed columns, compared below under their new names
+	tableKey := d.key(target.Schema, target.Name)
+	for _, r := range d.renames {
+		if r.Column != "" && d.key(r.Schema, r.Table) == tableKey {
+			changes.RenamedColumns = append(changes.RenamedColumns, ColumnRename{OldName: r.Column, NewName: r.NewName})
+			hasChanges = true
+		}
+	}
+
 	// Compare columns
 	sourceColMap := make(map[string]*schema.Column)
 	for i := range source.Columns {
@@ -141,7 +413,9 @@ func (d *Differ) compareTable(source, target *schema.Table) *TableChanges {
 	}
 
 	// Added columns
-	for name, col := range targetColMap {
+	for i := range target.Columns {
+		col := &target.Columns[i]
+		name := col.Name
 		if _, exists := sourceColMap[name]; !exists {
 			changes.AddedColumns = append(changes.AddedColumns, *col)
 			hasChanges = true
@@ -149,16 +423,27 @@ func (d *Differ) compareTable(source, target *schema.Table) *TableChanges {
 	}
 
 	// Removed columns
-	for name, col := range sourceColMap {
+	for i := range source.Columns {
+		col := &source.Columns[i]
+		name := col.Name
 		if _, exists := targetColMap[name]; !exists {
 			changes.RemovedColumns = append(changes.RemovedColumns, *col)
 			hasChanges = true
 		}
 	}
 
-	// Modified columns
-	for name, sourceCol := range sourceColMap {
+	// Modified columns; a changed generation expression or storage can
+	// only be applied by dropping and re-adding the column
+	for i := range source.Columns {
+		sourceCol := &source.Columns[i]
+		name := sourceCol.Name
 		if targetCol, exists := targetColMap[name]; exists {
+			if !sameGenerated(sourceCol.Generated, targetCol.Generated) {
+				changes.RemovedColumns = append(changes.RemovedColumns, *sourceCol)
+				changes.AddedColumns = append(changes.AddedColumns, *targetCol)
+				hasChanges = true
+				continue
+			}
 			colChanges := d.compareColumn(sourceCol, targetCol)
 			if colChanges != nil {
 				changes.ModifiedColumns = append(changes.ModifiedColumns, *colChanges)
@@ -168,70 +453,74 @@ func (d *Differ) compareTable(source, target *schema.Table) *TableChanges {
 	}
 
 	// Compare indexes within table
-	sourceIdxMap := make(map[string]*schema.Index)
-	for i := range source.Indexes {
-		idx := &source.Indexes[i]
-		sourceIdxMap[idx.Name] = idx
-	}
-
-	targetIdxMap := make(map[string]*schema.Index)
-	for i := range target.Indexes {
-		idx := &target.Indexes[i]
-		targetIdxMap[idx.Name] = idx
-	}
-
-	for name, idx := range targetIdxMap {
-		if _, exists := sourceIdxMap[name]; !exists {
-			changes.AddedIndexes = append(changes.AddedIndexes, *idx)
+	sourceIdx, targetIdx := d.matchObjects(indexNames(source.Indexes), indexNames(target.Indexes), func(i, j int) bool {
+		return sameIndex(&source.Indexes[i], &target.Indexes[j])
+	})
+	for j := range target.Indexes {
+		if sourceIdx[j] < 0 {
+			changes.AddedIndexes = append(changes.AddedIndexes, target.Indexes[j])
 			hasChanges = true
 		}
 	}
-
-	for name, idx := range sourceIdxMap {
-		if _, exists := targetIdxMap[name]; !exists {
-			changes.RemovedIndexes = append(changes.RemovedIndexes, *idx)
+	for i := range source.Indexes {
+		j := targetIdx[i]
+		switch {
+		case j < 0:
+			changes.RemovedIndexes = append(changes.RemovedIndexes, source.Indexes[i])
+			hasChanges = true
+		case !sameIndex(&source.Indexes[i], &target.Indexes[j]):
+			changes.ModifiedIndexes = append(changes.ModifiedIndexes, IndexChanges{
+				Name: target.Indexes[j].Name,
+				Old:  source.Indexes[i],
+				New:  target.Indexes[j],
+			})
 			hasChanges = true
 		}
 	}
 
 	// Compare foreign keys
-	sourceFKMap := make(map[string]*schema.ForeignKey)
-	for i := range source.ForeignKeys {
-		fk := &source.ForeignKeys[i]
-		key := fk.Name
-		if key == "" {
-			key = strings.Join(fk.Columns, "_") + "_fk"
-		}
-		sourceFKMap[key] = fk
-	}
-
-	targetFKMap := make(map[string]*schema.ForeignKey)
-	for i := range target.ForeignKeys {
-		fk := &target.ForeignKeys[i]
-		key := fk.Name
-		if key == "" {
-			key = strings.Join(fk.Columns, "_") + "_fk"
+	sourceFK, targetFK := d.matchForeignKeys(source.ForeignKeys, target.ForeignKeys)
+	for j := range target.ForeignKeys {
+		if sourceFK[j] < 0 {
+			changes.AddedForeignKeys = append(changes.AddedForeignKeys, target.ForeignKeys[j])
+			hasChanges = true
 		}
-		targetFKMap[key] = fk
 	}
-
-	for key, fk := range targetFKMap {
-		if _, exists := sourceFKMap[key]; !exists {
-			changes.AddedForeignKeys = append(changes.AddedForeignKeys, *fk)
+	for i := range source.ForeignKeys {
+		j := targetFK[i]
+		switch {
+		case j < 0:
+			changes.RemovedForeignKeys = append(changes.RemovedForeignKeys, source.ForeignKeys[i])
+			hasChanges = true
+		case !d.sameForeignKey(&source.ForeignKeys[i], &target.ForeignKeys[j]):
+			changes.ModifiedForeignKeys = append(changes.ModifiedForeignKeys, ForeignKeyChanges{
+				Name: target.ForeignKeys[j].Name,
+				Old:  source.ForeignKeys[i],
+				New:  target.ForeignKeys[j],
+			})
 			hasChanges = true
 		}
 	}
 
-	for key, fk := range sourceFKMap {
-		if _, exists := targetFKMap[key]; !exists {
-			changes.RemovedForeignKeys = append(changes.RemovedForeignKeys, *fk)
-			hasChanges = true
-		}
+	// Compare CHECK and UNIQUE constraints
+	if d.compareConstraints(source, target, changes) {
+		hasChanges = true
 	}
 
 	// Compare primary keys
 	if !d.samePrimaryKey(source.PrimaryKey, target.PrimaryKey) {
 		changes.PrimaryKeyChanged = true
+		changes.OldPrimaryKey = source.PrimaryKey
+		changes.NewPrimaryKey = target.PrimaryKey
+		changes.DependentForeignKeys = d.dependentForeignKeys(source, target)
+		hasChanges = true
+	}
+
+	// Compare comments
+	if source.Comment != target.Comment {
+		changes.CommentChanged = true
+		changes.OldComment = source.Comment
+		changes.NewComment = target.Comment
 		hasChanges = true
 	}
 
@@ -239,6 +528,19 @@ func (d *Differ) compareTable(source, target *schema.Table) *TableChanges {
 		return nil
 	}
 
+	changes.Definition = target
+	changes.Source = source
+	for _, idx := range d.target.Indexes {
+		if d.key(idx.Schema, idx.Table) == tableKey {
+			changes.StandaloneIndexes = append(changes.StandaloneIndexes, idx)
+		}
+	}
+	for _, t := range d.target.Triggers {
+		if d.key(t.Schema, t.Table) == tableKey {
+			changes.Triggers = append(changes.Triggers, t)
+		}
+	}
+
 	return changes
 }
 
@@ -247,7 +549,7 @@ func (d *Differ) compareColumn(source, target *schema.Column) *ColumnChanges {
 	hasChanges := false
 
 	// Type change
-	if !strings.EqualFold(source.Type, target.Type) {
+	if !sameType(source.Type, target.Type) {
 		changes.OldType = source.Type
 		changes.NewType = target.Type
 		hasChanges = true
@@ -266,6 +568,32 @@ func (d *Differ) compareColumn(source, target *schema.Column) *ColumnChanges {
 		changes.DefaultChanged = true
 		changes.OldDefault = source.Default
 		changes.NewDefault = target.Default
+		changes.OldDefaultName = source.DefaultName
+		changes.NewDefaultName = target.DefaultName
+		hasChanges = true
+	}
+
+	// MySQL ON UPDATE change
+	if normalizeExpr(source.OnUpdate) != normalizeExpr(target.OnUpdate) {
+		changes.OnUpdateChanged = true
+		changes.OldOnUpdate = source.OnUpdate
+		changes.NewOnUpdate = target.OnUpdate
+		hasChanges = true
+	}
+
+	// Identity generation or options change
+	if !sameIdentity(source.Identity, target.Identity) {
+		changes.IdentityChanged = true
+		changes.OldIdentity = source.Identity
+		changes.NewIdentity = target.Identity
+		hasChanges = true
+	}
+
+	// Comment change
+	if source.Comment != target.Comment {
+		changes.CommentChanged = true
+		changes.OldComment = source.Comment
+		changes.NewComment = target.Comment
 		hasChanges = true
 	}
 
@@ -273,9 +601,289 @@ func (d *Differ) compareColumn(source, target *schema.Column) *ColumnChanges {
 		return nil
 	}
 
+	changes.Definition = target
+
 	return changes
 }
 
+func sameGenerated(a, b *schema.Generated) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Stored == b.Stored && normalizeExpr(a.Expression) == normalizeExpr(b.Expression)
+}
+
+// compareConstraints adds the CHECK and UNIQUE constraints of target that
+// source lacks, and the reverse, to changes, reporting whether there were
+// any. Constraints are paired as matchObjects does; a changed constraint
+// is dropped and added again. Unnamed constraints only on removed columns
+// go with them.
+func (d *Differ) compareConstraints(source, target *schema.Table, changes *TableChanges) bool {
+	sourceConstraints, targetConstraints := tableConstraints(source), tableConstraints(target)
+	names := func(constraints []schema.Constraint) []string {
+		names := make([]string, len(constraints))
+		for i := range constraints {
+			names[i] = constraints[i].Name
+		}
+		return names
+	}
+	sourceOf, targetOf := d.matchObjects(names(sourceConstraints), names(targetConstraints), func(i, j int) bool {
+		return sameConstraint(&sourceConstraints[i], &targetConstraints[j])
+	})
+
+	var removed, added []schema.Constraint
+	for i := range sourceConstraints {
+		c := &sourceConstraints[i]
+		j := targetOf[i]
+		switch {
+		case j >= 0 && !sameConstraint(c, &targetConstraints[j]):
+			removed = append(removed, *c)
+			added = append(added, targetConstraints[j])
+		case j < 0 && (c.Name != "" || !onlyRemovedColumns(c.Columns, changes.RemovedColumns)):
+			removed = append(removed, *c)
+		}
+	}
+	for j := range targetConstraints {
+		if sourceOf[j] < 0 {
+			added = append(added, targetConstraints[j])
+		}
+	}
+
+	changes.RemovedConstraints = append(changes.RemovedConstraints, removed...)
+	changes.AddedConstraints = append(changes.AddedConstraints, added...)
+	return len(removed) > 0 || len(added) > 0
+}
+
+// tableConstraints returns the CHECK and UNIQUE constraints of a table,
+// counting a column declared UNIQUE as an unnamed UNIQUE constraint on it
+// unless a constraint already covers it.
+func tableConstraints(t *schema.Table) []schema.Constraint {
+	constraints := append([]schema.Constraint(nil), t.Constraints...)
+	for _, col := range t.Columns {
+		if !col.IsUnique || col.IsPrimaryKey {
+			continue
+		}
+		unique := schema.Constraint{Type: "UNIQUE", Columns: []string{col.Name}}
+		covered := false
+		for i := range t.Constraints {
+			if sameConstraint(&t.Constraints[i], &unique) {
+				covered = true
+			}
+		}
+		if !covered {
+			constraints = append(constraints, unique)
+		}
+	}
+	return constraints
+}
+
+// sameConstraint compares the definitions of two constraints: the columns
+// of a UNIQUE constraint and the expression of a CHECK, whether it was
+// declared on a column or the table.
+func sameConstraint(a, b *schema.Constraint) bool {
+	if !strings.EqualFold(a.Type, b.Type) {
+		return false
+	}
+	if strings.EqualFold(a.Type, "CHECK") {
+		return normalizeExpr(a.Expression) == normalizeExpr(b.Expression)
+	}
+	return sameNames(a.Columns, b.Columns)
+}
+
+// onlyRemovedColumns reports whether names is not empty and lists only
+// removed columns.
+func onlyRemovedColumns(names []string, removed []schema.Column) bool {
+	if len(names) == 0 {
+		return false
+	}
+	for _, name := range names {
+		found := false
+		for _, col := range removed {
+			if strings.EqualFold(col.Name, name) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// matchObjects pairs source and target objects such as indexes, given
+// their names and whether source i and target j have the same definition:
+// first by name, then by definition where either is unnamed or, with
+// Options.MatchByStructure, whatever their names. Each further test in
+// same pairs the objects left over by the tests before it. It returns the
+// source object paired with each target object and the reverse, or -1.
+func (d *Differ) matchObjects(sourceNames, targetNames []string, same ...func(i, j int) bool) (sourceOf, targetOf []int) {
+	sourceOf = make([]int, len(targetNames))
+	targetOf = make([]int, len(sourceNames))
+	for j := range sourceOf {
+		sourceOf[j] = -1
+	}
+	for i := range targetOf {
+		targetOf[i] = -1
+	}
+	pair := func(i, j int) {
+		sourceOf[j], targetOf[i] = i, j
+	}
+
+	for j, targetName := range targetNames {
+		if targetName == "" {
+			continue
+		}
+		for i, sourceName := range sourceNames {
+			if targetOf[i] < 0 && strings.EqualFold(sourceName, targetName) {
+				pair(i, j)
+				break
+			}
+		}
+	}
+	for _, same := range same {
+		for j, targetName := range targetNames {
+			if sourceOf[j] >= 0 {
+				continue
+			}
+			for i, sourceName := range sourceNames {
+				if targetOf[i] < 0 && (sourceName == "" || targetName == "" || d.opts.MatchByStructure) && same(i, j) {
+					pair(i, j)
+					break
+				}
+			}
+		}
+	}
+	return sourceOf, targetOf
+}
+
+// matchForeignKeys pairs the foreign keys of a table with its new version
+// by matchObjects: by definition, and then an unnamed key with one on the
+// same columns and referenced table, which it changes.
+func (d *Differ) matchForeignKeys(source, target []schema.ForeignKey) (sourceOf, targetOf []int) {
+	names := func(fks []schema.ForeignKey) []string {
+		names := make([]string, len(fks))
+		for i := range fks {
+			names[i] = fks[i].Name
+		}
+		return names
+	}
+	return d.matchObjects(names(source), names(target),
+		func(i, j int) bool { return d.sameForeignKey(&source[i], &target[j]) },
+		func(i, j int) bool { return d.sameForeignKeyColumns(&source[i], &target[j]) })
+}
+
+func indexNames(indexes []schema.Index) []string {
+	names := make([]string, len(indexes))
+	for i := range indexes {
+		names[i] = indexes[i].Name
+	}
+	return names
+}
+
+// sameForeignKey compares the definitions of two foreign keys: columns,
+// referenced table and columns, and referential actions. RESTRICT and NO
+// ACTION count as the same, as they are in MySQL, and so do an unset
+// referenced schema and the default one.
+func (d *Differ) sameForeignKey(a, b *schema.ForeignKey) bool {
+	if !d.sameForeignKeyColumns(a, b) {
+		return false
+	}
+	// A key whose referenced columns could not be resolved matches any
+	if len(a.ReferencedCols) > 0 && len(b.ReferencedCols) > 0 && !sameNames(a.ReferencedCols, b.ReferencedCols) {
+		return false
+	}
+	return referentialAction(a.OnDelete) == referentialAction(b.OnDelete) &&
+		referentialAction(a.OnUpdate) == referentialAction(b.OnUpdate) &&
+		matchType(a.Match) == matchType(b.Match) &&
+		a.Deferrable == b.Deferrable && a.InitiallyDeferred == b.InitiallyDeferred
+}
+
+// sameForeignKeyColumns reports whether two foreign keys have the same
+// columns and referenced table.
+func (d *Differ) sameForeignKeyColumns(a, b *schema.ForeignKey) bool {
+	if !sameNames(a.Columns, b.Columns) || !strings.EqualFold(a.ReferencedTable, b.ReferencedTable) {
+		return false
+	}
+	schemaA, schemaB := a.ReferencedSchema, b.ReferencedSchema
+	if schemaA == "" {
+		schemaA = d.opts.DefaultSchema
+	}
+	if schemaB == "" {
+		schemaB = d.opts.DefaultSchema
+	}
+	return schemaA == "" || schemaB == "" || strings.EqualFold(schemaA, schemaB)
+}
+
+// referentialAction normalizes an ON DELETE or ON UPDATE action, the
+// default being NO ACTION.
+func referentialAction(action string) string {
+	action = strings.ToUpper(strings.Join(strings.Fields(action), " "))
+	if action == "NO ACTION" || action == "RESTRICT" {
+		return ""
+	}
+	return action
+}
+
+// matchType normalizes a MATCH clause, the default being SIMPLE.
+func matchType(match string) string {
+	match = strings.ToUpper(match)
+	if match == "SIMPLE" {
+		return ""
+	}
+	return match
+}
+
+// dependentForeignKeys lists the foreign keys that refer to the primary
+// key of source or of target, its new version, pairing the keys of each
+// table as compareTable does. Keys that refer to neither stay as they are.
+func (d *Differ) dependentForeignKeys(source, target *schema.Table) []DependentForeignKey {
+	tableKey := d.key(target.Schema, target.Name)
+	refersTo := func(fk *schema.ForeignKey, pk *schema.PrimaryKey) bool {
+		if d.key(fk.ReferencedSchema, fk.ReferencedTable) != tableKey {
+			return false
+		}
+		// A key without referenced columns follows whichever key the table has
+		return len(fk.ReferencedCols) == 0 || (pk != nil && sameNames(fk.ReferencedCols, pk.Columns))
+	}
+
+	var deps []DependentForeignKey
+	for i := range d.source.Tables {
+		oldTable := &d.source.Tables[i]
+		newTable := d.findTable(d.target, d.key(oldTable.Schema, oldTable.Name))
+		if newTable == nil {
+			// Removed tables lose their keys before they are dropped
+			for j := range oldTable.ForeignKeys {
+				if fk := &oldTable.ForeignKeys[j]; refersTo(fk, source.PrimaryKey) {
+					deps = append(deps, DependentForeignKey{Schema: oldTable.Schema, Table: oldTable.Name, Source: oldTable, Old: fk})
+				}
+			}
+			continue
+		}
+
+		sourceOf, targetOf := d.matchForeignKeys(oldTable.ForeignKeys, newTable.ForeignKeys)
+		for i := range oldTable.ForeignKeys {
+			oldFK := &oldTable.ForeignKeys[i]
+			var newFK *schema.ForeignKey
+			if j := targetOf[i]; j >= 0 {
+				newFK = &newTable.ForeignKeys[j]
+			}
+			switch {
+			case refersTo(oldFK, source.PrimaryKey):
+			case newFK != nil && refersTo(newFK, target.PrimaryKey) && !d.sameForeignKey(oldFK, newFK):
+			default:
+				continue
+			}
+			deps = append(deps, DependentForeignKey{Schema: newTable.Schema, Table: newTable.Name, Source: oldTable, Old: oldFK, New: newFK})
+		}
+		for j := range newTable.ForeignKeys {
+			if fk := &newTable.ForeignKeys[j]; sourceOf[j] < 0 && refersTo(fk, target.PrimaryKey) {
+				deps = append(deps, DependentForeignKey{Schema: newTable.Schema, Table: newTable.Name, New: fk})
+			}
+		}
+	}
+	return deps
+}
+
 func (d *Differ) samePrimaryKey(source, target *schema.PrimaryKey) bool {
 	if source == nil && target == nil {
 		return true
@@ -295,71 +903,241 @@ func (d *Differ) samePrimaryKey(source, target *schema.PrimaryKey) bool {
 }
 
 func sameDefault(a, b *string) bool {
+	// DEFAULT NULL, as mysqldump writes it, is the same as no default
+	if a != nil && strings.EqualFold(*a, "NULL") {
+		a = nil
+	}
+	if b != nil && strings.EqualFold(*b, "NULL") {
+		b = nil
+	}
 	if a == nil && b == nil {
 		return true
 	}
 	if a == nil || b == nil {
 		return false
 	}
-	return strings.EqualFold(*a, *b)
+	return normalizeExpr(*a) == normalizeExpr(*b)
+}
+
+// sameType reports whether two spellings name the same column type, such
+// as VARCHAR(255) and character varying(255), or TIMESTAMPTZ and timestamp
+// with time zone as PostgreSQL's format_type spells it.
+func sameType(a, b string) bool {
+	return canonicalType(a) == canonicalType(b)
+}
+
+// typeAliases maps type names to the name canonicalType spells them with.
+var typeAliases = map[string]string{
+	"int":         "integer",
+	"int4":        "integer",
+	"int2":        "smallint",
+	"int8":        "bigint",
+	"varchar":     "character varying",
+	"char":        "character",
+	"bpchar":      "character",
+	"bool":        "boolean",
+	"float8":      "double precision",
+	"float4":      "real",
+	"decimal":     "numeric",
+	"timestamptz": "timestamp with time zone",
+	"timetz":      "time with time zone",
+	"varbit":      "bit varying",
+}
+
+// canonicalType lower-cases a type and spells its name, arguments, time
+// zone and array bounds the way format_type does.
+func canonicalType(t string) string {
+	t = strings.ToLower(strings.Join(strings.Fields(t), " "))
+	t = strings.ReplaceAll(t, `"`, "")
+	t = strings.ReplaceAll(t, ", ", ",")
+
+	arrays := ""
+	for strings.HasSuffix(t, "[]") {
+		arrays += "[]"
+		t = strings.TrimSpace(strings.TrimSuffix(t, "[]"))
+	}
+
+	zone := ""
+	for _, suffix := range []string{" with time zone", " without time zone"} {
+		if strings.HasSuffix(t, suffix) {
+			zone, t = suffix, strings.TrimSuffix(t, suffix)
+		}
+	}
+
+	name, args := t, ""
+	if i := strings.Index(t, "("); i >= 0 {
+		name, args = strings.TrimSpace(t[:i]), t[i:]
+	}
+	if alias, ok := typeAliases[name]; ok {
+		name = alias
+	}
+	if strings.HasSuffix(name, " with time zone") {
+		zone, name = " with time zone", strings.TrimSuffix(name, " with time zone")
+	}
+	if zone == " without time zone" {
+		zone = ""
+	}
+	if name == "character" && args == "" {
+		// CHAR is CHAR(1)
+		args = "(1)"
+	}
+	return name + args + zone + arrays
 }
 
 func (d *Differ) compareStandaloneIndexes(changes *Changes) {
-	sourceMap := make(map[string]*schema.Index)
+	names := func(indexes []schema.Index) []string {
+		names := make([]string, len(indexes))
+		for i := range indexes {
+			names[i] = d.key(indexes[i].Schema, indexes[i].Name)
+		}
+		return names
+	}
+	sameTable := func(a, b *schema.Index) bool {
+		return strings.EqualFold(d.key(a.Schema, a.Table), d.key(b.Schema, b.Table))
+	}
+	sourceOf, targetOf := d.matchObjects(names(d.source.Indexes), names(d.target.Indexes), func(i, j int) bool {
+		a, b := &d.source.Indexes[i], &d.target.Indexes[j]
+		return sameTable(a, b) && sameIndex(a, b)
+	})
+
+	for j := range d.target.Indexes {
+		if sourceOf[j] < 0 {
+			changes.AddedIndexes = append(changes.AddedIndexes, d.target.Indexes[j])
+		}
+	}
+
 	for i := range d.source.Indexes {
-		idx := &d.source.Indexes[i]
-		sourceMap[idx.Name] = idx
+		sourceIdx := &d.source.Indexes[i]
+		j := targetOf[i]
+		if j < 0 {
+			changes.RemovedIndexes = append(changes.RemovedIndexes, *sourceIdx)
+			continue
+		}
+		if targetIdx := &d.target.Indexes[j]; !sameTable(sourceIdx, targetIdx) || !sameIndex(sourceIdx, targetIdx) {
+			changes.ModifiedIndexes = append(changes.ModifiedIndexes, IndexChanges{
+				Name: targetIdx.Name,
+				Old:  *sourceIdx,
+				New:  *targetIdx,
+			})
+		}
 	}
+}
 
-	targetMap := make(map[string]*schema.Index)
-	for i := range d.target.Indexes {
-		idx := &d.target.Indexes[i]
-		targetMap[idx.Name] = idx
+// sameIndex reports whether two indexes have the same definition: keys,
+// sort order, operator classes, included columns and predicate.
+func sameIndex(a, b *schema.Index) bool {
+	if a.IsUnique != b.IsUnique || !sameIndexType(a.Type, b.Type) {
+		return false
+	}
+	if !sameNames(a.Include, b.Include) || normalizeExpr(a.Where) != normalizeExpr(b.Where) {
+		return false
 	}
 
-	for name, idx := range targetMap {
-		if _, exists := sourceMap[name]; !exists {
-			changes.AddedIndexes = append(changes.AddedIndexes, *idx)
+	aKeys, bKeys := a.KeyColumns(), b.KeyColumns()
+	if len(aKeys) != len(bKeys) {
+		return false
+	}
+	for i := range aKeys {
+		ak, bk := aKeys[i], bKeys[i]
+		if !strings.EqualFold(ak.Name, bk.Name) ||
+			normalizeExpr(ak.Expression) != normalizeExpr(bk.Expression) ||
+			ak.Descending != bk.Descending ||
+			!strings.EqualFold(ak.Nulls, bk.Nulls) ||
+			!strings.EqualFold(ak.OpClass, bk.OpClass) ||
+			!strings.EqualFold(strings.Trim(ak.Collation, `"`), strings.Trim(bk.Collation, `"`)) ||
+			ak.Length != bk.Length {
+			return false
 		}
 	}
+	return true
+}
 
-	for name, idx := range sourceMap {
-		if _, exists := targetMap[name]; !exists {
-			changes.RemovedIndexes = append(changes.RemovedIndexes, *idx)
+func sameIndexType(a, b string) bool {
+	if a == "" {
+		a = "btree"
+	}
+	if b == "" {
+		b = "btree"
+	}
+	return strings.EqualFold(a, b)
+}
+
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
 		}
 	}
+	return true
+}
+
+// normalizeExpr normalizes an expression for comparison, dropping
+// parentheses that wrap all of it.
+func normalizeExpr(expr string) string {
+	expr = normalizeSQL(expr)
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") && wrapsAll(expr) {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	return expr
+}
+
+// wrapsAll reports whether the opening parenthesis of expr closes at its end.
+func wrapsAll(expr string) bool {
+	depth := 0
+	for i, ch := range expr {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i == len(expr)-1
+			}
+		}
+	}
+	return false
 }
 
 func (d *Differ) compareViews(changes *Changes) {
 	sourceMap := make(map[string]*schema.View)
 	for i := range d.source.Views {
 		v := &d.source.Views[i]
-		sourceMap[v.Name] = v
+		sourceMap[d.key(v.Schema, v.Name)] = v
 	}
 
 	targetMap := make(map[string]*schema.View)
 	for i := range d.target.Views {
 		v := &d.target.Views[i]
-		targetMap[v.Name] = v
+		targetMap[d.key(v.Schema, v.Name)] = v
 	}
 
-	for name, view := range targetMap {
+	for i := range d.target.Views {
+		view := &d.target.Views[i]
+		name := d.key(view.Schema, view.Name)
 		if _, exists := sourceMap[name]; !exists {
 			changes.AddedViews = append(changes.AddedViews, *view)
 		}
 	}
 
-	for name, view := range sourceMap {
+	for i := range d.source.Views {
+		view := &d.source.Views[i]
+		name := d.key(view.Schema, view.Name)
 		if _, exists := targetMap[name]; !exists {
 			changes.RemovedViews = append(changes.RemovedViews, *view)
 		}
 	}
 
-	for name, sourceView := range sourceMap {
+	for i := range d.source.Views {
+		sourceView := &d.source.Views[i]
+		name := d.key(sourceView.Schema, sourceView.Name)
 		if targetView, exists := targetMap[name]; exists {
 			if normalizeSQL(sourceView.Definition) != normalizeSQL(targetView.Definition) {
 				changes.ModifiedViews = append(changes.ModifiedViews, ViewChanges{
-					Name:          name,
+					Name:          targetView.Name,
+					Schema:        targetView.Schema,
 					OldDefinition: sourceView.Definition,
 					NewDefinition: targetView.Definition,
 				})
@@ -368,6 +1146,404 @@ func (d *Differ) compareViews(changes *Changes) {
 	}
 }
 
+func (d *Differ) compareEnums(changes *Changes) {
+	sourceMap := make(map[string]*schema.Enum)
+	for i := range d.source.Enums {
+		e := &d.source.Enums[i]
+		sourceMap[d.key(e.Schema, e.Name)] = e
+	}
+
+	targetMap := make(map[string]*schema.Enum)
+	for i := range d.target.Enums {
+		e := &d.target.Enums[i]
+		targetMap[d.key(e.Schema, e.Name)] = e
+	}
+
+	for i := range d.target.Enums {
+		e := &d.target.Enums[i]
+		name := d.key(e.Schema, e.Name)
+		if _, exists := sourceMap[name]; !exists {
+			changes.AddedEnums = append(changes.AddedEnums, *e)
+		}
+	}
+
+	for i := range d.source.Enums {
+		e := &d.source.Enums[i]
+		name := d.key(e.Schema, e.Name)
+		if _, exists := targetMap[name]; !exists {
+			changes.RemovedEnums = append(changes.RemovedEnums, *e)
+		}
+	}
+
+	for i := range d.source.Enums {
+		sourceEnum := &d.source.Enums[i]
+		name := d.key(sourceEnum.Schema, sourceEnum.Name)
+		targetEnum, exists := targetMap[name]
+		if !exists {
+			continue
+		}
+		ec := EnumChanges{Name: targetEnum.Name, Schema: targetEnum.Schema, NewValues: targetEnum.Values}
+		for _, v := range targetEnum.Values {
+			if !containsString(sourceEnum.Values, v) {
+				ec.AddedValues = append(ec.AddedValues, v)
+			}
+		}
+		for _, v := range sourceEnum.Values {
+			if !containsString(targetEnum.Values, v) {
+				ec.RemovedValues = append(ec.RemovedValues, v)
+			}
+		}
+		if len(ec.AddedValues) > 0 || len(ec.RemovedValues) > 0 {
+			changes.ModifiedEnums = append(changes.ModifiedEnums, ec)
+		}
+	}
+}
+
+func (d *Differ) compareDomains(changes *Changes) {
+	sourceMap := make(map[string]*schema.Domain)
+	for i := range d.source.Domains {
+		dom := &d.source.Domains[i]
+		sourceMap[d.key(dom.Schema, dom.Name)] = dom
+	}
+
+	targetMap := make(map[string]*schema.Domain)
+	for i := range d.target.Domains {
+		dom := &d.target.Domains[i]
+		targetMap[d.key(dom.Schema, dom.Name)] = dom
+	}
+
+	for i := range d.target.Domains {
+		dom := &d.target.Domains[i]
+		name := d.key(dom.Schema, dom.Name)
+		if _, exists := sourceMap[name]; !exists {
+			changes.AddedDomains = append(changes.AddedDomains, *dom)
+		}
+	}
+
+	for i := range d.source.Domains {
+		dom := &d.source.Domains[i]
+		name := d.key(dom.Schema, dom.Name)
+		if _, exists := targetMap[name]; !exists {
+			changes.RemovedDomains = append(changes.RemovedDomains, *dom)
+		}
+	}
+
+	for i := range d.source.Domains {
+		sourceDomain := &d.source.Domains[i]
+		name := d.key(sourceDomain.Schema, sourceDomain.Name)
+		if targetDomain, exists := targetMap[name]; exists && !sameDomain(sourceDomain, targetDomain) {
+			changes.ModifiedDomains = append(changes.ModifiedDomains, DomainChanges{
+				Name: targetDomain.Name,
+				Old:  *sourceDomain,
+				New:  *targetDomain,
+			})
+		}
+	}
+}
+
+func sameDomain(a, b *schema.Domain) bool {
+	if !sameType(a.Type, b.Type) || a.NotNull != b.NotNull || !sameDefault(a.Default, b.Default) {
+		return false
+	}
+	if !strings.EqualFold(strings.Trim(a.Collation, `"`), strings.Trim(b.Collation, `"`)) {
+		return false
+	}
+	if len(a.Checks) != len(b.Checks) {
+		return false
+	}
+	for i := range a.Checks {
+		if normalizeExpr(a.Checks[i].Expression) != normalizeExpr(b.Checks[i].Expression) {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *Differ) compareSequences(changes *Changes) {
+	sourceMap := make(map[string]*schema.Sequence)
+	for i := range d.source.Sequences {
+		seq := &d.source.Sequences[i]
+		sourceMap[d.key(seq.Schema, seq.Name)] = seq
+	}
+
+	targetMap := make(map[string]*schema.Sequence)
+	for i := range d.target.Sequences {
+		seq := &d.target.Sequences[i]
+		targetMap[d.key(seq.Schema, seq.Name)] = seq
+	}
+
+	for i := range d.target.Sequences {
+		seq := &d.target.Sequences[i]
+		name := d.key(seq.Schema, seq.Name)
+		if _, exists := sourceMap[name]; !exists {
+			changes.AddedSequences = append(changes.AddedSequences, *seq)
+		}
+	}
+
+	for i := range d.source.Sequences {
+		seq := &d.source.Sequences[i]
+		name := d.key(seq.Schema, seq.Name)
+		if _, exists := targetMap[name]; !exists {
+			changes.RemovedSequences = append(changes.RemovedSequences, *seq)
+		}
+	}
+
+	for i := range d.source.Sequences {
+		sourceSeq := &d.source.Sequences[i]
+		name := d.key(sourceSeq.Schema, sourceSeq.Name)
+		if targetSeq, exists := targetMap[name]; exists && !sameSequence(sourceSeq, targetSeq) {
+			changes.ModifiedSequences = append(changes.ModifiedSequences, SequenceChanges{
+				Name: targetSeq.Name,
+				Old:  *sourceSeq,
+				New:  *targetSeq,
+			})
+		}
+	}
+}
+
+func sameSequence(a, b *schema.Sequence) bool {
+	return strings.EqualFold(sequenceType(a.Type), sequenceType(b.Type)) &&
+		sameSequenceOptions(a.SequenceOptions, b.SequenceOptions) &&
+		strings.EqualFold(a.OwnedBy, b.OwnedBy)
+}
+
+// sequenceType defaults an unspecified sequence type to BIGINT.
+func sequenceType(t string) string {
+	if t == "" {
+		return "BIGINT"
+	}
+	return t
+}
+
+// sameIdentity compares identity definitions. A missing identity equals
+// an identity with default options and no explicit generation.
+func sameIdentity(a, b *schema.Identity) bool {
+	if a == nil {
+		a = &schema.Identity{}
+	}
+	if b == nil {
+		b = &schema.Identity{}
+	}
+	return strings.EqualFold(a.Generation, b.Generation) && sameSequenceOptions(a.SequenceOptions, b.SequenceOptions)
+}
+
+// sameSequenceOptions compares options treating unset values as their
+// defaults: start, increment and cache of 1.
+func sameSequenceOptions(a, b schema.SequenceOptions) bool {
+	value := func(v *int64, def int64) int64 {
+		if v == nil {
+			return def
+		}
+		return *v
+	}
+	same := func(x, y *int64) bool {
+		if x == nil || y == nil {
+			return x == nil && y == nil
+		}
+		return *x == *y
+	}
+	return value(a.Start, 1) == value(b.Start, 1) &&
+		value(a.Increment, 1) == value(b.Increment, 1) &&
+		value(a.Cache, 1) == value(b.Cache, 1) &&
+		same(a.MinValue, b.MinValue) && same(a.MaxValue, b.MaxValue) &&
+		a.Cycle == b.Cycle
+}
+
+func (d *Differ) compareRoutines(changes *Changes) {
+	overloaded := d.overloadedRoutines(d.source.Routines, d.target.Routines)
+
+	sourceMap := make(map[string]*schema.Routine)
+	for i := range d.source.Routines {
+		r := &d.source.Routines[i]
+		sourceMap[d.routineKey(r, overloaded)] = r
+	}
+
+	targetMap := make(map[string]*schema.Routine)
+	for i := range d.target.Routines {
+		r := &d.target.Routines[i]
+		targetMap[d.routineKey(r, overloaded)] = r
+	}
+
+	for i := range d.target.Routines {
+		r := &d.target.Routines[i]
+		key := d.routineKey(r, overloaded)
+		if _, exists := sourceMap[key]; !exists {
+			changes.AddedRoutines = append(changes.AddedRoutines, *r)
+		}
+	}
+
+	for i := range d.source.Routines {
+		r := &d.source.Routines[i]
+		key := d.routineKey(r, overloaded)
+		if _, exists := targetMap[key]; !exists {
+			changes.RemovedRoutines = append(changes.RemovedRoutines, *r)
+		}
+	}
+
+	for i := range d.source.Routines {
+		sourceRoutine := &d.source.Routines[i]
+		key := d.routineKey(sourceRoutine, overloaded)
+		if targetRoutine, exists := targetMap[key]; exists && !sameRoutine(sourceRoutine, targetRoutine) {
+			changes.ModifiedRoutines = append(changes.ModifiedRoutines, RoutineChanges{
+				Name: targetRoutine.Name,
+				Old:  *sourceRoutine,
+				New:  *targetRoutine,
+			})
+		}
+	}
+}
+
+// overloadedRoutines returns the routine names that either schema defines
+// more than once. Those are told apart by their argument types; all others
+// match by name alone, so a changed parameter list is a modification.
+func (d *Differ) overloadedRoutines(source, target []schema.Routine) map[string]bool {
+	overloaded := make(map[string]bool)
+	for _, routines := range [][]schema.Routine{source, target} {
+		seen := make(map[string]bool)
+		for i := range routines {
+			name := d.routineName(&routines[i])
+			if seen[name] {
+				overloaded[name] = true
+			}
+			seen[name] = true
+		}
+	}
+	return overloaded
+}
+
+func (d *Differ) routineName(r *schema.Routine) string {
+	return r.Kind + " " + strings.ToLower(d.key(r.Schema, r.Name))
+}
+
+func (d *Differ) routineKey(r *schema.Routine, overloaded map[string]bool) string {
+	name := d.routineName(r)
+	if overloaded[name] {
+		return name + "(" + strings.Join(r.ArgTypes(), ", ") + ")"
+	}
+	return name
+}
+
+func sameRoutine(a, b *schema.Routine) bool {
+	return normalizeTokens(a.Signature) == normalizeTokens(b.Signature) &&
+		normalizeTokens(a.Returns) == normalizeTokens(b.Returns) &&
+		strings.EqualFold(a.Language, b.Language) &&
+		normalizeTokens(a.Body) == normalizeTokens(b.Body)
+}
+
+func (d *Differ) compareTriggers(changes *Changes) {
+	sourceMap := make(map[string]*schema.Trigger)
+	for i := range d.source.Triggers {
+		t := &d.source.Triggers[i]
+		sourceMap[d.triggerKey(t)] = t
+	}
+
+	targetMap := make(map[string]*schema.Trigger)
+	for i := range d.target.Triggers {
+		t := &d.target.Triggers[i]
+		targetMap[d.triggerKey(t)] = t
+	}
+
+	for i := range d.target.Triggers {
+		t := &d.target.Triggers[i]
+		key := d.triggerKey(t)
+		if _, exists := sourceMap[key]; !exists {
+			changes.AddedTriggers = append(changes.AddedTriggers, *t)
+		}
+	}
+
+	for i := range d.source.Triggers {
+		t := &d.source.Triggers[i]
+		key := d.triggerKey(t)
+		if _, exists := targetMap[key]; !exists {
+			changes.RemovedTriggers = append(changes.RemovedTriggers, *t)
+		}
+	}
+
+	for i := range d.source.Triggers {
+		sourceTrigger := &d.source.Triggers[i]
+		key := d.triggerKey(sourceTrigger)
+		if targetTrigger, exists := targetMap[key]; exists && !sameTrigger(sourceTrigger, targetTrigger) {
+			changes.ModifiedTriggers = append(changes.ModifiedTriggers, TriggerChanges{
+				Name: targetTrigger.Name,
+				Old:  *sourceTrigger,
+				New:  *targetTrigger,
+			})
+		}
+	}
+}
+
+// triggerKey identifies a trigger by its table and name, as PostgreSQL
+// scopes trigger names to their table.
+func (d *Differ) triggerKey(t *schema.Trigger) string {
+	return strings.ToLower(d.key(t.Schema, t.Table) + "." + t.Name)
+}
+
+func sameTrigger(a, b *schema.Trigger) bool {
+	return strings.EqualFold(a.Timing, b.Timing) &&
+		sameEvents(a.Events, b.Events) &&
+		triggerForEach(a.ForEach) == triggerForEach(b.ForEach) &&
+		normalizeExpr(a.When) == normalizeExpr(b.When) &&
+		normalizeTokens(a.Body) == normalizeTokens(b.Body)
+}
+
+// sameEvents compares trigger events regardless of order.
+func sameEvents(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, event := range a {
+		if !containsFold(b, event) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, v string) bool {
+	for _, x := range values {
+		if strings.EqualFold(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// triggerForEach defaults an unspecified trigger level to STATEMENT.
+func triggerForEach(forEach string) string {
+	if forEach == "" {
+		return "STATEMENT"
+	}
+	return strings.ToUpper(forEach)
+}
+
+// normalizeTokens normalizes SQL such as a routine body for comparison,
+// ignoring comments, layout and trailing semicolons.
+func normalizeTokens(sql string) string {
+	tokens, err := schema.NewLexer(sql, "postgres").Tokenize()
+	if err != nil {
+		return normalizeSQL(sql)
+	}
+	var parts []string
+	for _, tok := range tokens {
+		if tok.Kind != schema.TokenEOF {
+			parts = append(parts, tok.Raw)
+		}
+	}
+	for len(parts) > 0 && parts[len(parts)-1] == ";" {
+		parts = parts[:len(parts)-1]
+	}
+	return normalizeSQL(strings.Join(parts, " "))
+}
+
+func containsString(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
 func normalizeSQL(sql string) string {
 	// Normalize whitespace for comparison
 	sql = strings.TrimSpace(sql)
@@ -377,14 +1553,33 @@ func normalizeSQL(sql string) string {
 
 // IsEmpty returns true if there are no changes.
 func (c *Changes) IsEmpty() bool {
-	return len(c.AddedTables) == 0 &&
+	return len(c.AddedSchemas) == 0 &&
+		len(c.RemovedSchemas) == 0 &&
+		len(c.RenamedTables) == 0 &&
+		len(c.AddedTables) == 0 &&
 		len(c.RemovedTables) == 0 &&
 		len(c.ModifiedTables) == 0 &&
 		len(c.AddedIndexes) == 0 &&
 		len(c.RemovedIndexes) == 0 &&
+		len(c.ModifiedIndexes) == 0 &&
 		len(c.AddedViews) == 0 &&
 		len(c.RemovedViews) == 0 &&
-		len(c.ModifiedViews) == 0
+		len(c.ModifiedViews) == 0 &&
+		len(c.AddedEnums) == 0 &&
+		len(c.RemovedEnums) == 0 &&
+		len(c.ModifiedEnums) == 0 &&
+		len(c.AddedDomains) == 0 &&
+		len(c.RemovedDomains) == 0 &&
+		len(c.ModifiedDomains) == 0 &&
+		len(c.AddedSequences) == 0 &&
+		len(c.RemovedSequences) == 0 &&
+		len(c.ModifiedSequences) == 0 &&
+		len(c.AddedRoutines) == 0 &&
+		len(c.RemovedRoutines) == 0 &&
+		len(c.ModifiedRoutines) == 0 &&
+		len(c.AddedTriggers) == 0 &&
+		len(c.RemovedTriggers) == 0 &&
+		len(c.ModifiedTriggers) == 0
 }
 
 // WriteText writes a human-readable diff output.
@@ -397,11 +1592,86 @@ func WriteText(w io.Writer, c *Changes) error {
 		return err
 	}
 
+	// Schemas
+	if len(c.AddedSchemas) > 0 || len(c.RemovedSchemas) > 0 {
+		sb.WriteString("Schemas:\n")
+		for _, name := range c.AddedSchemas {
+			sb.WriteString(fmt.Sprintf("  + %s\n", name))
+		}
+		for _, name := range c.RemovedSchemas {
+			sb.WriteString(fmt.Sprintf("  - %s\n", name))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Types
+	if len(c.AddedEnums) > 0 || len(c.AddedDomains) > 0 {
+		sb.WriteString("Added Types:\n")
+		for _, e := range c.AddedEnums {
+			sb.WriteString(fmt.Sprintf("  + enum %s (%s)\n", displayName(e.Schema, e.Name), strings.Join(e.Values, ", ")))
+		}
+		for _, dom := range c.AddedDomains {
+			sb.WriteString(fmt.Sprintf("  + domain %s %s\n", displayName(dom.Schema, dom.Name), dom.Type))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(c.RemovedEnums) > 0 || len(c.RemovedDomains) > 0 {
+		sb.WriteString("Removed Types:\n")
+		for _, e := range c.RemovedEnums {
+			sb.WriteString(fmt.Sprintf("  - enum %s\n", displayName(e.Schema, e.Name)))
+		}
+		for _, dom := range c.RemovedDomains {
+			sb.WriteString(fmt.Sprintf("  - domain %s\n", displayName(dom.Schema, dom.Name)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(c.ModifiedEnums) > 0 || len(c.ModifiedDomains) > 0 {
+		sb.WriteString("Modified Types:\n")
+		for _, e := range c.ModifiedEnums {
+			for _, v := range e.AddedValues {
+				sb.WriteString(fmt.Sprintf("  ~ enum %s: + value '%s'\n", displayName(e.Schema, e.Name), v))
+			}
+			for _, v := range e.RemovedValues {
+				sb.WriteString(fmt.Sprintf("  ~ enum %s: - value '%s'\n", displayName(e.Schema, e.Name), v))
+			}
+		}
+		for _, dom := range c.ModifiedDomains {
+			sb.WriteString(fmt.Sprintf("  ~ domain %s (definition changed)\n", displayName(dom.New.Schema, dom.Name)))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Sequences
+	if len(c.AddedSequences) > 0 || len(c.RemovedSequences) > 0 || len(c.ModifiedSequences) > 0 {
+		sb.WriteString("Sequences:\n")
+		for _, seq := range c.AddedSequences {
+			sb.WriteString(fmt.Sprintf("  + %s\n", displayName(seq.Schema, seq.Name)))
+		}
+		for _, seq := range c.RemovedSequences {
+			sb.WriteString(fmt.Sprintf("  - %s\n", displayName(seq.Schema, seq.Name)))
+		}
+		for _, sc := range c.ModifiedSequences {
+			sb.WriteString(fmt.Sprintf("  ~ %s (options changed)\n", displayName(sc.New.Schema, sc.Name)))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Added tables
 	if len(c.AddedTables) > 0 {
 		sb.WriteString("Added Tables:\n")
 		for _, t := range c.AddedTables {
-			sb.WriteString(fmt.Sprintf("  + %s (%d columns)\n", t.Name, len(t.Columns)))
+			sb.WriteString(fmt.Sprintf("  + %s (%d columns)\n", displayName(t.Schema, t.Name), len(t.Columns)))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Renamed tables
+	if len(c.RenamedTables) > 0 {
+		sb.WriteString("Renamed Tables:\n")
+		for _, r := range c.RenamedTables {
+			sb.WriteString(fmt.Sprintf("  ~ %s → %s\n", displayName(r.Schema, r.OldName), r.NewName))
 		}
 		sb.WriteString("\n")
 	}
@@ -410,14 +1680,14 @@ func WriteText(w io.Writer, c *Changes) error {
 	if len(c.RemovedTables) > 0 {
 		sb.WriteString("Removed Tables:\n")
 		for _, t := range c.RemovedTables {
-			sb.WriteString(fmt.Sprintf("  - %s\n", t.Name))
+			sb.WriteString(fmt.Sprintf("  - %s\n", displayName(t.Schema, t.Name)))
 		}
 		sb.WriteString("\n")
 	}
 
 	// Modified tables
 	for _, tc := range c.ModifiedTables {
-		sb.WriteString(fmt.Sprintf("Modified Table: %s\n", tc.Name))
+		sb.WriteString(fmt.Sprintf("Modified Table: %s\n", displayName(tc.Schema, tc.Name)))
 		sb.WriteString(strings.Repeat("-", 40) + "\n")
 
 		for _, col := range tc.AddedColumns {
@@ -426,6 +1696,9 @@ func WriteText(w io.Writer, c *Changes) error {
 		for _, col := range tc.RemovedColumns {
 			sb.WriteString(fmt.Sprintf("  - Column: %s\n", col.Name))
 		}
+		for _, r := range tc.RenamedColumns {
+			sb.WriteString(fmt.Sprintf("  ~ Column %s: renamed to %s\n", r.OldName, r.NewName))
+		}
 		for _, col := range tc.ModifiedColumns {
 			if col.OldType != "" {
 				sb.WriteString(fmt.Sprintf("  ~ Column %s: type %s → %s\n", col.Name, col.OldType, col.NewType))
@@ -439,6 +1712,15 @@ func WriteText(w io.Writer, c *Changes) error {
 				}
 				sb.WriteString(fmt.Sprintf("  ~ Column %s: %s → %s\n", col.Name, nullable(col.OldNullable), nullable(col.NewNullable)))
 			}
+			if col.CommentChanged {
+				sb.WriteString(fmt.Sprintf("  ~ Column %s: comment changed\n", col.Name))
+			}
+			if col.OnUpdateChanged {
+				sb.WriteString(fmt.Sprintf("  ~ Column %s: ON UPDATE %s → %s\n", col.Name, describeOnUpdate(col.OldOnUpdate), describeOnUpdate(col.NewOnUpdate)))
+			}
+			if col.IdentityChanged {
+				sb.WriteString(fmt.Sprintf("  ~ Column %s: identity %s → %s\n", col.Name, describeIdentity(col.OldIdentity), describeIdentity(col.NewIdentity)))
+			}
 		}
 
 		for _, idx := range tc.AddedIndexes {
@@ -447,6 +1729,9 @@ func WriteText(w io.Writer, c *Changes) error {
 		for _, idx := range tc.RemovedIndexes {
 			sb.WriteString(fmt.Sprintf("  - Index: %s\n", idx.Name))
 		}
+		for _, ic := range tc.ModifiedIndexes {
+			sb.WriteString(fmt.Sprintf("  ~ Index: %s (definition changed)\n", ic.Name))
+		}
 
 		for _, fk := range tc.AddedForeignKeys {
 			sb.WriteString(fmt.Sprintf("  + FK: %s → %s\n", strings.Join(fk.Columns, ", "), fk.ReferencedTable))
@@ -454,9 +1739,23 @@ func WriteText(w io.Writer, c *Changes) error {
 		for _, fk := range tc.RemovedForeignKeys {
 			sb.WriteString(fmt.Sprintf("  - FK: %s → %s\n", strings.Join(fk.Columns, ", "), fk.ReferencedTable))
 		}
+		for _, fc := range tc.ModifiedForeignKeys {
+			sb.WriteString(fmt.Sprintf("  ~ FK: %s → %s (definition changed)\n", strings.Join(fc.New.Columns, ", "), fc.New.ReferencedTable))
+		}
+
+		for _, c := range tc.AddedConstraints {
+			sb.WriteString(fmt.Sprintf("  + Constraint: %s\n", describeConstraint(&c)))
+		}
+		for _, c := range tc.RemovedConstraints {
+			sb.WriteString(fmt.Sprintf("  - Constraint: %s\n", describeConstraint(&c)))
+		}
 
 		if tc.PrimaryKeyChanged {
-			sb.WriteString("  ~ Primary key changed\n")
+			sb.WriteString(fmt.Sprintf("  ~ Primary key: %s → %s\n", describePrimaryKey(tc.OldPrimaryKey), describePrimaryKey(tc.NewPrimaryKey)))
+		}
+
+		if tc.CommentChanged {
+			sb.WriteString("  ~ Comment changed\n")
 		}
 
 		sb.WriteString("\n")
@@ -466,7 +1765,7 @@ func WriteText(w io.Writer, c *Changes) error {
 	if len(c.AddedIndexes) > 0 {
 		sb.WriteString("Added Indexes:\n")
 		for _, idx := range c.AddedIndexes {
-			sb.WriteString(fmt.Sprintf("  + %s ON %s\n", idx.Name, idx.Table))
+			sb.WriteString(fmt.Sprintf("  + %s ON %s\n", idx.Name, displayName(idx.Schema, idx.Table)))
 		}
 		sb.WriteString("\n")
 	}
@@ -480,11 +1779,20 @@ func WriteText(w io.Writer, c *Changes) error {
 		sb.WriteString("\n")
 	}
 
+	// Modified indexes
+	if len(c.ModifiedIndexes) > 0 {
+		sb.WriteString("Modified Indexes:\n")
+		for _, ic := range c.ModifiedIndexes {
+			sb.WriteString(fmt.Sprintf("  ~ %s ON %s\n", ic.Name, displayName(ic.New.Schema, ic.New.Table)))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Views
 	if len(c.AddedViews) > 0 {
 		sb.WriteString("Added Views:\n")
 		for _, v := range c.AddedViews {
-			sb.WriteString(fmt.Sprintf("  + %s\n", v.Name))
+			sb.WriteString(fmt.Sprintf("  + %s\n", displayName(v.Schema, v.Name)))
 		}
 		sb.WriteString("\n")
 	}
@@ -492,7 +1800,7 @@ func WriteText(w io.Writer, c *Changes) error {
 	if len(c.RemovedViews) > 0 {
 		sb.WriteString("Removed Views:\n")
 		for _, v := range c.RemovedViews {
-			sb.WriteString(fmt.Sprintf("  - %s\n", v.Name))
+			sb.WriteString(fmt.Sprintf("  - %s\n", displayName(v.Schema, v.Name)))
 		}
 		sb.WriteString("\n")
 	}
@@ -500,7 +1808,36 @@ func WriteText(w io.Writer, c *Changes) error {
 	if len(c.ModifiedViews) > 0 {
 		sb.WriteString("Modified Views:\n")
 		for _, v := range c.ModifiedViews {
-			sb.WriteString(fmt.Sprintf("  ~ %s (definition changed)\n", v.Name))
+			sb.WriteString(fmt.Sprintf("  ~ %s (definition changed)\n", displayName(v.Schema, v.Name)))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Routines and triggers
+	if len(c.AddedRoutines) > 0 || len(c.RemovedRoutines) > 0 || len(c.ModifiedRoutines) > 0 {
+		sb.WriteString("Routines:\n")
+		for _, r := range c.AddedRoutines {
+			sb.WriteString(fmt.Sprintf("  + %s %s%s\n", strings.ToLower(r.Kind), displayName(r.Schema, r.Name), r.Signature))
+		}
+		for _, r := range c.RemovedRoutines {
+			sb.WriteString(fmt.Sprintf("  - %s %s%s\n", strings.ToLower(r.Kind), displayName(r.Schema, r.Name), r.Signature))
+		}
+		for _, rc := range c.ModifiedRoutines {
+			sb.WriteString(fmt.Sprintf("  ~ %s %s%s (definition changed)\n", strings.ToLower(rc.New.Kind), displayName(rc.New.Schema, rc.Name), rc.New.Signature))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(c.AddedTriggers) > 0 || len(c.RemovedTriggers) > 0 || len(c.ModifiedTriggers) > 0 {
+		sb.WriteString("Triggers:\n")
+		for _, t := range c.AddedTriggers {
+			sb.WriteString(fmt.Sprintf("  + %s ON %s\n", t.Name, displayName(t.Schema, t.Table)))
+		}
+		for _, t := range c.RemovedTriggers {
+			sb.WriteString(fmt.Sprintf("  - %s ON %s\n", t.Name, displayName(t.Schema, t.Table)))
+		}
+		for _, tc := range c.ModifiedTriggers {
+			sb.WriteString(fmt.Sprintf("  ~ %s ON %s (definition changed)\n", tc.Name, displayName(tc.New.Schema, tc.New.Table)))
 		}
 	}
 
@@ -508,6 +1845,57 @@ func WriteText(w io.Writer, c *Changes) error {
 	return err
 }
 
+// displayName qualifies a name with its schema, if it has one.
+func displayName(schemaName, name string) string {
+	if schemaName == "" {
+		return name
+	}
+	return schemaName + "." + name
+}
+
+// describeOnUpdate renders a MySQL ON UPDATE expression, or none.
+func describeOnUpdate(expr string) string {
+	if expr == "" {
+		return "none"
+	}
+	return expr
+}
+
+// describePrimaryKey lists the columns of a primary key, or says none.
+func describePrimaryKey(pk *schema.PrimaryKey) string {
+	if pk == nil {
+		return "none"
+	}
+	return "(" + strings.Join(pk.Columns, ", ") + ")"
+}
+
+func describeConstraint(c *schema.Constraint) string {
+	desc := strings.ToUpper(c.Type)
+	if strings.EqualFold(c.Type, "CHECK") {
+		desc += " (" + c.Expression + ")"
+	} else {
+		desc += " (" + strings.Join(c.Columns, ", ") + ")"
+	}
+	if c.Name != "" {
+		desc = c.Name + " " + desc
+	}
+	return desc
+}
+
+func describeIdentity(identity *schema.Identity) string {
+	if identity == nil {
+		return "none"
+	}
+	desc := "IDENTITY"
+	if identity.Generation != "" {
+		desc = "GENERATED " + identity.Generation + " AS IDENTITY"
+	}
+	if opts := schema.NewGenerator("postgres").GenerateSequenceOptions(&identity.SequenceOptions); opts != "" {
+		desc += " (" + opts + ")"
+	}
+	return desc
+}
+
 // WriteJSON writes the changes as JSON.
 func WriteJSON(w io.Writer, c *Changes) error {
 	enc := json.NewEncoder(w)
@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/egoughnour/migrate/internal/schema"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+var dialects = []string{"postgres", "mysql", "sqlserver", "sqlite"}
+
+// outputs are the formats the diff command writes, keyed by golden file name.
+var outputs = []struct {
+	file  string
+	write func(w io.Writer, dialect string, c *Changes) error
+}{
+	{"diff.txt", func(w io.Writer, _ string, c *Changes) error { return WriteText(w, c) }},
+	{"diff.json", func(w io.Writer, _ string, c *Changes) error { return WriteJSON(w, c) }},
+	{"diff.sql", func(w io.Writer, dialect string, c *Changes) error { return NewSQLGenerator(dialect).WriteSQL(w, c) }},
+}
+
+// compareTestdata diffs testdata/<dialect>/simple.sql against changed.sql.
+func compareTestdata(t *testing.T, dialect string) *Changes {
+	t.Helper()
+	dir := filepath.Join("..", "..", "testdata", dialect)
+	source, err := schema.ParseFile(filepath.Join(dir, "simple.sql"), dialect)
+	if err != nil {
+		t.Fatalf("parse source: %v", err)
+	}
+	target, err := schema.ParseFile(filepath.Join(dir, "changed.sql"), dialect)
+	if err != nil {
+		t.Fatalf("parse target: %v", err)
+	}
+	return NewDifferWithOptions(source, target, Options{DefaultSchema: schema.DefaultSchema(dialect)}).Compare()
+}
+
+func TestGolden(t *testing.T) {
+	for _, dialect := range dialects {
+		for _, out := range outputs {
+			t.Run(dialect+"/"+out.file, func(t *testing.T) {
+				var buf bytes.Buffer
+				if err := out.write(&buf, dialect, compareTestdata(t, dialect)); err != nil {
+					t.Fatalf("write: %v", err)
+				}
+
+				path := filepath.Join("..", "..", "testdata", dialect, out.file)
+				if *update {
+					if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("%v (run with -update to create it)", err)
+				}
+				if !bytes.Equal(buf.Bytes(), want) {
+					t.Errorf("output differs from %s (run with -update to accept it)\ngot:\n%s\nwant:\n%s", path, buf.Bytes(), want)
+				}
+			})
+		}
+	}
+}
+
+// TestDeterministicOutput diffs the same schemas repeatedly, so that map
+// iteration order leaking into the output shows up as a failure.
+func TestDeterministicOutput(t *testing.T) {
+	const runs = 20
+	for _, dialect := range dialects {
+		for _, out := range outputs {
+			t.Run(dialect+"/"+out.file, func(t *testing.T) {
+				var first []byte
+				for i := 0; i < runs; i++ {
+					var buf bytes.Buffer
+					if err := out.write(&buf, dialect, compareTestdata(t, dialect)); err != nil {
+						t.Fatalf("write: %v", err)
+					}
+					if i == 0 {
+						first = buf.Bytes()
+						continue
+					}
+					if !bytes.Equal(buf.Bytes(), first) {
+						t.Fatalf("run %d differs from run 0\ngot:\n%s\nwant:\n%s", i, buf.Bytes(), first)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/egoughnour/migrate/internal/schema"
+)
+
+const (
+	// tableRenameThreshold is the share of columns a removed and an added
+	// table need in common to be taken for a renamed table.
+	tableRenameThreshold = 0.75
+
+	// columnRenameThreshold is the score a removed and an added column
+	// need to be taken for a renamed column; see columnRenameScore.
+	columnRenameThreshold = 1.5
+)
+
+// findRenames returns the tables and then the columns the target has under
+// new names, from Options.Renames and, unless turned off, from the removed
+// and added objects that look alike. Column renames name their table by
+// its new name. Renames that do not fit the schemas are left out; the
+// first of those is returned as the error.
+func (d *Differ) findRenames() ([]schema.Rename, error) {
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	olds := make([]string, 0, len(d.opts.Renames))
+	for old := range d.opts.Renames {
+		olds = append(olds, old)
+	}
+	sort.Strings(olds)
+
+	// Given renames of tables, then detected ones
+	var tableRenames []schema.Rename
+	var columnOlds []string
+	for _, old := range olds {
+		parts := strings.Split(old, ".")
+		switch {
+		case len(parts) == 1 || (len(parts) == 2 && d.sourceTable(parts[0], parts[1]) != nil):
+			r, err := d.resolveTableRename(old, d.opts.Renames[old])
+			if err != nil {
+				fail(err)
+				continue
+			}
+			tableRenames = append(tableRenames, r)
+		case len(parts) <= 3:
+			columnOlds = append(columnOlds, old)
+		default:
+			fail(fmt.Errorf("rename %s: expected table, schema.table, table.column or schema.table.column", old))
+		}
+	}
+	if !d.opts.NoRenameDetection {
+		tableRenames = append(tableRenames, d.detectTableRenames(tableRenames)...)
+	}
+	renamed := d.source.ApplyRenames(tableRenames)
+
+	// Given renames of columns, then detected ones
+	var columnRenames []schema.Rename
+	for _, old := range columnOlds {
+		r, err := d.resolveColumnRename(tableRenames, old, d.opts.Renames[old])
+		if err != nil {
+			fail(err)
+			continue
+		}
+		columnRenames = append(columnRenames, r)
+	}
+	if !d.opts.NoRenameDetection {
+		columnRenames = append(columnRenames, d.detectColumnRenames(renamed, columnRenames)...)
+	}
+
+	return append(tableRenames, columnRenames...), firstErr
+}
+
+// resolveTableRename checks a given table rename against the schemas.
+func (d *Differ) resolveTableRename(old, newName string) (schema.Rename, error) {
+	schemaName, name := "", old
+	if dot := strings.Index(old, "."); dot >= 0 {
+		schemaName, name = old[:dot], old[dot+1:]
+	}
+	source := d.sourceTable(schemaName, name)
+	if source == nil {
+		return schema.Rename{}, fmt.Errorf("rename %s=%s: source has no table %s", old, newName, old)
+	}
+	if d.findTable(d.target, d.key(source.Schema, newName)) == nil {
+		return schema.Rename{}, fmt.Errorf("rename %s=%s: target has no table %s", old, newName, displayName(source.Schema, newName))
+	}
+	return schema.Rename{Schema: source.Schema, Table: source.Name, NewName: newName}, nil
+}
+
+// resolveColumnRename checks a given column rename against the schemas,
+// naming its table as tableRenames leave it.
+func (d *Differ) resolveColumnRename(tableRenames []schema.Rename, old, newName string) (schema.Rename, error) {
+	parts := strings.Split(old, ".")
+	schemaName, tableName, colName := "", parts[0], parts[1]
+	if len(parts) == 3 {
+		schemaName, tableName, colName = parts[0], parts[1], parts[2]
+	}
+	source := d.sourceTable(schemaName, tableName)
+	if source == nil {
+		return schema.Rename{}, fmt.Errorf("rename %s=%s: source has no table %s", old, newName, strings.Join(parts[:len(parts)-1], "."))
+	}
+	if findColumn(source, colName) == nil {
+		return schema.Rename{}, fmt.Errorf("rename %s=%s: source has no column %s", old, newName, old)
+	}
+
+	// The table may be renamed too
+	tableName = source.Name
+	for _, r := range tableRenames {
+		if d.key(r.Schema, r.Table) == d.key(source.Schema, source.Name) {
+			tableName = r.NewName
+		}
+	}
+	target := d.findTable(d.target, d.key(source.Schema, tableName))
+	if target == nil || findColumn(target, newName) == nil {
+		return schema.Rename{}, fmt.Errorf("rename %s=%s: target has no column %s.%s", old, newName, displayName(source.Schema, tableName), newName)
+	}
+	return schema.Rename{Schema: source.Schema, Table: tableName, Column: findColumn(source, colName).Name, NewName: newName}, nil
+}
+
+// detectTableRenames pairs the tables only the source has with those only
+// the target has that share most of their columns, in the same schema.
+func (d *Differ) detectTableRenames(given []schema.Rename) []schema.Rename {
+	renamedFrom := make(map[string]bool)
+	renamedTo := make(map[string]bool)
+	for _, r := range given {
+		renamedFrom[d.key(r.Schema, r.Table)] = true
+		renamedTo[d.key(r.Schema, r.NewName)] = true
+	}
+
+	var removed, added []*schema.Table
+	for i := range d.source.Tables {
+		t := &d.source.Tables[i]
+		key := d.key(t.Schema, t.Name)
+		if !renamedFrom[key] && d.findTable(d.target, key) == nil {
+			removed = append(removed, t)
+		}
+	}
+	for i := range d.target.Tables {
+		t := &d.target.Tables[i]
+		key := d.key(t.Schema, t.Name)
+		if !renamedTo[key] && d.findTable(d.source, key) == nil {
+			added = append(added, t)
+		}
+	}
+
+	var candidates []renameCandidate
+	for i, source := range removed {
+		for j, target := range added {
+			if d.key(source.Schema, "") != d.key(target.Schema, "") {
+				continue
+			}
+			if score := tableRenameScore(source, target); score >= tableRenameThreshold {
+				candidates = append(candidates, renameCandidate{source: i, target: j, score: score})
+			}
+		}
+	}
+
+	var renames []schema.Rename
+	for _, c := range pickRenames(candidates) {
+		renames = append(renames, schema.Rename{
+			Schema:  removed[c.source].Schema,
+			Table:   removed[c.source].Name,
+			NewName: added[c.target].Name,
+		})
+	}
+	return renames
+}
+
+// detectColumnRenames pairs the columns only the source has with those
+// only the target has in each table both have, where their definitions
+// and names suggest a rename.
+func (d *Differ) detectColumnRenames(renamed *schema.Schema, given []schema.Rename) []schema.Rename {
+	var renames []schema.Rename
+	for i := range renamed.Tables {
+		source := &renamed.Tables[i]
+		tableKey := d.key(source.Schema, source.Name)
+		target := d.findTable(d.target, tableKey)
+		if target == nil {
+			continue
+		}
+
+		renamedFrom := make(map[string]bool)
+		renamedTo := make(map[string]bool)
+		for _, r := range given {
+			if d.key(r.Schema, r.Table) == tableKey {
+				renamedFrom[r.Column] = true
+				renamedTo[r.NewName] = true
+			}
+		}
+
+		var removed, added []int
+		for j := range source.Columns {
+			name := source.Columns[j].Name
+			if !renamedFrom[name] && findColumn(target, name) == nil {
+				removed = append(removed, j)
+			}
+		}
+		for j := range target.Columns {
+			name := target.Columns[j].Name
+			if !renamedTo[name] && findColumn(source, name) == nil {
+				added = append(added, j)
+			}
+		}
+
+		var candidates []renameCandidate
+		for si, sj := range removed {
+			for ti, tj := range added {
+				if score := d.columnRenameScore(source, sj, target, tj); score >= columnRenameThreshold {
+					candidates = append(candidates, renameCandidate{source: si, target: ti, score: score})
+				}
+			}
+		}
+		for _, c := range pickRenames(candidates) {
+			renames = append(renames, schema.Rename{
+				Schema:  source.Schema,
+				Table:   source.Name,
+				Column:  source.Columns[removed[c.source]].Name,
+				NewName: target.Columns[added[c.target]].Name,
+			})
+		}
+	}
+	return renames
+}
+
+// tableRenameScore is the share of columns two tables have in common, by
+// name and type. One column in common, such as an id, tells too little
+// and scores 0.
+func tableRenameScore(a, b *schema.Table) float64 {
+	common := 0
+	for i := range a.Columns {
+		if col := findColumn(b, a.Columns[i].Name); col != nil && sameType(col.Type, a.Columns[i].Type) {
+			common++
+		}
+	}
+	if common < 2 {
+		return 0
+	}
+	return float64(common) / float64(max(len(a.Columns), len(b.Columns)))
+}
+
+// columnRenameScore rates how likely it is that column i of source was
+// renamed to column j of target: the similarity of their names (0 to 1),
+// plus 0.25 each for the same nullability and default, 0.5 for the same
+// place next to another column and 0.5 for referencing the same key.
+// Columns of different types or generation, or referencing different
+// keys, are never renames of each other.
+func (d *Differ) columnRenameScore(source *schema.Table, i int, target *schema.Table, j int) float64 {
+	a, b := &source.Columns[i], &target.Columns[j]
+	if !sameType(a.Type, b.Type) || !sameGenerated(a.Generated, b.Generated) {
+		return 0
+	}
+
+	score := nameSimilarity(a.Name, b.Name)
+	refA, refB := d.columnReference(source, a.Name), d.columnReference(target, b.Name)
+	switch {
+	case refA != "" && refB != "" && refA != refB:
+		return 0
+	case refA != "" && refA == refB:
+		score += 0.5
+	}
+	if a.Nullable == b.Nullable {
+		score += 0.25
+	}
+	if sameDefault(a.Default, b.Default) {
+		score += 0.25
+	}
+	if neighbor(source.Columns, i, -1) == neighbor(target.Columns, j, -1) ||
+		neighbor(source.Columns, i, 1) == neighbor(target.Columns, j, 1) {
+		score += 0.5
+	}
+	return score
+}
+
+// columnReference returns the table and column a column references by a
+// foreign key, if any.
+func (d *Differ) columnReference(t *schema.Table, col string) string {
+	for _, fk := range t.ForeignKeys {
+		for i, c := range fk.Columns {
+			if c == col && i < len(fk.ReferencedCols) {
+				return d.key(fk.ReferencedSchema, fk.ReferencedTable) + "." + fk.ReferencedCols[i]
+			}
+		}
+	}
+	return ""
+}
+
+// neighbor returns the name of the column step places from column i, or
+// "" past either end.
+func neighbor(cols []schema.Column, i, step int) string {
+	if i+step < 0 || i+step >= len(cols) {
+		return ""
+	}
+	return cols[i+step].Name
+}
+
+// nameSimilarity rates two names from 0 to 1 by the length of their
+// longest common subsequence, ignoring case: full_name and name rate 0.62.
+func nameSimilarity(a, b string) float64 {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if len(a)+len(b) == 0 {
+		return 1
+	}
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return 2 * float64(prev[len(b)]) / float64(len(a)+len(b))
+}
+
+// renameCandidate pairs the indexes of a removed and an added object with
+// the score of the pair.
+type renameCandidate struct {
+	source, target int
+	score          float64
+}
+
+// pickRenames takes the best scoring candidates first, pairing each object
+// at most once, and returns them in source order. Ties go to the pair
+// listed first.
+func pickRenames(candidates []renameCandidate) []renameCandidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	usedSource := make(map[int]bool)
+	usedTarget := make(map[int]bool)
+	var picked []renameCandidate
+	for _, c := range candidates {
+		if usedSource[c.source] || usedTarget[c.target] {
+			continue
+		}
+		usedSource[c.source] = true
+		usedTarget[c.target] = true
+		picked = append(picked, c)
+	}
+	sort.Slice(picked, func(i, j int) bool {
+		return picked[i].source < picked[j].source
+	})
+	return picked
+}
+
+// sourceTable finds a source table by name, in any schema when schemaName
+// is empty.
+func (d *Differ) sourceTable(schemaName, name string) *schema.Table {
+	for i := range d.source.Tables {
+		t := &d.source.Tables[i]
+		if strings.EqualFold(t.Name, name) && (schemaName == "" || d.key(schemaName, "") == d.key(t.Schema, "")) {
+			return t
+		}
+	}
+	return nil
+}
+
+// findTable finds the table of s with the given key.
+func (d *Differ) findTable(s *schema.Schema, key string) *schema.Table {
+	for i := range s.Tables {
+		if d.key(s.Tables[i].Schema, s.Tables[i].Name) == key {
+			return &s.Tables[i]
+		}
+	}
+	return nil
+}
+
+// findColumn finds a column of a table by name.
+func findColumn(t *schema.Table, name string) *schema.Column {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package diff
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/egoughnour/migrate/internal/schema"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"name", "name", 1},
+		{"Name", "NAME", 1},
+		{"full_name", "name", 8.0 / 13},
+		{"author_id", "writer_id", 10.0 / 18},
+		{"abc", "xyz", 0},
+		{"", "", 1},
+		{"id", "", 0},
+	}
+	for _, tt := range tests {
+		if got := nameSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("nameSimilarity(%q, %q) = %.4f, want %.4f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTableRenameScore(t *testing.T) {
+	table := func(cols ...string) *schema.Table {
+		t := &schema.Table{}
+		for i := 0; i < len(cols); i += 2 {
+			t.Columns = append(t.Columns, schema.Column{Name: cols[i], Type: cols[i+1]})
+		}
+		return t
+	}
+	tests := []struct {
+		name string
+		a, b *schema.Table
+		want float64
+	}{
+		{
+			name: "same columns",
+			a:    table("id", "INT", "name", "TEXT"),
+			b:    table("id", "INT", "name", "TEXT"),
+			want: 1,
+		},
+		{
+			name: "one added column",
+			a:    table("id", "INT", "name", "TEXT", "email", "TEXT"),
+			b:    table("id", "INT", "name", "TEXT", "email", "TEXT", "created_at", "TIMESTAMP"),
+			want: 0.75,
+		},
+		{
+			name: "type aliases match",
+			a:    table("id", "INT", "code", "VARCHAR(10)"),
+			b:    table("id", "INTEGER", "code", "CHARACTER VARYING(10)"),
+			want: 1,
+		},
+		{
+			name: "changed type does not count",
+			a:    table("id", "INT", "name", "TEXT", "age", "INT"),
+			b:    table("id", "INT", "name", "TEXT", "age", "TEXT"),
+			want: 2.0 / 3,
+		},
+		{
+			name: "only an id in common",
+			a:    table("id", "INT", "name", "TEXT"),
+			b:    table("id", "INT", "title", "TEXT"),
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tableRenameScore(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("tableRenameScore = %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnRenameScore(t *testing.T) {
+	tests := []struct {
+		name           string
+		source, target string
+		table          string
+		from, to       string
+		want           float64
+	}{
+		{
+			name:   "same place, nullability and default",
+			source: "CREATE TABLE users (id INT, name VARCHAR(100), email TEXT);",
+			target: "CREATE TABLE users (id INT, full_name VARCHAR(100), email TEXT);",
+			table:  "users", from: "name", to: "full_name",
+			want: 8.0/13 + 0.25 + 0.25 + 0.5,
+		},
+		{
+			name:   "moved elsewhere",
+			source: "CREATE TABLE users (id INT, name VARCHAR(100), email TEXT);",
+			target: "CREATE TABLE users (id INT, email TEXT, created_at TEXT, full_name VARCHAR(100));",
+			table:  "users", from: "name", to: "full_name",
+			want: 8.0/13 + 0.25 + 0.25,
+		},
+		{
+			name:   "changed nullability and default",
+			source: "CREATE TABLE users (id INT, name VARCHAR(100), email TEXT);",
+			target: "CREATE TABLE users (id INT, full_name VARCHAR(100) NOT NULL DEFAULT '', email TEXT);",
+			table:  "users", from: "name", to: "full_name",
+			want: 8.0/13 + 0.5,
+		},
+		{
+			name:   "different types",
+			source: "CREATE TABLE users (id INT, name VARCHAR(100), email TEXT);",
+			target: "CREATE TABLE users (id INT, full_name TEXT, email TEXT);",
+			table:  "users", from: "name", to: "full_name",
+			want: 0,
+		},
+		{
+			name: "same referenced key",
+			source: "CREATE TABLE users (id INT PRIMARY KEY);" +
+				"CREATE TABLE posts (id INT, author_id INT REFERENCES users(id));",
+			target: "CREATE TABLE users (id INT PRIMARY KEY);" +
+				"CREATE TABLE posts (id INT, writer_id INT REFERENCES users(id));",
+			table: "posts", from: "author_id", to: "writer_id",
+			want: 10.0/18 + 0.5 + 0.25 + 0.25 + 0.5,
+		},
+		{
+			name: "different referenced keys",
+			source: "CREATE TABLE users (id INT PRIMARY KEY); CREATE TABLE teams (id INT PRIMARY KEY);" +
+				"CREATE TABLE posts (id INT, author_id INT REFERENCES users(id));",
+			target: "CREATE TABLE users (id INT PRIMARY KEY); CREATE TABLE teams (id INT PRIMARY KEY);" +
+				"CREATE TABLE posts (id INT, team_id INT REFERENCES teams(id));",
+			table: "posts", from: "author_id", to: "team_id",
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := mustParse(t, tt.source, "postgres")
+			target := mustParse(t, tt.target, "postgres")
+			d := NewDifferWithOptions(source, target, Options{NoRenameDetection: true})
+
+			key := d.key("", tt.table)
+			sourceTable, targetTable := d.findTable(source, key), d.findTable(target, key)
+			i, j := columnIndex(t, sourceTable, tt.from), columnIndex(t, targetTable, tt.to)
+			if got := d.columnRenameScore(sourceTable, i, targetTable, j); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("columnRenameScore = %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickRenames(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []renameCandidate
+		want       []renameCandidate
+	}{
+		{
+			name: "best score wins a contested target",
+			candidates: []renameCandidate{
+				{source: 0, target: 0, score: 1.6},
+				{source: 1, target: 0, score: 2.0},
+				{source: 1, target: 1, score: 1.8},
+			},
+			want: []renameCandidate{
+				{source: 1, target: 0, score: 2.0},
+			},
+		},
+		{
+			name: "ties go to the pair listed first",
+			candidates: []renameCandidate{
+				{source: 0, target: 1, score: 1.5},
+				{source: 0, target: 0, score: 1.5},
+				{source: 1, target: 0, score: 1.5},
+			},
+			want: []renameCandidate{
+				{source: 0, target: 1, score: 1.5},
+				{source: 1, target: 0, score: 1.5},
+			},
+		},
+		{
+			name: "returned in source order",
+			candidates: []renameCandidate{
+				{source: 2, target: 0, score: 3},
+				{source: 0, target: 2, score: 2},
+				{source: 1, target: 1, score: 1.5},
+			},
+			want: []renameCandidate{
+				{source: 0, target: 2, score: 2},
+				{source: 1, target: 1, score: 1.5},
+				{source: 2, target: 0, score: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickRenames(tt.candidates); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pickRenames = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectRenames(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		target string
+		opts   Options
+		want   []schema.Rename
+	}{
+		{
+			name:   "renamed column",
+			source: "CREATE TABLE users (id INT, name VARCHAR(100), email TEXT);",
+			target: "CREATE TABLE users (id INT, full_name VARCHAR(100), email TEXT);",
+			want:   []schema.Rename{{Table: "users", Column: "name", NewName: "full_name"}},
+		},
+		{
+			name:   "moved column scores too low",
+			source: "CREATE TABLE users (id INT, name VARCHAR(100), email TEXT);",
+			target: "CREATE TABLE users (id INT, email TEXT, created_at TEXT, full_name VARCHAR(100) NOT NULL);",
+		},
+		{
+			name:   "renamed table",
+			source: "CREATE TABLE person (id INT, name TEXT, email TEXT);",
+			target: "CREATE TABLE people (id INT, name TEXT, email TEXT);",
+			want:   []schema.Rename{{Table: "person", NewName: "people"}},
+		},
+		{
+			name:   "renamed table and column",
+			source: "CREATE TABLE person (id INT, name TEXT, email TEXT, phone TEXT);",
+			target: "CREATE TABLE people (id INT, full_name TEXT, email TEXT, phone TEXT);",
+			want: []schema.Rename{
+				{Table: "person", NewName: "people"},
+				{Table: "people", Column: "name", NewName: "full_name"},
+			},
+		},
+		{
+			name:   "detection turned off",
+			source: "CREATE TABLE users (id INT, name VARCHAR(100), email TEXT);",
+			target: "CREATE TABLE users (id INT, full_name VARCHAR(100), email TEXT);",
+			opts:   Options{NoRenameDetection: true},
+		},
+		{
+			name:   "given rename",
+			source: "CREATE TABLE users (id INT, name VARCHAR(100), email TEXT);",
+			target: "CREATE TABLE users (id INT, login TEXT, email TEXT);",
+			opts:   Options{Renames: map[string]string{"users.name": "login"}},
+			want:   []schema.Rename{{Table: "users", Column: "name", NewName: "login"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDifferWithOptions(mustParse(t, tt.source, "postgres"), mustParse(t, tt.target, "postgres"), tt.opts)
+			if err := d.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if !reflect.DeepEqual(d.renames, tt.want) {
+				t.Errorf("renames = %+v, want %+v", d.renames, tt.want)
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, sql, dialect string) *schema.Schema {
+	t.Helper()
+	s, err := schema.Parse(sql, dialect)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return s
+}
+
+func columnIndex(t *testing.T, table *schema.Table, name string) int {
+	t.Helper()
+	for i := range table.Columns {
+		if table.Columns[i].Name == name {
+			return i
+		}
+	}
+	t.Fatalf("table %s has no column %s", table.Name, name)
+	return -1
+}
@@ -3,6 +3,7 @@ package diff
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/egoughnour/migrate/internal/schema"
@@ -25,36 +26,164 @@ func (g *SQLGenerator) WriteSQL(w io.Writer, c *Changes) error {
 	sb.WriteString("-- Migration SQL\n")
 	sb.WriteString(fmt.Sprintf("-- Dialect: %s\n\n", g.dialect))
 
+	// Create schemas before anything that lives in them
+	for _, name := range c.AddedSchemas {
+		sb.WriteString(g.generator().GenerateCreateSchema(name))
+		sb.WriteString("\n\n")
+	}
+
+	// Rename tables before anything refers to them by their new names
+	for _, r := range c.RenamedTables {
+		sb.WriteString(g.generateRenameTable(&r))
+		sb.WriteString("\n")
+	}
+	if len(c.RenamedTables) > 0 {
+		sb.WriteString("\n")
+	}
+
+	// Drop triggers and routines first, before the objects they use change
+	var drops []string
+	for _, t := range c.RemovedTriggers {
+		drops = append(drops, g.generator().GenerateDropTrigger(&t))
+	}
+	for _, tc := range c.ModifiedTriggers {
+		if g.dialect != "sqlserver" {
+			drops = append(drops, g.generator().GenerateDropTrigger(&tc.Old))
+		}
+	}
+	for _, r := range c.RemovedRoutines {
+		drops = append(drops, g.generator().GenerateDropRoutine(&r))
+	}
+	for _, rc := range c.ModifiedRoutines {
+		if g.routineNeedsDrop(&rc) {
+			drops = append(drops, g.generator().GenerateDropRoutine(&rc.Old))
+		}
+	}
+	if len(drops) > 0 {
+		sb.WriteString(strings.Join(drops, "\n"))
+		sb.WriteString("\n\n")
+	}
+
 	// Drop removed tables (at the end for FK dependencies)
 	var dropTables []string
 	for _, t := range c.RemovedTables {
 		dropTables = append(dropTables, g.generateDropTable(&t))
 	}
 
+	// Create new types before the tables that use them
+	for _, e := range c.AddedEnums {
+		if stmt := g.generator().GenerateEnum(&e); stmt != "" {
+			sb.WriteString(stmt)
+			sb.WriteString("\n\n")
+		}
+	}
+	for _, dom := range c.AddedDomains {
+		if stmt := g.generator().GenerateDomain(&dom); stmt != "" {
+			sb.WriteString(stmt)
+			sb.WriteString("\n\n")
+		}
+	}
+	for _, ec := range c.ModifiedEnums {
+		if stmt := g.generateAlterEnum(&ec); stmt != "" {
+			sb.WriteString(stmt)
+			sb.WriteString("\n")
+		}
+	}
+	for _, dc := range c.ModifiedDomains {
+		if stmt := g.generateAlterDomain(&dc); stmt != "" {
+			sb.WriteString(stmt)
+			sb.WriteString("\n")
+		}
+	}
+
+	// Create sequences before the column defaults that use them
+	for _, seq := range c.AddedSequences {
+		if stmt := g.generator().GenerateSequence(&seq); stmt != "" {
+			sb.WriteString(stmt)
+			sb.WriteString("\n\n")
+		}
+	}
+	for _, sc := range c.ModifiedSequences {
+		if stmt := g.generateAlterSequence(&sc); stmt != "" {
+			sb.WriteString(stmt)
+			sb.WriteString("\n")
+		}
+	}
+
 	// Create new tables
 	for _, t := range c.AddedTables {
 		sb.WriteString(g.generateCreateTable(&t))
 		sb.WriteString("\n\n")
 	}
 
+	// Drop the foreign keys on primary keys about to change
+	if g.dialect != "sqlite" {
+		for _, tc := range c.ModifiedTables {
+			for _, dep := range tc.DependentForeignKeys {
+				if dep.Old != nil {
+					stmt, err := g.generateDropForeignKey(dep.Schema, dep.Table, dep.Source, dep.Old, nil)
+					if err != nil {
+						return err
+					}
+					sb.WriteString(stmt)
+					sb.WriteString("\n")
+				}
+			}
+		}
+	}
+
+	// Drop removed and modified indexes before the columns they use are
+	// dropped or altered, which may drop the indexes along with them
+	for _, idx := range c.RemovedIndexes {
+		sb.WriteString(g.generateDropIndex(&idx))
+		sb.WriteString("\n")
+	}
+	for _, ic := range c.ModifiedIndexes {
+		sb.WriteString(g.generateDropIndex(&ic.Old))
+		sb.WriteString("\n")
+	}
+
 	// Alter existing tables
 	for _, tc := range c.ModifiedTables {
-		alterSQL := g.generateAlterTable(&tc)
+		alterSQL, err := g.generateAlterTable(&tc, c)
+		if err != nil {
+			return err
+		}
 		if alterSQL != "" {
 			sb.WriteString(alterSQL)
 			sb.WriteString("\n")
 		}
 	}
 
-	// Create new standalone indexes
-	for _, idx := range c.AddedIndexes {
-		sb.WriteString(g.generateCreateIndex(&idx))
+	// Add them back once the new primary keys exist
+	if g.dialect != "sqlite" {
+		for _, tc := range c.ModifiedTables {
+			for _, dep := range tc.DependentForeignKeys {
+				if dep.New != nil {
+					sb.WriteString(g.generateAddForeignKey(g.qualifiedName(dep.Schema, dep.Table), dep.New))
+					sb.WriteString("\n")
+				}
+			}
+		}
+	}
+
+	// Tie new sequences to their columns once the tables exist
+	for _, seq := range c.AddedSequences {
+		if stmt := g.generator().GenerateSequenceOwner(&seq); stmt != "" {
+			sb.WriteString(stmt)
+			sb.WriteString("\n")
+		}
+	}
+
+	// Re-create modified indexes
+	for _, ic := range c.ModifiedIndexes {
+		sb.WriteString(g.generateCreateIndex(&ic.New))
 		sb.WriteString("\n")
 	}
 
-	// Drop removed indexes
-	for _, idx := range c.RemovedIndexes {
-		sb.WriteString(g.generateDropIndex(&idx))
+	// Create new standalone indexes
+	for _, idx := range c.AddedIndexes {
+		sb.WriteString(g.generateCreateIndex(&idx))
 		sb.WriteString("\n")
 	}
 
@@ -66,32 +195,283 @@ func (g *SQLGenerator) WriteSQL(w io.Writer, c *Changes) error {
 
 	// Modify views (drop and recreate)
 	for _, vc := range c.ModifiedViews {
-		sb.WriteString(g.generateDropView(vc.Name))
+		sb.WriteString(g.generateDropView(vc.Schema, vc.Name))
 		sb.WriteString("\n")
-		sb.WriteString(fmt.Sprintf("CREATE VIEW %s AS\n%s;\n\n", g.quoteName(vc.Name), vc.NewDefinition))
+		sb.WriteString(fmt.Sprintf("CREATE VIEW %s AS\n%s;\n\n", g.qualifiedName(vc.Schema, vc.Name), vc.NewDefinition))
 	}
 
 	// Drop removed views
 	for _, v := range c.RemovedViews {
-		sb.WriteString(g.generateDropView(v.Name))
+		sb.WriteString(g.generateDropView(v.Schema, v.Name))
 		sb.WriteString("\n")
 	}
 
+	// Create routines and triggers once the objects they use exist
+	for _, r := range c.AddedRoutines {
+		sb.WriteString(g.generator().GenerateRoutine(&r, false))
+		sb.WriteString("\n\n")
+	}
+	for _, rc := range c.ModifiedRoutines {
+		sb.WriteString(g.generator().GenerateRoutine(&rc.New, !g.routineNeedsDrop(&rc)))
+		sb.WriteString("\n\n")
+	}
+	for _, t := range c.AddedTriggers {
+		sb.WriteString(g.generator().GenerateTrigger(&t, false))
+		sb.WriteString("\n\n")
+	}
+	for _, tc := range c.ModifiedTriggers {
+		sb.WriteString(g.generator().GenerateTrigger(&tc.New, true))
+		sb.WriteString("\n\n")
+	}
+
 	// Drop tables at the end
 	for _, sql := range dropTables {
 		sb.WriteString(sql)
 		sb.WriteString("\n")
 	}
 
+	// Drop sequences once no default draws from them
+	for _, seq := range c.RemovedSequences {
+		if g.dialect == "postgres" || g.dialect == "sqlserver" {
+			sb.WriteString(fmt.Sprintf("DROP SEQUENCE %s;\n", g.qualifiedName(seq.Schema, seq.Name)))
+		}
+	}
+
+	// Drop types once nothing uses them
+	for _, e := range c.RemovedEnums {
+		if g.dialect == "postgres" {
+			sb.WriteString(fmt.Sprintf("DROP TYPE %s;\n", g.qualifiedName(e.Schema, e.Name)))
+		}
+	}
+	for _, dom := range c.RemovedDomains {
+		switch g.dialect {
+		case "postgres":
+			sb.WriteString(fmt.Sprintf("DROP DOMAIN %s;\n", g.qualifiedName(dom.Schema, dom.Name)))
+		case "sqlserver":
+			sb.WriteString(fmt.Sprintf("DROP TYPE %s;\n", g.qualifiedName(dom.Schema, dom.Name)))
+		}
+	}
+
+	// Drop schemas once they are empty
+	for _, name := range c.RemovedSchemas {
+		sb.WriteString(g.generator().GenerateDropSchema(name))
+		sb.WriteString("\n")
+	}
+
 	_, err := w.Write([]byte(sb.String()))
 	return err
 }
 
+// routineNeedsDrop reports whether a changed routine must be dropped and
+// recreated rather than replaced in place. MySQL cannot replace routines,
+// and PostgreSQL cannot replace one with different parameters or result.
+func (g *SQLGenerator) routineNeedsDrop(rc *RoutineChanges) bool {
+	switch g.dialect {
+	case "mysql":
+		return true
+	case "postgres":
+		return normalizeTokens(rc.Old.Signature) != normalizeTokens(rc.New.Signature) ||
+			normalizeTokens(rc.Old.Returns) != normalizeTokens(rc.New.Returns)
+	default:
+		return false
+	}
+}
+
+func (g *SQLGenerator) generator() *schema.Generator {
+	return schema.NewGenerator(g.dialect)
+}
+
 func (g *SQLGenerator) generateCreateTable(t *schema.Table) string {
 	gen := schema.NewGenerator(g.dialect)
 	return gen.Generate(&schema.Schema{Tables: []schema.Table{*t}})
 }
 
+// generateAlterEnum adds new enum values in their target position. Values
+// cannot be removed from a PostgreSQL enum, so removals are reported as
+// warnings.
+func (g *SQLGenerator) generateAlterEnum(ec *EnumChanges) string {
+	if g.dialect != "postgres" {
+		return ""
+	}
+
+	var sb strings.Builder
+	typeName := g.qualifiedName(ec.Schema, ec.Name)
+
+	for i, v := range ec.NewValues {
+		if !containsString(ec.AddedValues, v) {
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TYPE %s ADD VALUE %s", typeName, schema.QuoteString(v))
+		// Position relative to the next existing value, or after the
+		// previous one
+		placed := false
+		for _, next := range ec.NewValues[i+1:] {
+			if !containsString(ec.AddedValues, next) {
+				stmt += " BEFORE " + schema.QuoteString(next)
+				placed = true
+				break
+			}
+		}
+		if !placed && i > 0 {
+			stmt += " AFTER " + schema.QuoteString(ec.NewValues[i-1])
+		}
+		sb.WriteString(stmt + ";\n")
+	}
+
+	for _, v := range ec.RemovedValues {
+		sb.WriteString(fmt.Sprintf("-- Warning: cannot remove value %s from enum %s; recreate the type to drop it\n",
+			schema.QuoteString(v), typeName))
+	}
+
+	return sb.String()
+}
+
+// generateAlterDomain applies default, NOT NULL and CHECK changes to a
+// PostgreSQL domain. Base type changes require recreating the domain.
+func (g *SQLGenerator) generateAlterDomain(dc *DomainChanges) string {
+	if g.dialect != "postgres" {
+		return fmt.Sprintf("-- Warning: type %s changed; drop and recreate it with its dependent columns\n",
+			g.qualifiedName(dc.New.Schema, dc.Name))
+	}
+
+	var sb strings.Builder
+	domainName := g.qualifiedName(dc.New.Schema, dc.Name)
+
+	if !strings.EqualFold(dc.Old.Type, dc.New.Type) {
+		sb.WriteString(fmt.Sprintf("-- Warning: domain %s base type changed from %s to %s; recreate the domain\n",
+			domainName, dc.Old.Type, dc.New.Type))
+	}
+	if !sameDefault(dc.Old.Default, dc.New.Default) {
+		if dc.New.Default != nil {
+			sb.WriteString(fmt.Sprintf("ALTER DOMAIN %s SET DEFAULT %s;\n", domainName, *dc.New.Default))
+		} else {
+			sb.WriteString(fmt.Sprintf("ALTER DOMAIN %s DROP DEFAULT;\n", domainName))
+		}
+	}
+	if dc.Old.NotNull != dc.New.NotNull {
+		if dc.New.NotNull {
+			sb.WriteString(fmt.Sprintf("ALTER DOMAIN %s SET NOT NULL;\n", domainName))
+		} else {
+			sb.WriteString(fmt.Sprintf("ALTER DOMAIN %s DROP NOT NULL;\n", domainName))
+		}
+	}
+
+	hasCheck := func(checks []schema.Constraint, c schema.Constraint) bool {
+		for _, x := range checks {
+			if normalizeExpr(x.Expression) == normalizeExpr(c.Expression) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, c := range dc.Old.Checks {
+		if hasCheck(dc.New.Checks, c) {
+			continue
+		}
+		if c.Name == "" {
+			sb.WriteString(fmt.Sprintf("-- Warning: cannot drop unnamed CHECK (%s) on domain %s\n", c.Expression, domainName))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("ALTER DOMAIN %s DROP CONSTRAINT %s;\n", domainName, g.quoteName(c.Name)))
+	}
+	for _, c := range dc.New.Checks {
+		if hasCheck(dc.Old.Checks, c) {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("ALTER DOMAIN %s ADD ", domainName))
+		if c.Name != "" {
+			sb.WriteString(fmt.Sprintf("CONSTRAINT %s ", g.quoteName(c.Name)))
+		}
+		sb.WriteString(fmt.Sprintf("CHECK (%s);\n", c.Expression))
+	}
+
+	return sb.String()
+}
+
+// generateAlterSequence changes the options of a sequence in place.
+func (g *SQLGenerator) generateAlterSequence(sc *SequenceChanges) string {
+	seqName := g.qualifiedName(sc.New.Schema, sc.Name)
+	if g.dialect == "mysql" || g.dialect == "sqlite" {
+		return ""
+	}
+
+	var sb strings.Builder
+	var clauses []string
+	if g.dialect == "postgres" && !strings.EqualFold(sequenceType(sc.Old.Type), sequenceType(sc.New.Type)) {
+		clauses = append(clauses, "AS "+sequenceType(sc.New.Type))
+	}
+	clauses = append(clauses, g.sequenceOptionChanges(sc.Old.SequenceOptions, sc.New.SequenceOptions)...)
+	if len(clauses) > 0 {
+		sb.WriteString(fmt.Sprintf("ALTER SEQUENCE %s %s;\n", seqName, strings.Join(clauses, " ")))
+	}
+
+	if !strings.EqualFold(sc.Old.OwnedBy, sc.New.OwnedBy) && g.dialect == "postgres" {
+		if stmt := g.generator().GenerateSequenceOwner(&sc.New); stmt != "" {
+			sb.WriteString(stmt + "\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("ALTER SEQUENCE %s OWNED BY NONE;\n", seqName))
+		}
+	}
+
+	return sb.String()
+}
+
+// sequenceOptionChanges returns the ALTER SEQUENCE clauses that turn the
+// old options into the new ones. Unset options are reset to their
+// defaults.
+func (g *SQLGenerator) sequenceOptionChanges(old, new schema.SequenceOptions) []string {
+	value := func(v *int64) int64 {
+		if v == nil {
+			return 1
+		}
+		return *v
+	}
+	same := func(x, y *int64) bool {
+		if x == nil || y == nil {
+			return x == nil && y == nil
+		}
+		return *x == *y
+	}
+
+	var clauses []string
+	if value(old.Increment) != value(new.Increment) {
+		clauses = append(clauses, fmt.Sprintf("INCREMENT BY %d", value(new.Increment)))
+	}
+	if !same(old.MinValue, new.MinValue) {
+		if new.MinValue != nil {
+			clauses = append(clauses, fmt.Sprintf("MINVALUE %d", *new.MinValue))
+		} else {
+			clauses = append(clauses, "NO MINVALUE")
+		}
+	}
+	if !same(old.MaxValue, new.MaxValue) {
+		if new.MaxValue != nil {
+			clauses = append(clauses, fmt.Sprintf("MAXVALUE %d", *new.MaxValue))
+		} else {
+			clauses = append(clauses, "NO MAXVALUE")
+		}
+	}
+	if value(old.Start) != value(new.Start) {
+		// SQL Server has no START WITH on ALTER SEQUENCE
+		if g.dialect == "sqlserver" {
+			clauses = append(clauses, fmt.Sprintf("RESTART WITH %d", value(new.Start)))
+		} else {
+			clauses = append(clauses, fmt.Sprintf("START WITH %d", value(new.Start)))
+		}
+	}
+	if value(old.Cache) != value(new.Cache) {
+		clauses = append(clauses, fmt.Sprintf("CACHE %d", value(new.Cache)))
+	}
+	if old.Cycle != new.Cycle {
+		if new.Cycle {
+			clauses = append(clauses, "CYCLE")
+		} else {
+			clauses = append(clauses, "NO CYCLE")
+		}
+	}
+	return clauses
+}
+
 func (g *SQLGenerator) generateDropTable(t *schema.Table) string {
 	tableName := g.quoteName(t.Name)
 	if t.Schema != "" {
@@ -100,13 +480,46 @@ func (g *SQLGenerator) generateDropTable(t *schema.Table) string {
 	return fmt.Sprintf("DROP TABLE %s;", tableName)
 }
 
-func (g *SQLGenerator) generateAlterTable(tc *TableChanges) string {
+func (g *SQLGenerator) generateAlterTable(tc *TableChanges, c *Changes) (string, error) {
 	var sb strings.Builder
-	tableName := g.quoteName(tc.Name)
+	tableName := g.qualifiedName(tc.Schema, tc.Name)
+
+	// Rename columns first, as the other changes use the new names
+	for _, r := range tc.RenamedColumns {
+		sb.WriteString(g.generateRenameColumn(tableName, &r))
+		sb.WriteString("\n")
+	}
+
+	if g.dialect == "sqlite" && needsRebuild(tc) {
+		return sb.String() + g.generateRebuildTable(tc, c), nil
+	}
 
-	// Drop removed foreign keys first (before dropping columns)
+	// Drop removed foreign keys first (before dropping columns), except
+	// those already dropped for a primary key change
+	dependent := dependentForeignKeys(c, tc)
+	var dropped []schema.ForeignKey
 	for _, fk := range tc.RemovedForeignKeys {
-		sb.WriteString(g.generateDropConstraint(tableName, fk.Name, "FOREIGN KEY"))
+		if !dependent[foreignKeyID(&fk)] {
+			dropped = append(dropped, fk)
+		}
+	}
+	for _, fc := range tc.ModifiedForeignKeys {
+		if !dependent[foreignKeyID(&fc.Old)] {
+			dropped = append(dropped, fc.Old)
+		}
+	}
+	for _, fk := range dropped {
+		stmt, err := g.generateDropForeignKey(tc.Schema, tc.Name, tc.Source, &fk, tc.RenamedColumns)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(stmt)
+		sb.WriteString("\n")
+	}
+
+	// Drop removed and changed constraints, before the columns they use
+	for _, con := range tc.RemovedConstraints {
+		sb.WriteString(g.generateDropConstraint(tableName, g.constraintName(tc, c, &con), strings.ToUpper(con.Type)))
 		sb.WriteString("\n")
 	}
 
@@ -115,10 +528,22 @@ func (g *SQLGenerator) generateAlterTable(tc *TableChanges) string {
 		sb.WriteString(g.generateDropIndex(&idx))
 		sb.WriteString("\n")
 	}
+	for _, ic := range tc.ModifiedIndexes {
+		sb.WriteString(g.generateDropIndex(&ic.Old))
+		sb.WriteString("\n")
+	}
+
+	// Drop the old primary key, before the columns it uses
+	if tc.PrimaryKeyChanged {
+		if drop := g.generateDropPrimaryKey(tableName, tc, c); drop != "" {
+			sb.WriteString(drop)
+			sb.WriteString("\n")
+		}
+	}
 
 	// Drop removed columns
 	for _, col := range tc.RemovedColumns {
-		sb.WriteString(g.generateDropColumn(tableName, col.Name))
+		sb.WriteString(g.generateDropColumn(tableName, &col))
 		sb.WriteString("\n")
 	}
 
@@ -126,36 +551,310 @@ func (g *SQLGenerator) generateAlterTable(tc *TableChanges) string {
 	for _, col := range tc.AddedColumns {
 		sb.WriteString(g.generateAddColumn(tableName, &col))
 		sb.WriteString("\n")
+		if col.Comment != "" && g.dialect != "mysql" && g.dialect != "sqlite" {
+			sb.WriteString(g.generator().GenerateComment(tc.Schema, tc.Name, col.Name, "", col.Comment))
+			sb.WriteString("\n")
+		}
+	}
+
+	// MySQL swaps the primary key first, so the old key's columns can be
+	// made nullable; the new key's columns become NOT NULL with it
+	if tc.PrimaryKeyChanged && g.dialect == "mysql" {
+		if add := g.generateAddPrimaryKey(tableName, tc); add != "" {
+			sb.WriteString(add)
+			sb.WriteString("\n")
+		}
 	}
 
 	// Modify columns
 	for _, col := range tc.ModifiedColumns {
 		sb.WriteString(g.generateAlterColumn(tableName, &col))
+		sb.WriteString(g.generateColumnComment(tc, &col))
+		sb.WriteString("\n")
+	}
+
+	// Table comment
+	if tc.CommentChanged && g.dialect != "sqlite" {
+		sb.WriteString(g.generator().GenerateComment(tc.Schema, tc.Name, "", tc.OldComment, tc.NewComment))
 		sb.WriteString("\n")
 	}
 
+	// Add the new primary key once its columns are NOT NULL
+	if tc.PrimaryKeyChanged && g.dialect != "mysql" {
+		if add := g.generateAddPrimaryKey(tableName, tc); add != "" {
+			sb.WriteString(add)
+			sb.WriteString("\n")
+		}
+	}
+
 	// Add new indexes
 	for _, idx := range tc.AddedIndexes {
 		sb.WriteString(g.generateCreateIndex(&idx))
 		sb.WriteString("\n")
 	}
+	for _, ic := range tc.ModifiedIndexes {
+		sb.WriteString(g.generateCreateIndex(&ic.New))
+		sb.WriteString("\n")
+	}
+
+	// Add new constraints
+	for _, con := range tc.AddedConstraints {
+		sb.WriteString(fmt.Sprintf("ALTER TABLE %s ADD %s;\n", tableName, g.generator().GenerateConstraint(&con)))
+	}
 
 	// Add new foreign keys
 	for _, fk := range tc.AddedForeignKeys {
-		sb.WriteString(g.generateAddForeignKey(tableName, &fk))
-		sb.WriteString("\n")
+		if !dependent[foreignKeyID(&fk)] {
+			sb.WriteString(g.generateAddForeignKey(tableName, &fk))
+			sb.WriteString("\n")
+		}
+	}
+	for _, fc := range tc.ModifiedForeignKeys {
+		if !dependent[foreignKeyID(&fc.New)] {
+			sb.WriteString(g.generateAddForeignKey(tableName, &fc.New))
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// dependentForeignKeys returns the foreign keys of a table, by
+// foreignKeyID, that are dropped and added around a primary key change
+// rather than with the table's own changes.
+func dependentForeignKeys(c *Changes, table *TableChanges) map[string]bool {
+	ids := make(map[string]bool)
+	for _, tc := range c.ModifiedTables {
+		for _, dep := range tc.DependentForeignKeys {
+			if !strings.EqualFold(dep.Schema, table.Schema) || !strings.EqualFold(dep.Table, table.Name) {
+				continue
+			}
+			if dep.Old != nil {
+				ids[foreignKeyID(dep.Old)] = true
+			}
+			if dep.New != nil {
+				ids[foreignKeyID(dep.New)] = true
+			}
+		}
 	}
+	return ids
+}
+
+// foreignKeyID identifies a foreign key within its table by its name, or
+// by its columns when it has none.
+func foreignKeyID(fk *schema.ForeignKey) string {
+	if fk.Name == "" {
+		return "(" + strings.ToLower(strings.Join(fk.Columns, ",")) + ")"
+	}
+	return strings.ToLower(fk.Name)
+}
 
+// generateDropPrimaryKey drops the old primary key of a table. An unnamed
+// key goes by the name PostgreSQL gives it, table_pkey after the table's
+// name when it was created, and SQL Server looks its generated name up.
+// MySQL drops and adds the key in one statement, in generateAddPrimaryKey.
+func (g *SQLGenerator) generateDropPrimaryKey(tableName string, tc *TableChanges, c *Changes) string {
+	pk := tc.OldPrimaryKey
+	if pk == nil {
+		return ""
+	}
+	switch g.dialect {
+	case "mysql":
+		return ""
+	case "sqlserver":
+		if pk.Name != "" {
+			return g.generateDropConstraint(tableName, pk.Name, "PRIMARY KEY")
+		}
+		return fmt.Sprintf("GO\nDECLARE @sql nvarchar(max) = N%s + QUOTENAME((SELECT name FROM sys.key_constraints WHERE type = 'PK' AND parent_object_id = OBJECT_ID(N%s)));\nEXEC sp_executesql @sql;\nGO",
+			schema.QuoteString("ALTER TABLE "+tableName+" DROP CONSTRAINT "), schema.QuoteString(tableName))
+	default:
+		name := pk.Name
+		if name == "" {
+			name = postgresDefaultName(oldTableName(tc, c), nil, "pkey")
+		}
+		return g.generateDropConstraint(tableName, name, "PRIMARY KEY")
+	}
+}
+
+// oldTableName returns the name of the table before it was renamed, which
+// the default names of its constraints keep.
+func oldTableName(tc *TableChanges, c *Changes) string {
+	for _, r := range c.RenamedTables {
+		if strings.EqualFold(r.Schema, tc.Schema) && strings.EqualFold(r.NewName, tc.Name) {
+			return r.OldName
+		}
+	}
+	return tc.Name
+}
+
+// generateAddPrimaryKey adds the new primary key of a table. MySQL drops
+// the old one in the same statement, so that an AUTO_INCREMENT column is
+// never left without a key, unless dropping its columns removed it.
+func (g *SQLGenerator) generateAddPrimaryKey(tableName string, tc *TableChanges) string {
+	var clauses []string
+	if g.dialect == "mysql" && tc.OldPrimaryKey != nil && !onlyRemovedColumns(tc.OldPrimaryKey.Columns, tc.RemovedColumns) {
+		clauses = append(clauses, "DROP PRIMARY KEY")
+	}
+	if tc.NewPrimaryKey != nil {
+		clauses = append(clauses, "ADD "+g.generator().GeneratePrimaryKey(tc.NewPrimaryKey))
+	}
+	if len(clauses) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s;", tableName, strings.Join(clauses, ", "))
+}
+
+// needsRebuild reports whether SQLite must rebuild a table to apply its
+// changes. ALTER TABLE in SQLite can only rename a table or column, add a
+// column that needs no table scan, and drop a column no key depends on.
+func needsRebuild(tc *TableChanges) bool {
+	if len(tc.ModifiedColumns) > 0 || len(tc.AddedForeignKeys) > 0 || len(tc.RemovedForeignKeys) > 0 ||
+		len(tc.ModifiedForeignKeys) > 0 || len(tc.AddedConstraints) > 0 || len(tc.RemovedConstraints) > 0 || tc.PrimaryKeyChanged {
+		return true
+	}
+	for _, col := range tc.AddedColumns {
+		if col.IsPrimaryKey || col.IsUnique || (col.Generated != nil && col.Generated.Stored) ||
+			(!col.Nullable && col.Default == nil && col.Generated == nil) ||
+			(col.Default != nil && !isConstantDefault(*col.Default)) {
+			return true
+		}
+	}
+	for _, col := range tc.RemovedColumns {
+		if col.IsPrimaryKey || col.IsUnique {
+			return true
+		}
+	}
+	return false
+}
+
+// isConstantDefault reports whether SQLite accepts a default on a column
+// added with ALTER TABLE, which rules out CURRENT_* values and expressions.
+func isConstantDefault(def string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(def))
+	return !strings.HasPrefix(upper, "(") && !strings.HasPrefix(upper, "CURRENT_")
+}
+
+// generateRebuildTable applies changes SQLite cannot make with ALTER TABLE
+// by creating the table anew, copying the rows over and putting the new
+// table in the old one's place, as described in "Making Other Kinds Of
+// Table Schema Changes" in the SQLite documentation. Dropping the old table
+// drops its indexes and triggers, so those of the target are recreated,
+// except for the ones created later in the migration. Legacy ALTER TABLE
+// behavior keeps the rename from checking the views that use the table.
+func (g *SQLGenerator) generateRebuildTable(tc *TableChanges, c *Changes) string {
+	var sb strings.Builder
+	tableName := g.qualifiedName(tc.Schema, tc.Name)
+	tempName := "_" + tc.Name + "_new"
+
+	added := make(map[string]bool)
+	for _, col := range tc.AddedColumns {
+		added[col.Name] = true
+	}
+	var copied []string
+	for _, col := range tc.Definition.Columns {
+		if !added[col.Name] && col.Generated == nil {
+			copied = append(copied, g.quoteName(col.Name))
+		}
+	}
+
+	newTable := *tc.Definition
+	newTable.Name = tempName
+	newTable.Indexes = nil
+	create := g.generateCreateTable(&newTable)
+
+	sb.WriteString(fmt.Sprintf("-- SQLite cannot alter %s in place; rebuild it\n", tableName))
+	sb.WriteString("PRAGMA foreign_keys = OFF;\n")
+	sb.WriteString("PRAGMA legacy_alter_table = ON;\n")
+	sb.WriteString(strings.TrimSpace(create) + "\n")
+	if len(copied) > 0 {
+		cols := strings.Join(copied, ", ")
+		sb.WriteString(fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;\n",
+			g.qualifiedName(tc.Schema, tempName), cols, cols, tableName))
+	}
+	sb.WriteString(fmt.Sprintf("DROP TABLE %s;\n", tableName))
+	sb.WriteString(fmt.Sprintf("ALTER TABLE %s RENAME TO %s;\n", g.qualifiedName(tc.Schema, tempName), g.quoteName(tc.Name)))
+
+	createdLater := make(map[string]bool)
+	for _, idx := range c.AddedIndexes {
+		createdLater["index "+idx.Name] = true
+	}
+	for _, ic := range c.ModifiedIndexes {
+		createdLater["index "+ic.New.Name] = true
+	}
+	for _, t := range c.AddedTriggers {
+		createdLater["trigger "+t.Name] = true
+	}
+	for _, t := range c.ModifiedTriggers {
+		createdLater["trigger "+t.Name] = true
+	}
+
+	for _, idx := range append(tc.Definition.Indexes, tc.StandaloneIndexes...) {
+		if !idx.IsPrimary && !createdLater["index "+idx.Name] {
+			sb.WriteString(g.generateCreateIndex(&idx))
+			sb.WriteString("\n")
+		}
+	}
+	for _, t := range tc.Triggers {
+		if !createdLater["trigger "+t.Name] {
+			sb.WriteString(g.generator().GenerateTrigger(&t, false))
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("PRAGMA legacy_alter_table = OFF;\n")
+	// Only the rebuilt table's keys: tables that refer to it may reach
+	// their new definitions later in the migration
+	sb.WriteString(fmt.Sprintf("PRAGMA foreign_key_check(%s);\n", g.quoteName(tc.Name)))
+	sb.WriteString("PRAGMA foreign_keys = ON;\n")
 	return sb.String()
 }
 
-func (g *SQLGenerator) generateDropColumn(tableName, colName string) string {
+// generateRenameTable renames a table within its schema.
+func (g *SQLGenerator) generateRenameTable(r *TableRename) string {
+	oldName := g.qualifiedName(r.Schema, r.OldName)
+	switch g.dialect {
+	case "mysql":
+		// An unqualified new name would move the table to the current database
+		return fmt.Sprintf("RENAME TABLE %s TO %s;", oldName, g.qualifiedName(r.Schema, r.NewName))
+	case "sqlserver":
+		return fmt.Sprintf("EXEC sp_rename %s, %s;", schema.QuoteString(oldName), schema.QuoteString(r.NewName))
+	default:
+		return fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", oldName, g.quoteName(r.NewName))
+	}
+}
+
+func (g *SQLGenerator) generateRenameColumn(tableName string, r *ColumnRename) string {
+	if g.dialect == "sqlserver" {
+		return fmt.Sprintf("EXEC sp_rename %s, %s, 'COLUMN';",
+			schema.QuoteString(tableName+"."+g.quoteName(r.OldName)), schema.QuoteString(r.NewName))
+	}
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", tableName, g.quoteName(r.OldName), g.quoteName(r.NewName))
+}
+
+func (g *SQLGenerator) generateDropColumn(tableName string, col *schema.Column) string {
 	switch g.dialect {
 	case "sqlserver":
-		return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", tableName, g.quoteName(colName))
+		// A column cannot be dropped while its default constraint exists
+		drop := fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", tableName, g.quoteName(col.Name))
+		if col.Default != nil {
+			drop = g.generateDropDefault(tableName, col.Name, col.DefaultName) + "\n" + drop
+		}
+		return drop
 	default:
-		return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", tableName, g.quoteName(colName))
+		return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", tableName, g.quoteName(col.Name))
+	}
+}
+
+// generateDropDefault drops the default constraint of a SQL Server
+// column. An unnamed default got a system name, which is looked up in
+// sys.default_constraints.
+func (g *SQLGenerator) generateDropDefault(tableName, colName, defaultName string) string {
+	if defaultName != "" {
+		return g.generateDropConstraint(tableName, defaultName, "DEFAULT")
 	}
+	return fmt.Sprintf("GO\nDECLARE @sql nvarchar(max) = N%s + QUOTENAME((SELECT dc.name FROM sys.default_constraints dc WHERE dc.parent_object_id = OBJECT_ID(N%s) AND dc.parent_column_id = COLUMNPROPERTY(OBJECT_ID(N%s), N%s, 'ColumnId')));\nEXEC sp_executesql @sql;\nGO",
+		schema.QuoteString("ALTER TABLE "+tableName+" DROP CONSTRAINT "), schema.QuoteString(tableName),
+		schema.QuoteString(tableName), schema.QuoteString(colName))
 }
 
 func (g *SQLGenerator) generateAddColumn(tableName string, col *schema.Column) string {
@@ -190,24 +889,50 @@ func (g *SQLGenerator) generateAlterColumn(tableName string, col *ColumnChanges)
 					tableName, g.quoteName(col.Name)))
 			}
 		}
+		if col.IdentityChanged {
+			sb.WriteString(g.generateAlterIdentity(tableName, col))
+		}
 
 	case "mysql":
-		// MySQL uses MODIFY COLUMN
+		// MySQL uses MODIFY COLUMN, restating the whole definition
 		var parts []string
 		parts = append(parts, g.quoteName(col.Name))
-		if col.NewType != "" {
-			parts = append(parts, col.NewType)
+		if def := col.Definition; def != nil {
+			parts = append(parts, def.Type)
+			if !def.Nullable {
+				parts = append(parts, "NOT NULL")
+			}
+			if def.Default != nil {
+				parts = append(parts, "DEFAULT", *def.Default)
+			}
+			if def.OnUpdate != "" {
+				parts = append(parts, "ON UPDATE", def.OnUpdate)
+			}
+			if def.IsIdentity {
+				parts = append(parts, "AUTO_INCREMENT")
+			}
+			if def.Comment != "" {
+				parts = append(parts, "COMMENT", schema.QuoteString(def.Comment))
+			}
 		} else {
-			parts = append(parts, col.OldType)
-		}
-		if !col.NewNullable {
-			parts = append(parts, "NOT NULL")
-		}
-		if col.NewDefault != nil {
-			parts = append(parts, "DEFAULT", *col.NewDefault)
+			if col.NewType != "" {
+				parts = append(parts, col.NewType)
+			} else {
+				parts = append(parts, col.OldType)
+			}
+			if !col.NewNullable {
+				parts = append(parts, "NOT NULL")
+			}
+			if col.NewDefault != nil {
+				parts = append(parts, "DEFAULT", *col.NewDefault)
+			}
 		}
 		sb.WriteString(fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;\n",
 			tableName, strings.Join(parts, " ")))
+		if col.IdentityChanged && col.NewIdentity != nil && !col.NewIdentity.IsDefault() {
+			sb.WriteString(fmt.Sprintf("-- Warning: MySQL AUTO_INCREMENT has no options; identity of %s.%s not changed\n",
+				tableName, g.quoteName(col.Name)))
+		}
 
 	case "sqlserver":
 		if col.NewType != "" || col.NullableChanged {
@@ -218,25 +943,82 @@ func (g *SQLGenerator) generateAlterColumn(tableName string, col *ColumnChanges)
 				nullable = " NULL"
 			}
 			colType := col.NewType
-			if colType == "" {
-				colType = col.OldType
+			if colType == "" && col.Definition != nil {
+				colType = col.Definition.Type
 			}
 			sb.WriteString(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s%s;\n",
 				tableName, g.quoteName(col.Name), colType, nullable))
 		}
 		if col.DefaultChanged {
-			// SQL Server requires dropping and adding default constraint
-			sb.WriteString("-- Note: May need to drop existing default constraint first\n")
+			// Defaults are constraints, dropped before adding the new one
+			if col.OldDefault != nil {
+				sb.WriteString(g.generateDropDefault(tableName, col.Name, col.OldDefaultName))
+				sb.WriteString("\n")
+			}
 			if col.NewDefault != nil {
-				sb.WriteString(fmt.Sprintf("ALTER TABLE %s ADD DEFAULT %s FOR %s;\n",
-					tableName, *col.NewDefault, g.quoteName(col.Name)))
+				constraint := ""
+				if col.NewDefaultName != "" {
+					constraint = "CONSTRAINT " + g.quoteName(col.NewDefaultName) + " "
+				}
+				sb.WriteString(fmt.Sprintf("ALTER TABLE %s ADD %sDEFAULT %s FOR %s;\n",
+					tableName, constraint, *col.NewDefault, g.quoteName(col.Name)))
 			}
 		}
+		if col.IdentityChanged {
+			sb.WriteString(fmt.Sprintf("-- Warning: SQL Server cannot alter the identity of %s.%s; rebuild the column\n",
+				tableName, g.quoteName(col.Name)))
+		}
 	}
 
 	return sb.String()
 }
 
+// generateColumnComment changes a column comment in the dialects that set
+// comments with separate statements; MySQL restates it with the column.
+func (g *SQLGenerator) generateColumnComment(tc *TableChanges, col *ColumnChanges) string {
+	if !col.CommentChanged || g.dialect == "mysql" {
+		return ""
+	}
+	return g.generator().GenerateComment(tc.Schema, tc.Name, col.Name, col.OldComment, col.NewComment) + "\n"
+}
+
+// generateAlterIdentity adds, drops or alters a PostgreSQL identity column.
+func (g *SQLGenerator) generateAlterIdentity(tableName string, col *ColumnChanges) string {
+	colName := g.quoteName(col.Name)
+	oldIdentity, newIdentity := col.OldIdentity, col.NewIdentity
+
+	switch {
+	case newIdentity == nil || newIdentity.Generation == "":
+		if oldIdentity == nil || oldIdentity.Generation == "" {
+			return ""
+		}
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP IDENTITY;\n", tableName, colName)
+
+	case oldIdentity == nil || oldIdentity.Generation == "":
+		var sb strings.Builder
+		if strings.Contains(strings.ToUpper(col.OldType), "SERIAL") {
+			// The serial's sequence default has to go first
+			sb.WriteString(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;\n", tableName, colName))
+		}
+		sb.WriteString(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s ADD %s;\n",
+			tableName, colName, g.generator().GenerateIdentity(newIdentity)))
+		return sb.String()
+
+	default:
+		var clauses []string
+		if !strings.EqualFold(oldIdentity.Generation, newIdentity.Generation) {
+			clauses = append(clauses, "SET GENERATED "+newIdentity.Generation)
+		}
+		for _, c := range g.sequenceOptionChanges(oldIdentity.SequenceOptions, newIdentity.SequenceOptions) {
+			clauses = append(clauses, "SET "+c)
+		}
+		if len(clauses) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s;\n", tableName, colName, strings.Join(clauses, " "))
+	}
+}
+
 func (g *SQLGenerator) generateDropConstraint(tableName, constraintName, constraintType string) string {
 	if constraintName == "" {
 		return fmt.Sprintf("-- Warning: Cannot drop unnamed %s constraint on %s\n", constraintType, tableName)
@@ -244,8 +1026,13 @@ func (g *SQLGenerator) generateDropConstraint(tableName, constraintName, constra
 
 	switch g.dialect {
 	case "mysql":
-		if constraintType == "FOREIGN KEY" {
+		switch constraintType {
+		case "FOREIGN KEY":
 			return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s;", tableName, g.quoteName(constraintName))
+		case "UNIQUE":
+			return fmt.Sprintf("ALTER TABLE %s DROP INDEX %s;", tableName, g.quoteName(constraintName))
+		case "CHECK":
+			return fmt.Sprintf("ALTER TABLE %s DROP CHECK %s;", tableName, g.quoteName(constraintName))
 		}
 		return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", tableName, g.quoteName(constraintName))
 	default:
@@ -253,6 +1040,209 @@ func (g *SQLGenerator) generateDropConstraint(tableName, constraintName, constra
 	}
 }
 
+// generateDropForeignKey drops a foreign key of source, the table before
+// the change, that is now named table. An unnamed key is dropped by the
+// name the database gave it: PostgreSQL's table_columns_fkey after the
+// table's name when it was created, and MySQL's table_ibfk_n numbered in
+// order. SQL Server looks its generated name up by the key's columns,
+// renamed as in renames if the table's columns were renamed already. It
+// fails when the name cannot be worked out, rather than leave the key in
+// place and add it again.
+func (g *SQLGenerator) generateDropForeignKey(schemaName, table string, source *schema.Table, fk *schema.ForeignKey, renames []ColumnRename) (string, error) {
+	tableName := g.qualifiedName(schemaName, table)
+	if fk.Name != "" {
+		return g.generateDropConstraint(tableName, fk.Name, "FOREIGN KEY"), nil
+	}
+
+	name := ""
+	switch {
+	case source == nil:
+	case g.dialect == "postgres":
+		name = postgresForeignKeyName(source, fk)
+	case g.dialect == "mysql":
+		name = mysqlForeignKeyName(table, source, fk)
+	case g.dialect == "sqlserver":
+		var columns []string
+		for _, col := range fk.Columns {
+			for _, r := range renames {
+				if strings.EqualFold(r.OldName, col) {
+					col = r.NewName
+				}
+			}
+			columns = append(columns, col)
+		}
+		// The key's columns, in order, identify it among the table's keys
+		lookup := fmt.Sprintf("SELECT fk.name FROM sys.foreign_keys fk WHERE fk.parent_object_id = OBJECT_ID(N%s) "+
+			"AND (SELECT STRING_AGG(COL_NAME(fc.parent_object_id, fc.parent_column_id), ',') WITHIN GROUP (ORDER BY fc.constraint_column_id) "+
+			"FROM sys.foreign_key_columns fc WHERE fc.constraint_object_id = fk.object_id) = N%s",
+			schema.QuoteString(tableName), schema.QuoteString(strings.Join(columns, ",")))
+		return fmt.Sprintf("GO\nDECLARE @sql nvarchar(max) = N%s + QUOTENAME((%s));\nEXEC sp_executesql @sql;\nGO",
+			schema.QuoteString("ALTER TABLE "+tableName+" DROP CONSTRAINT "), lookup), nil
+	}
+	if name == "" {
+		return "", fmt.Errorf("cannot drop unnamed foreign key (%s) on %s: its name is not known",
+			strings.Join(fk.Columns, ", "), tableName)
+	}
+	return g.generateDropConstraint(tableName, name, "FOREIGN KEY"), nil
+}
+
+// postgresForeignKeyName returns the name PostgreSQL gave an unnamed
+// foreign key of source, with a number appended when an earlier key took
+// the name.
+func postgresForeignKeyName(source *schema.Table, fk *schema.ForeignKey) string {
+	var used []string
+	for _, other := range source.ForeignKeys {
+		if other.Name != "" {
+			used = append(used, other.Name)
+		}
+	}
+	for _, other := range source.ForeignKeys {
+		if other.Name != "" {
+			continue
+		}
+		name := postgresDefaultName(source.Name, other.Columns, "fkey")
+		for n := 1; containsFold(used, name); n++ {
+			name = postgresDefaultName(source.Name, other.Columns, fmt.Sprintf("fkey%d", n))
+		}
+		if foreignKeyID(&other) == foreignKeyID(fk) {
+			return name
+		}
+		used = append(used, name)
+	}
+	return ""
+}
+
+// mysqlForeignKeyName returns the name MySQL gave an unnamed foreign key
+// of source, now named table: table_ibfk_n, numbered after the keys before
+// it, named or not.
+func mysqlForeignKeyName(table string, source *schema.Table, fk *schema.ForeignKey) string {
+	prefix := strings.ToLower(source.Name) + "_ibfk_"
+	n := 0
+	for _, other := range source.ForeignKeys {
+		if other.Name != "" {
+			if k, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(other.Name), prefix)); err == nil &&
+				strings.HasPrefix(strings.ToLower(other.Name), prefix) && k > n {
+				n = k
+			}
+			continue
+		}
+		n++
+		if foreignKeyID(&other) == foreignKeyID(fk) {
+			return fmt.Sprintf("%s_ibfk_%d", table, n)
+		}
+	}
+	return ""
+}
+
+// constraintName returns the name of a constraint of the table before the
+// change, or the name the database gave an unnamed one where that is
+// predictable: PostgreSQL's table_columns_key and table_column_check, and
+// MySQL's index named after the first column of a UNIQUE constraint.
+func (g *SQLGenerator) constraintName(tc *TableChanges, c *Changes, con *schema.Constraint) string {
+	if con.Name != "" {
+		return con.Name
+	}
+	unique := strings.EqualFold(con.Type, "UNIQUE")
+	switch g.dialect {
+	case "postgres":
+		if unique {
+			return postgresDefaultName(oldTableName(tc, c), con.Columns, "key")
+		}
+		return g.postgresCheckName(oldTableName(tc, c), tc.Source, con)
+	case "mysql":
+		if unique && len(con.Columns) > 0 {
+			return con.Columns[0]
+		}
+	}
+	return ""
+}
+
+// postgresCheckName returns the name PostgreSQL gave an unnamed check of
+// source: the table and the column its expression uses, if it uses only
+// one, with a number appended when an earlier constraint took the name.
+func (g *SQLGenerator) postgresCheckName(table string, source *schema.Table, con *schema.Constraint) string {
+	if source == nil {
+		source = &schema.Table{Constraints: []schema.Constraint{*con}}
+		for _, name := range con.Columns {
+			source.Columns = append(source.Columns, schema.Column{Name: name})
+		}
+	}
+	var columns, used []string
+	for _, col := range source.Columns {
+		columns = append(columns, col.Name)
+	}
+	for _, other := range source.Constraints {
+		if other.Name != "" {
+			used = append(used, other.Name)
+		}
+	}
+
+	for _, other := range source.Constraints {
+		if other.Name != "" || !strings.EqualFold(other.Type, "CHECK") {
+			continue
+		}
+		var cols []string
+		if refs := g.expressionColumns(other.Expression, columns); len(refs) == 1 {
+			cols = refs
+		}
+		name := postgresDefaultName(table, cols, "check")
+		for n := 1; containsFold(used, name); n++ {
+			name = postgresDefaultName(table, cols, fmt.Sprintf("check%d", n))
+		}
+		if sameConstraint(&other, con) {
+			return name
+		}
+		used = append(used, name)
+	}
+	return ""
+}
+
+// expressionColumns returns the distinct columns among columns that expr
+// refers to, skipping function names and the types of casts.
+func (g *SQLGenerator) expressionColumns(expr string, columns []string) []string {
+	tokens, _ := schema.NewLexer(expr, g.dialect).Tokenize()
+	var used []string
+	for i, tok := range tokens {
+		if tok.Kind != schema.TokenIdent && tok.Kind != schema.TokenQuotedIdent {
+			continue
+		}
+		if i+1 < len(tokens) && tokens[i+1].IsPunct("(") || i > 0 && tokens[i-1].IsPunct("::") {
+			continue
+		}
+		for _, col := range columns {
+			if strings.EqualFold(col, tok.Value) && !containsFold(used, col) {
+				used = append(used, col)
+			}
+		}
+	}
+	return used
+}
+
+// postgresDefaultName returns the name PostgreSQL gives an unnamed
+// constraint: the table, columns and label joined by underscores, with the
+// table and column parts shortened to fit in 63 bytes.
+func postgresDefaultName(table string, columns []string, label string) string {
+	const maxLength = 63
+	cols := strings.Join(columns, "_")
+	available := maxLength - len(label) - 1
+	if cols != "" {
+		available--
+	}
+	tableLen, colsLen := len(table), len(cols)
+	for tableLen+colsLen > available {
+		if tableLen > colsLen {
+			tableLen--
+		} else {
+			colsLen--
+		}
+	}
+	parts := []string{table[:tableLen]}
+	if cols != "" {
+		parts = append(parts, cols[:colsLen])
+	}
+	return strings.Join(append(parts, label), "_")
+}
+
 func (g *SQLGenerator) generateAddForeignKey(tableName string, fk *schema.ForeignKey) string {
 	localCols := make([]string, len(fk.Columns))
 	for i, c := range fk.Columns {
@@ -279,35 +1269,29 @@ func (g *SQLGenerator) generateAddForeignKey(tableName string, fk *schema.Foreig
 	sb.WriteString(fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
 		strings.Join(localCols, ", "), refTable, strings.Join(refCols, ", ")))
 
+	if fk.Match != "" && g.dialect == "postgres" {
+		sb.WriteString(" MATCH " + fk.Match)
+	}
 	if fk.OnDelete != "" {
 		sb.WriteString(" ON DELETE " + fk.OnDelete)
 	}
 	if fk.OnUpdate != "" {
 		sb.WriteString(" ON UPDATE " + fk.OnUpdate)
 	}
+	if fk.Deferrable && g.dialect == "postgres" {
+		sb.WriteString(" DEFERRABLE")
+		if fk.InitiallyDeferred {
+			sb.WriteString(" INITIALLY DEFERRED")
+		}
+	}
 
 	sb.WriteString(";")
 	return sb.String()
 }
 
 func (g *SQLGenerator) generateCreateIndex(idx *schema.Index) string {
-	cols := make([]string, len(idx.Columns))
-	for i, c := range idx.Columns {
-		cols[i] = g.quoteName(c)
-	}
-
-	tableName := g.quoteName(idx.Table)
-	if idx.Schema != "" {
-		tableName = g.quoteName(idx.Schema) + "." + tableName
-	}
-
-	unique := ""
-	if idx.IsUnique {
-		unique = "UNIQUE "
-	}
-
-	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s);",
-		unique, g.quoteName(idx.Name), tableName, strings.Join(cols, ", "))
+	gen := schema.NewGenerator(g.dialect)
+	return gen.GenerateIndex(idx)
 }
 
 func (g *SQLGenerator) generateDropIndex(idx *schema.Index) string {
@@ -324,8 +1308,8 @@ func (g *SQLGenerator) generateDropIndex(idx *schema.Index) string {
 			tableName = g.quoteName(idx.Schema) + "." + tableName
 		}
 		return fmt.Sprintf("DROP INDEX %s ON %s;", g.quoteName(idx.Name), tableName)
-	default: // postgres
-		return fmt.Sprintf("DROP INDEX %s;", g.quoteName(idx.Name))
+	default: // postgres, sqlite
+		return fmt.Sprintf("DROP INDEX %s;", g.qualifiedName(idx.Schema, idx.Name))
 	}
 }
 
@@ -337,25 +1321,53 @@ func (g *SQLGenerator) generateCreateView(v *schema.View) string {
 	return fmt.Sprintf("CREATE VIEW %s AS\n%s;", viewName, v.Definition)
 }
 
-func (g *SQLGenerator) generateDropView(name string) string {
-	return fmt.Sprintf("DROP VIEW IF EXISTS %s;", g.quoteName(name))
+func (g *SQLGenerator) generateDropView(schemaName, name string) string {
+	return fmt.Sprintf("DROP VIEW IF EXISTS %s;", g.qualifiedName(schemaName, name))
 }
 
 func (g *SQLGenerator) generateColumnDef(col *schema.Column) string {
 	var parts []string
-	parts = append(parts, g.quoteName(col.Name), col.Type)
+	parts = append(parts, g.quoteName(col.Name))
+	if col.Type != "" {
+		parts = append(parts, col.Type)
+	}
+	if col.Generated != nil {
+		parts = append(parts, g.generator().GenerateComputed(col.Generated))
+	}
 
 	if !col.Nullable {
 		parts = append(parts, "NOT NULL")
 	}
 
 	if col.Default != nil {
+		if g.dialect == "sqlserver" && col.DefaultName != "" {
+			parts = append(parts, "CONSTRAINT", g.quoteName(col.DefaultName))
+		}
 		parts = append(parts, "DEFAULT", *col.Default)
 	}
 
+	if col.OnUpdate != "" && g.dialect == "mysql" {
+		parts = append(parts, "ON UPDATE", col.OnUpdate)
+	}
+
+	if col.Identity != nil && (g.dialect != "postgres" || col.Identity.Generation != "") {
+		parts = append(parts, g.generator().GenerateIdentity(col.Identity))
+	}
+
+	if col.Comment != "" && g.dialect == "mysql" {
+		parts = append(parts, "COMMENT", schema.QuoteString(col.Comment))
+	}
+
 	return strings.Join(parts, " ")
 }
 
+func (g *SQLGenerator) qualifiedName(schemaName, name string) string {
+	if schemaName != "" {
+		return g.quoteName(schemaName) + "." + g.quoteName(name)
+	}
+	return g.quoteName(name)
+}
+
 func (g *SQLGenerator) quoteName(name string) string {
 	switch g.dialect {
 	case "mysql":
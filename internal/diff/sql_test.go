@@ -0,0 +1,312 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/egoughnour/migrate/internal/schema"
+)
+
+func TestPostgresDefaultName(t *testing.T) {
+	long := strings.Repeat("t", 70)
+	tests := []struct {
+		table   string
+		columns []string
+		label   string
+		want    string
+	}{
+		{"users", nil, "pkey", "users_pkey"},
+		{"posts", []string{"user_id"}, "fkey", "posts_user_id_fkey"},
+		{"posts", []string{"user_id", "tenant_id"}, "fkey", "posts_user_id_tenant_id_fkey"},
+		{"users", []string{"email"}, "key", "users_email_key"},
+		{long, nil, "pkey", strings.Repeat("t", 58) + "_pkey"},
+		{long, []string{"user_id"}, "fkey", strings.Repeat("t", 50) + "_user_id_fkey"},
+		{long, []string{strings.Repeat("c", 40)}, "fkey", strings.Repeat("t", 29) + "_" + strings.Repeat("c", 28) + "_fkey"},
+	}
+	for _, tt := range tests {
+		got := postgresDefaultName(tt.table, tt.columns, tt.label)
+		if got != tt.want {
+			t.Errorf("postgresDefaultName(%q, %q, %q) = %q, want %q", tt.table, tt.columns, tt.label, got, tt.want)
+		}
+		if len(got) > 63 {
+			t.Errorf("postgresDefaultName(%q, %q, %q) is %d bytes long", tt.table, tt.columns, tt.label, len(got))
+		}
+	}
+}
+
+func TestGenerateDropForeignKey(t *testing.T) {
+	fk := func(name string, cols ...string) schema.ForeignKey {
+		return schema.ForeignKey{Name: name, Columns: cols, ReferencedTable: "users", ReferencedCols: []string{"id"}}
+	}
+	posts := &schema.Table{Name: "posts", ForeignKeys: []schema.ForeignKey{
+		fk("", "user_id"),
+		fk("", "editor_id"),
+	}}
+
+	tests := []struct {
+		name    string
+		dialect string
+		table   string
+		source  *schema.Table
+		fk      schema.ForeignKey
+		renames []ColumnRename
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "named key",
+			dialect: "postgres",
+			table:   "posts",
+			source:  posts,
+			fk:      fk("posts_author_fk", "user_id"),
+			want:    `ALTER TABLE "posts" DROP CONSTRAINT "posts_author_fk";`,
+		},
+		{
+			name:    "postgres default name",
+			dialect: "postgres",
+			table:   "posts",
+			source:  posts,
+			fk:      fk("", "editor_id"),
+			want:    `ALTER TABLE "posts" DROP CONSTRAINT "posts_editor_id_fkey";`,
+		},
+		{
+			name:    "postgres name taken by an earlier key",
+			dialect: "postgres",
+			table:   "posts",
+			source: &schema.Table{Name: "posts", ForeignKeys: []schema.ForeignKey{
+				fk("posts_user_id_fkey", "author_id"),
+				fk("", "user_id"),
+			}},
+			fk:   fk("", "user_id"),
+			want: `ALTER TABLE "posts" DROP CONSTRAINT "posts_user_id_fkey1";`,
+		},
+		{
+			name:    "postgres renamed table keeps the old name",
+			dialect: "postgres",
+			table:   "articles",
+			source:  posts,
+			fk:      fk("", "user_id"),
+			want:    `ALTER TABLE "articles" DROP CONSTRAINT "posts_user_id_fkey";`,
+		},
+		{
+			name:    "mysql numbered in order",
+			dialect: "mysql",
+			table:   "posts",
+			source:  posts,
+			fk:      fk("", "editor_id"),
+			want:    "ALTER TABLE `posts` DROP FOREIGN KEY `posts_ibfk_2`;",
+		},
+		{
+			name:    "mysql numbered after a named ibfk key",
+			dialect: "mysql",
+			table:   "posts",
+			source: &schema.Table{Name: "posts", ForeignKeys: []schema.ForeignKey{
+				fk("posts_ibfk_3", "author_id"),
+				fk("", "user_id"),
+			}},
+			fk:   fk("", "user_id"),
+			want: "ALTER TABLE `posts` DROP FOREIGN KEY `posts_ibfk_4`;",
+		},
+		{
+			name:    "mysql renamed table takes the new name",
+			dialect: "mysql",
+			table:   "articles",
+			source:  posts,
+			fk:      fk("", "user_id"),
+			want:    "ALTER TABLE `articles` DROP FOREIGN KEY `articles_ibfk_1`;",
+		},
+		{
+			name:    "sqlserver looks the name up by renamed columns",
+			dialect: "sqlserver",
+			table:   "posts",
+			source:  posts,
+			fk:      fk("", "user_id"),
+			renames: []ColumnRename{{OldName: "user_id", NewName: "author_id"}},
+			want: "GO\nDECLARE @sql nvarchar(max) = N'ALTER TABLE [posts] DROP CONSTRAINT ' + QUOTENAME((" +
+				"SELECT fk.name FROM sys.foreign_keys fk WHERE fk.parent_object_id = OBJECT_ID(N'[posts]') " +
+				"AND (SELECT STRING_AGG(COL_NAME(fc.parent_object_id, fc.parent_column_id), ',') WITHIN GROUP (ORDER BY fc.constraint_column_id) " +
+				"FROM sys.foreign_key_columns fc WHERE fc.constraint_object_id = fk.object_id) = N'author_id'));\n" +
+				"EXEC sp_executesql @sql;\nGO",
+		},
+		{
+			name:    "key missing from the source table",
+			dialect: "postgres",
+			table:   "posts",
+			source:  posts,
+			fk:      fk("", "reviewer_id"),
+			wantErr: `cannot drop unnamed foreign key (reviewer_id) on "posts": its name is not known`,
+		},
+		{
+			name:    "no source table",
+			dialect: "mysql",
+			table:   "posts",
+			fk:      fk("", "user_id"),
+			wantErr: "cannot drop unnamed foreign key (user_id) on `posts`: its name is not known",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewSQLGenerator(tt.dialect)
+			got, err := g.generateDropForeignKey("", tt.table, tt.source, &tt.fk, tt.renames)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("generateDropForeignKey error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("generateDropForeignKey: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("generateDropForeignKey =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyMigrationSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		source  string
+		target  string
+		want    []string
+	}{
+		{
+			name:    "postgres changed unnamed foreign key",
+			dialect: "postgres",
+			source: "CREATE TABLE users (id INT PRIMARY KEY);" +
+				"CREATE TABLE posts (id INT PRIMARY KEY, user_id INT REFERENCES users(id) ON DELETE CASCADE);",
+			target: "CREATE TABLE users (id INT PRIMARY KEY);" +
+				"CREATE TABLE posts (id INT PRIMARY KEY, user_id INT REFERENCES users(id));",
+			want: []string{
+				`ALTER TABLE "posts" DROP CONSTRAINT "posts_user_id_fkey";`,
+				`ALTER TABLE "posts" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");`,
+			},
+		},
+		{
+			name:    "mysql changed unnamed foreign key",
+			dialect: "mysql",
+			source: "CREATE TABLE users (id INT PRIMARY KEY);" +
+				"CREATE TABLE posts (id INT PRIMARY KEY, user_id INT, FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE);",
+			target: "CREATE TABLE users (id INT PRIMARY KEY);" +
+				"CREATE TABLE posts (id INT PRIMARY KEY, user_id INT, FOREIGN KEY (user_id) REFERENCES users(id));",
+			want: []string{
+				"ALTER TABLE `posts` DROP FOREIGN KEY `posts_ibfk_1`;",
+				"ALTER TABLE `posts` ADD FOREIGN KEY (`user_id`) REFERENCES `users` (`id`);",
+			},
+		},
+		{
+			name:    "postgres dependent unnamed foreign key",
+			dialect: "postgres",
+			source: "CREATE TABLE users (id INT PRIMARY KEY, email TEXT NOT NULL);" +
+				"CREATE TABLE posts (id INT PRIMARY KEY, user_id INT REFERENCES users(id));",
+			target: "CREATE TABLE users (id INT, email TEXT NOT NULL, PRIMARY KEY (id, email));" +
+				"CREATE TABLE posts (id INT PRIMARY KEY, user_id INT, user_email TEXT, FOREIGN KEY (user_id, user_email) REFERENCES users(id, email));",
+			want: []string{
+				`ALTER TABLE "posts" DROP CONSTRAINT "posts_user_id_fkey";`,
+				`ALTER TABLE "users" DROP CONSTRAINT "users_pkey";`,
+			},
+		},
+		{
+			name:    "mysql dependent unnamed foreign key",
+			dialect: "mysql",
+			source: "CREATE TABLE users (id INT PRIMARY KEY, email VARCHAR(50) NOT NULL);" +
+				"CREATE TABLE posts (id INT PRIMARY KEY, user_id INT, FOREIGN KEY (user_id) REFERENCES users(id));",
+			target: "CREATE TABLE users (id INT, email VARCHAR(50) NOT NULL, PRIMARY KEY (id, email));" +
+				"CREATE TABLE posts (id INT PRIMARY KEY, user_id INT, user_email VARCHAR(50), FOREIGN KEY (user_id, user_email) REFERENCES users(id, email));",
+			want: []string{
+				"ALTER TABLE `posts` DROP FOREIGN KEY `posts_ibfk_1`;",
+			},
+		},
+		{
+			name:    "postgres unnamed primary key",
+			dialect: "postgres",
+			source:  "CREATE TABLE users (id INT, email TEXT, PRIMARY KEY (id));",
+			target:  "CREATE TABLE users (id INT, email TEXT, PRIMARY KEY (id, email));",
+			want: []string{
+				`ALTER TABLE "users" DROP CONSTRAINT "users_pkey";`,
+				`ALTER TABLE "users" ADD PRIMARY KEY ("id", "email");`,
+			},
+		},
+		{
+			name:    "postgres primary key of a renamed table",
+			dialect: "postgres",
+			source:  "CREATE TABLE person (id INT, name TEXT, email TEXT, PRIMARY KEY (id));",
+			target:  "CREATE TABLE people (id INT, name TEXT, email TEXT, PRIMARY KEY (id, email));",
+			want: []string{
+				`ALTER TABLE "person" RENAME TO "people";`,
+				`ALTER TABLE "people" DROP CONSTRAINT "person_pkey";`,
+			},
+		},
+		{
+			name:    "sqlserver unnamed primary key",
+			dialect: "sqlserver",
+			source:  "CREATE TABLE users (id INT NOT NULL, email NVARCHAR(100) NOT NULL, PRIMARY KEY (id));",
+			target:  "CREATE TABLE users (id INT NOT NULL, email NVARCHAR(100) NOT NULL, PRIMARY KEY (id, email));",
+			want: []string{
+				"DECLARE @sql nvarchar(max) = N'ALTER TABLE [users] DROP CONSTRAINT ' + QUOTENAME((SELECT name FROM sys.key_constraints WHERE type = 'PK' AND parent_object_id = OBJECT_ID(N'[users]')));",
+			},
+		},
+		{
+			name:    "postgres unnamed check",
+			dialect: "postgres",
+			source:  "CREATE TABLE products (id INT, price INT CHECK (price > 0), CHECK (price < 1000));",
+			target:  "CREATE TABLE products (id INT, price INT CHECK (price > 0));",
+			want: []string{
+				`ALTER TABLE "products" DROP CONSTRAINT "products_price_check1";`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := mustParse(t, tt.source, tt.dialect)
+			target := mustParse(t, tt.target, tt.dialect)
+			changes := NewDifferWithOptions(source, target, Options{DefaultSchema: schema.DefaultSchema(tt.dialect)}).Compare()
+
+			var buf bytes.Buffer
+			if err := NewSQLGenerator(tt.dialect).WriteSQL(&buf, changes); err != nil {
+				t.Fatalf("WriteSQL: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("migration lacks %s\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestIndexDropsPrecedeColumnChanges(t *testing.T) {
+	source := mustParse(t, "CREATE TABLE users (id INT PRIMARY KEY, login TEXT, email TEXT);"+
+		"CREATE INDEX idx_users_login ON users (login);"+
+		"CREATE INDEX idx_users_email ON users (email);", "postgres")
+	target := mustParse(t, "CREATE TABLE users (id INT PRIMARY KEY, username TEXT, email VARCHAR(320));"+
+		"CREATE INDEX idx_users_email ON users (email, id);", "postgres")
+	changes := NewDifferWithOptions(source, target, Options{DefaultSchema: "public", NoRenameDetection: true}).Compare()
+
+	var buf bytes.Buffer
+	if err := NewSQLGenerator("postgres").WriteSQL(&buf, changes); err != nil {
+		t.Fatalf("WriteSQL: %v", err)
+	}
+	// Dropping login drops its index, so DROP INDEX must come first
+	order := []string{
+		`DROP INDEX "idx_users_login";`,
+		`DROP INDEX "idx_users_email";`,
+		`ALTER TABLE "users" DROP COLUMN "login";`,
+		`ALTER TABLE "users" ALTER COLUMN "email" TYPE VARCHAR(320);`,
+		`CREATE INDEX "idx_users_email" ON "users" ("email", "id");`,
+	}
+	sql, last := buf.String(), -1
+	for _, stmt := range order {
+		i := strings.Index(sql, stmt)
+		if i < 0 {
+			t.Fatalf("migration lacks %s\n%s", stmt, sql)
+		}
+		if i < last {
+			t.Errorf("%s comes too early\n%s", stmt, sql)
+		}
+		last = i
+	}
+}
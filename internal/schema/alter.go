@@ -0,0 +1,845 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ignoredAlterActions lists ALTER TABLE actions that do not affect the
+// schema model (ownership, storage, security and maintenance settings).
+var ignoredAlterActions = []string{
+	"OWNER", "ENABLE", "DISABLE", "CLUSTER", "REPLICA", "ATTACH", "DETACH",
+	"INHERIT", "VALIDATE", "FORCE", "RESET", "OF", "CONVERT", "ORDER", "LOCK",
+	"ALGORITHM", "ENGINE", "AUTO_INCREMENT", "CHARSET", "COLLATE", "NOCHECK",
+	"REBUILD", "SWITCH", "ANALYZE", "OPTIMIZE", "REPAIR", "COALESCE", "REORGANIZE",
+}
+
+// parseAlterTable applies an ALTER TABLE statement to the table it names,
+// which must already be defined in s.
+func (p *Parser) parseAlterTable(s *Schema, ts *tokenStream) error {
+	ts.accept("IF", "EXISTS")
+	ts.accept("ONLY")
+
+	nameTok := ts.peek()
+	start := ts.pos
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+
+	table := s.table(schemaName, name)
+	if table == nil {
+		switch {
+		case isTableOption(ts):
+			// pg_dump sets the owner of sequences and views with ALTER TABLE
+			return nil
+		case s.sequence(schemaName, name) != nil:
+			ts.pos = start
+			return p.parseAlterSequence(s, ts)
+		case s.view(schemaName, name) != nil:
+			// As with ALTER VIEW, changes to views are not tracked
+			return nil
+		}
+		return ts.errorAt(nameTok, "ALTER TABLE of undefined table %s", name)
+	}
+
+	// SQL Server: ALTER TABLE t WITH CHECK ADD CONSTRAINT ...
+	ts.accept("WITH", "CHECK")
+	ts.accept("WITH", "NOCHECK")
+
+	var verb []Token
+	for {
+		action := ts.parseExpr(nil)
+		if len(action) > 0 {
+			// SQL Server lists further items without repeating ADD or DROP COLUMN
+			if len(verb) > 0 && !containsKeyword(alterVerbs, action[0]) &&
+				!containsKeyword(ignoredAlterActions, action[0]) {
+				action = append(append([]Token(nil), verb...), action...)
+			}
+			verb = alterVerb(action)
+
+			if err := p.applyAlterAction(s, table, newTokenStream(action)); err != nil {
+				return err
+			}
+		}
+		if !ts.acceptPunct(",") {
+			break
+		}
+	}
+
+	if !ts.atEnd() {
+		return ts.errorf(ts.peek(), "expected end of ALTER TABLE")
+	}
+	return nil
+}
+
+// isTableOption reports whether the stream is at an ALTER TABLE action that
+// only changes ownership, storage or other options that are not modeled.
+func isTableOption(ts *tokenStream) bool {
+	tok := ts.peek()
+	if tok.Is("SET") {
+		return !ts.peekN(1).Is("SCHEMA")
+	}
+	return containsKeyword(ignoredAlterActions, tok)
+}
+
+var alterVerbs = []string{"ADD", "DROP", "ALTER", "MODIFY", "CHANGE", "RENAME", "SET"}
+
+// alterVerb returns the leading tokens of an ADD or DROP action that a
+// following comma-separated item may omit.
+func alterVerb(action []Token) []Token {
+	switch {
+	case action[0].Is("ADD"):
+		return action[:1]
+	case action[0].Is("DROP") && len(action) > 1 && (action[1].Is("COLUMN") || action[1].Is("CONSTRAINT")):
+		return action[:2]
+	}
+	return nil
+}
+
+func (p *Parser) applyAlterAction(s *Schema, table *Table, ts *tokenStream) error {
+	tok := ts.peek()
+
+	switch {
+	case ts.accept("ADD"):
+		return p.alterAdd(s, table, ts)
+
+	case ts.accept("DROP"):
+		return p.alterDrop(s, table, ts)
+
+	case ts.accept("ALTER"):
+		ts.accept("COLUMN")
+		return p.alterColumn(table, ts)
+
+	case ts.accept("MODIFY"):
+		// MySQL: MODIFY [COLUMN] col definition
+		ts.accept("COLUMN")
+		col, err := p.parseColumnDef(ts, table)
+		if err != nil {
+			return err
+		}
+		return replaceColumn(s, table, col.Name, col)
+
+	case ts.accept("CHANGE"):
+		// MySQL: CHANGE [COLUMN] old new definition
+		ts.accept("COLUMN")
+		oldName, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		col, err := p.parseColumnDef(ts, table)
+		if err != nil {
+			return err
+		}
+		return replaceColumn(s, table, oldName, col)
+
+	case ts.accept("RENAME"):
+		return p.alterRename(s, table, ts)
+
+	case ts.accept("COMMENT"):
+		// MySQL: COMMENT [=] 'text'
+		ts.acceptPunct("=")
+		comment, err := parseStringLiteral(ts)
+		if err != nil {
+			return err
+		}
+		table.Comment = comment
+		return nil
+
+	case ts.accept("SET", "SCHEMA"):
+		newSchema, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		s.moveTable(table, newSchema, table.Name)
+		return nil
+
+	case tok.Is("SET") || tok.Is("NO") || tok.Is("DEFAULT") || tok.Is("CHECK") ||
+		containsKeyword(ignoredAlterActions, tok) || ts.peekN(1).IsPunct("="):
+		// Storage parameters, ownership and other table options
+		return nil
+
+	default:
+		return ts.errorf(tok, "unsupported ALTER TABLE action")
+	}
+}
+
+func (p *Parser) alterAdd(s *Schema, table *Table, ts *tokenStream) error {
+	tok := ts.peek()
+
+	switch {
+	case tok.Is("CONSTRAINT"):
+		ts.next()
+		name, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		// SQL Server: ADD CONSTRAINT name DEFAULT expr FOR col
+		if ts.accept("DEFAULT") {
+			return alterAddDefault(table, ts, name)
+		}
+		return p.addTableConstraint(table, ts, name)
+
+	case tok.Is("PRIMARY") || tok.Is("FOREIGN") || tok.Is("UNIQUE") || tok.Is("CHECK"):
+		return p.addTableConstraint(table, ts, "")
+
+	case ts.accept("DEFAULT"):
+		return alterAddDefault(table, ts, "")
+
+	case tok.Is("INDEX") || tok.Is("KEY") || tok.Is("FULLTEXT") || tok.Is("SPATIAL"):
+		indexType := ""
+		if kind, ok := ts.acceptAny("FULLTEXT", "SPATIAL"); ok {
+			indexType = strings.ToLower(kind)
+		}
+		idx, err := p.parseInlineIndex(ts, table, indexType)
+		if err != nil {
+			return err
+		}
+		table.Indexes = append(table.Indexes, *idx)
+		return nil
+
+	default:
+		ts.accept("COLUMN")
+		ts.accept("IF", "NOT", "EXISTS")
+		col, err := p.parseColumnDef(ts, table)
+		if err != nil {
+			return err
+		}
+		if table.column(col.Name) != nil {
+			return nil
+		}
+		table.Columns = append(table.Columns, *col)
+		table.syncPrimaryKey()
+		return nil
+	}
+}
+
+func (p *Parser) addTableConstraint(table *Table, ts *tokenStream, name string) error {
+	if err := p.parseTableConstraint(ts, table, name); err != nil {
+		return err
+	}
+	table.syncPrimaryKey()
+	return nil
+}
+
+// alterAddDefault handles SQL Server's ADD [CONSTRAINT name] DEFAULT expr FOR col.
+func alterAddDefault(table *Table, ts *tokenStream, name string) error {
+	expr := ts.parseExpr(func(ts *tokenStream) bool { return ts.peek().Is("FOR") })
+	if err := ts.expect("FOR"); err != nil {
+		return err
+	}
+
+	colTok := ts.peek()
+	colName, err := ts.parseIdent()
+	if err != nil {
+		return err
+	}
+	col := table.column(colName)
+	if col == nil {
+		return ts.errorAt(colTok, "unknown column %s in table %s", colName, table.Name)
+	}
+
+	defaultVal := joinTokens(expr)
+	col.Default = &defaultVal
+	col.DefaultName = name
+	return nil
+}
+
+func (p *Parser) alterDrop(s *Schema, table *Table, ts *tokenStream) error {
+	switch {
+	case ts.accept("CONSTRAINT"):
+		ts.accept("IF", "EXISTS")
+		name, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		table.dropConstraint(name)
+
+	case ts.accept("PRIMARY", "KEY"):
+		table.dropPrimaryKey()
+
+	case ts.accept("FOREIGN", "KEY"):
+		name, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		table.dropConstraint(name)
+
+	case ts.accept("INDEX") || ts.accept("KEY"):
+		name, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		s.dropIndex(table, name)
+
+	case ts.accept("CHECK"):
+		name, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		table.dropConstraint(name)
+
+	default:
+		ts.accept("COLUMN")
+		ts.accept("IF", "EXISTS")
+		colTok := ts.peek()
+		name, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		if table.column(name) == nil {
+			return ts.errorAt(colTok, "unknown column %s in table %s", name, table.Name)
+		}
+		s.dropColumn(table, name)
+	}
+
+	return nil
+}
+
+func (p *Parser) alterColumn(table *Table, ts *tokenStream) error {
+	colTok := ts.peek()
+	name, err := ts.parseIdent()
+	if err != nil {
+		return err
+	}
+	col := table.column(name)
+	if col == nil {
+		return ts.errorAt(colTok, "unknown column %s in table %s", name, table.Name)
+	}
+
+	switch {
+	case ts.accept("SET", "DEFAULT"):
+		defaultVal := joinTokens(ts.parseExpr(nil))
+		col.Default = &defaultVal
+
+	case ts.accept("DROP", "DEFAULT"):
+		col.Default = nil
+
+	case ts.accept("SET", "NOT", "NULL"):
+		col.Nullable = false
+
+	case ts.accept("DROP", "NOT", "NULL"):
+		col.Nullable = true
+
+	case ts.accept("SET", "DATA", "TYPE") || ts.accept("TYPE"):
+		col.Type, err = p.parseDataType(ts)
+		if err != nil {
+			return err
+		}
+		// USING expressions and collations only affect the conversion
+
+	case ts.accept("ADD", "GENERATED"):
+		// Postgres identity columns as emitted by pg_dump
+		col.IsIdentity = true
+		col.Identity = &Identity{Generation: parseIdentityGeneration(ts)}
+		if err := ts.expect("AS", "IDENTITY"); err != nil {
+			return err
+		}
+		return p.parseIdentityOptions(ts, col.Identity)
+
+	case ts.accept("DROP", "IDENTITY"):
+		col.IsIdentity = false
+		col.Identity = nil
+
+	case col.Identity != nil && isIdentityAlteration(ts):
+		// SET GENERATED ..., SET INCREMENT BY n, RESTART, ...
+		for !ts.atEnd() {
+			ts.accept("SET")
+			if ts.accept("GENERATED") {
+				col.Identity.Generation = parseIdentityGeneration(ts)
+				continue
+			}
+			if err := p.parseSequenceOption(ts, &col.Identity.SequenceOptions, nil); err != nil {
+				return err
+			}
+		}
+		col.Identity.clearDefaults()
+
+	case ts.peek().Is("SET") || ts.peek().Is("RESET") || ts.peek().Is("RESTART") ||
+		ts.peek().Is("OPTIONS"):
+		// Statistics, storage, sequence options and the like
+		return nil
+
+	default:
+		// SQL Server: ALTER COLUMN col type [NULL | NOT NULL]
+		col.Type, err = p.parseDataType(ts)
+		if err != nil {
+			return err
+		}
+		col.Nullable = !ts.accept("NOT", "NULL")
+		ts.accept("NULL")
+	}
+
+	return nil
+}
+
+func (p *Parser) alterRename(s *Schema, table *Table, ts *tokenStream) error {
+	switch {
+	case ts.accept("COLUMN"):
+		return alterRenameColumn(s, table, ts)
+
+	case ts.accept("CONSTRAINT"):
+		oldName, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		if err := ts.expect("TO"); err != nil {
+			return err
+		}
+		newName, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		table.renameConstraint(oldName, newName)
+		return nil
+
+	case ts.accept("INDEX") || ts.accept("KEY"):
+		oldName, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		if err := ts.expect("TO"); err != nil {
+			return err
+		}
+		newName, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		s.renameIndex(table, oldName, newName)
+		return nil
+
+	case ts.accept("TO") || ts.accept("AS"):
+		newSchema, newName, err := ts.parseQualifiedName()
+		if err != nil {
+			return err
+		}
+		if newSchema == "" {
+			newSchema = table.Schema
+		}
+		s.moveTable(table, newSchema, newName)
+		return nil
+
+	default:
+		// Postgres allows RENAME col TO new without COLUMN
+		return alterRenameColumn(s, table, ts)
+	}
+}
+
+func alterRenameColumn(s *Schema, table *Table, ts *tokenStream) error {
+	colTok := ts.peek()
+	oldName, err := ts.parseIdent()
+	if err != nil {
+		return err
+	}
+	if err := ts.expect("TO"); err != nil {
+		return err
+	}
+	newName, err := ts.parseIdent()
+	if err != nil {
+		return err
+	}
+
+	if table.column(oldName) == nil {
+		return ts.errorAt(colTok, "unknown column %s in table %s", oldName, table.Name)
+	}
+	s.renameColumn(table, oldName, newName)
+	return nil
+}
+
+// replaceColumn swaps the definition of column oldName for col, keeping
+// its position in the table.
+func replaceColumn(s *Schema, table *Table, oldName string, col *Column) error {
+	existing := table.column(oldName)
+	if existing == nil {
+		return fmt.Errorf("unknown column %s in table %s", oldName, table.Name)
+	}
+	if !strings.EqualFold(oldName, col.Name) {
+		s.renameColumn(table, oldName, col.Name)
+	}
+	*existing = *col
+	table.syncPrimaryKey()
+	return nil
+}
+
+// Model updates shared by ALTER TABLE and later DDL statements
+
+// moveTable renames a table and updates every reference to it.
+func (s *Schema) moveTable(table *Table, newSchema, newName string) {
+	oldSchema, oldName := table.Schema, table.Name
+
+	for i := range s.Tables {
+		for j := range s.Tables[i].ForeignKeys {
+			fk := &s.Tables[i].ForeignKeys[j]
+			if sameObject(fk.ReferencedSchema, fk.ReferencedTable, oldSchema, oldName) {
+				fk.ReferencedTable = newName
+				if fk.ReferencedSchema != "" {
+					fk.ReferencedSchema = newSchema
+				}
+			}
+		}
+	}
+
+	for i := range s.Indexes {
+		idx := &s.Indexes[i]
+		if sameObject(idx.Schema, idx.Table, oldSchema, oldName) {
+			idx.Schema, idx.Table = newSchema, newName
+		}
+	}
+	for i := range table.Indexes {
+		table.Indexes[i].Schema, table.Indexes[i].Table = newSchema, newName
+	}
+	for i := range s.Triggers {
+		trigger := &s.Triggers[i]
+		if sameObject(trigger.Schema, trigger.Table, oldSchema, oldName) {
+			trigger.Table = newName
+		}
+	}
+
+	table.Schema, table.Name = newSchema, newName
+}
+
+// renameColumn renames a column and updates the keys, indexes and
+// constraints that refer to it, including foreign keys in other tables.
+func (s *Schema) renameColumn(table *Table, oldName, newName string) {
+	if col := table.column(oldName); col != nil {
+		col.Name = newName
+	}
+
+	if table.PrimaryKey != nil {
+		renameInList(table.PrimaryKey.Columns, oldName, newName)
+	}
+	for i := range table.ForeignKeys {
+		renameInList(table.ForeignKeys[i].Columns, oldName, newName)
+	}
+	for i := range table.Constraints {
+		renameInList(table.Constraints[i].Columns, oldName, newName)
+	}
+	for i := range table.Indexes {
+		table.Indexes[i].renameColumn(oldName, newName)
+	}
+	for i := range s.Indexes {
+		if sameObject(s.Indexes[i].Schema, s.Indexes[i].Table, table.Schema, table.Name) {
+			s.Indexes[i].renameColumn(oldName, newName)
+		}
+	}
+	for i := range s.Tables {
+		for j := range s.Tables[i].ForeignKeys {
+			fk := &s.Tables[i].ForeignKeys[j]
+			if sameObject(fk.ReferencedSchema, fk.ReferencedTable, table.Schema, table.Name) {
+				renameInList(fk.ReferencedCols, oldName, newName)
+			}
+		}
+	}
+}
+
+// Rename names a table, or a column of it when Column is set, and the
+// name it is given.
+type Rename struct {
+	Schema  string `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Table   string `json:"table" yaml:"table"`
+	Column  string `json:"column,omitempty" yaml:"column,omitempty"`
+	NewName string `json:"new_name" yaml:"new_name"`
+}
+
+// ApplyRenames returns a copy of s with tables and columns renamed,
+// updating the keys, indexes and triggers that refer to them as the
+// database does. Renames are applied in order, so each names its table as
+// the earlier ones left it. Renames of objects s does not have are
+// ignored.
+func (s *Schema) ApplyRenames(renames []Rename) *Schema {
+	renamed := s.clone()
+	for _, r := range renames {
+		table := renamed.table(r.Schema, r.Table)
+		switch {
+		case table == nil:
+		case r.Column != "":
+			renamed.renameColumn(table, r.Column, r.NewName)
+		default:
+			renamed.moveTable(table, table.Schema, r.NewName)
+		}
+	}
+	return renamed
+}
+
+// dropColumn removes a column along with the keys, indexes and constraints
+// that depend on it, as the database would.
+func (s *Schema) dropColumn(table *Table, name string) {
+	var cols []Column
+	for _, c := range table.Columns {
+		if !strings.EqualFold(c.Name, name) {
+			cols = append(cols, c)
+		}
+	}
+	table.Columns = cols
+
+	if table.PrimaryKey != nil && containsName(table.PrimaryKey.Columns, name) {
+		table.dropPrimaryKey()
+	}
+
+	var fks []ForeignKey
+	for _, fk := range table.ForeignKeys {
+		if !containsName(fk.Columns, name) {
+			fks = append(fks, fk)
+		}
+	}
+	table.ForeignKeys = fks
+
+	var constraints []Constraint
+	for _, c := range table.Constraints {
+		if !containsName(c.Columns, name) {
+			constraints = append(constraints, c)
+		}
+	}
+	table.Constraints = constraints
+
+	var tableIndexes []Index
+	for _, idx := range table.Indexes {
+		if !idx.usesColumn(name) {
+			tableIndexes = append(tableIndexes, idx)
+		}
+	}
+	table.Indexes = tableIndexes
+
+	var indexes []Index
+	for _, idx := range s.Indexes {
+		if !sameObject(idx.Schema, idx.Table, table.Schema, table.Name) || !idx.usesColumn(name) {
+			indexes = append(indexes, idx)
+		}
+	}
+	s.Indexes = indexes
+}
+
+// renameColumn renames a key or included column of the index.
+func (idx *Index) renameColumn(oldName, newName string) {
+	renameInList(idx.Columns, oldName, newName)
+	renameInList(idx.Include, oldName, newName)
+	for i := range idx.Keys {
+		if strings.EqualFold(idx.Keys[i].Name, oldName) {
+			idx.Keys[i].Name = newName
+		}
+	}
+}
+
+// usesColumn reports whether the index has name as a key or included column.
+func (idx *Index) usesColumn(name string) bool {
+	return containsName(idx.Columns, name) || containsName(idx.Include, name)
+}
+
+// dropTable removes a table and the standalone indexes and triggers on it.
+// With cascade, foreign keys in other tables that reference it are dropped
+// too.
+func (s *Schema) dropTable(schemaName, name string, cascade bool) {
+	var tables []Table
+	for _, t := range s.Tables {
+		if !sameObject(schemaName, name, t.Schema, t.Name) {
+			tables = append(tables, t)
+		}
+	}
+	if len(tables) == len(s.Tables) {
+		return
+	}
+	s.Tables = tables
+
+	var indexes []Index
+	for _, idx := range s.Indexes {
+		if !sameObject(schemaName, name, idx.Schema, idx.Table) {
+			indexes = append(indexes, idx)
+		}
+	}
+	s.Indexes = indexes
+
+	var triggers []Trigger
+	for _, trigger := range s.Triggers {
+		if !sameObject(schemaName, name, trigger.Schema, trigger.Table) {
+			triggers = append(triggers, trigger)
+		}
+	}
+	s.Triggers = triggers
+
+	if !cascade {
+		return
+	}
+	for i := range s.Tables {
+		var fks []ForeignKey
+		for _, fk := range s.Tables[i].ForeignKeys {
+			if !sameObject(fk.ReferencedSchema, fk.ReferencedTable, schemaName, name) {
+				fks = append(fks, fk)
+			}
+		}
+		s.Tables[i].ForeignKeys = fks
+	}
+}
+
+func (s *Schema) dropView(schemaName, name string) {
+	var views []View
+	for _, v := range s.Views {
+		if !sameObject(schemaName, name, v.Schema, v.Name) {
+			views = append(views, v)
+		}
+	}
+	s.Views = views
+}
+
+// indexTable returns the table that owns the named index, whether it was
+// declared inline or with CREATE INDEX.
+func (s *Schema) indexTable(schemaName, name string) *Table {
+	for _, idx := range s.Indexes {
+		if sameObject(schemaName, name, idx.Schema, idx.Name) {
+			return s.table(idx.Schema, idx.Table)
+		}
+	}
+	for i := range s.Tables {
+		t := &s.Tables[i]
+		for _, idx := range t.Indexes {
+			if sameObject(schemaName, name, t.Schema, idx.Name) {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// dropStandaloneIndex removes an index whose table is not in the schema.
+func (s *Schema) dropStandaloneIndex(schemaName, name string) {
+	var indexes []Index
+	for _, idx := range s.Indexes {
+		if !sameObject(schemaName, name, idx.Schema, idx.Name) {
+			indexes = append(indexes, idx)
+		}
+	}
+	s.Indexes = indexes
+}
+
+// dropIndex removes a named index on table, whether declared inline or
+// with CREATE INDEX.
+func (s *Schema) dropIndex(table *Table, name string) {
+	var tableIndexes []Index
+	for _, idx := range table.Indexes {
+		if !strings.EqualFold(idx.Name, name) {
+			tableIndexes = append(tableIndexes, idx)
+		}
+	}
+	table.Indexes = tableIndexes
+
+	var indexes []Index
+	for _, idx := range s.Indexes {
+		if !strings.EqualFold(idx.Name, name) || !sameObject(idx.Schema, idx.Table, table.Schema, table.Name) {
+			indexes = append(indexes, idx)
+		}
+	}
+	s.Indexes = indexes
+
+	// MySQL unique keys are modeled as constraints
+	table.dropConstraint(name)
+}
+
+func (s *Schema) renameIndex(table *Table, oldName, newName string) {
+	for i := range table.Indexes {
+		if strings.EqualFold(table.Indexes[i].Name, oldName) {
+			table.Indexes[i].Name = newName
+		}
+	}
+	for i := range s.Indexes {
+		if strings.EqualFold(s.Indexes[i].Name, oldName) {
+			s.Indexes[i].Name = newName
+		}
+	}
+	table.renameConstraint(oldName, newName)
+}
+
+func (t *Table) dropPrimaryKey() {
+	if t.PrimaryKey == nil {
+		return
+	}
+	for _, name := range t.PrimaryKey.Columns {
+		if col := t.column(name); col != nil {
+			col.IsPrimaryKey = false
+		}
+	}
+	t.PrimaryKey = nil
+}
+
+// dropConstraint removes the named primary key, foreign key, constraint
+// or SQL Server default constraint.
+func (t *Table) dropConstraint(name string) {
+	// An unnamed primary key is table_pkey in PostgreSQL
+	if pk := t.PrimaryKey; pk != nil && (strings.EqualFold(pk.Name, name) || (pk.Name == "" && strings.EqualFold(name, t.Name+"_pkey"))) {
+		t.dropPrimaryKey()
+	}
+
+	for i := range t.Columns {
+		col := &t.Columns[i]
+		if col.DefaultName != "" && strings.EqualFold(col.DefaultName, name) {
+			col.Default = nil
+			col.DefaultName = ""
+		}
+		// A column declared UNIQUE is known by the name the database gives
+		// it: table_column_key in PostgreSQL, the column's own in MySQL
+		if col.IsUnique && (strings.EqualFold(name, t.Name+"_"+col.Name+"_key") || strings.EqualFold(name, col.Name)) {
+			col.IsUnique = false
+		}
+	}
+
+	var fks []ForeignKey
+	for _, fk := range t.ForeignKeys {
+		if !strings.EqualFold(fk.Name, name) {
+			fks = append(fks, fk)
+		}
+	}
+	t.ForeignKeys = fks
+
+	var constraints []Constraint
+	for _, c := range t.Constraints {
+		if !strings.EqualFold(c.Name, name) {
+			constraints = append(constraints, c)
+		}
+	}
+	t.Constraints = constraints
+}
+
+func (t *Table) renameConstraint(oldName, newName string) {
+	if t.PrimaryKey != nil && strings.EqualFold(t.PrimaryKey.Name, oldName) {
+		t.PrimaryKey.Name = newName
+	}
+	for i := range t.ForeignKeys {
+		if strings.EqualFold(t.ForeignKeys[i].Name, oldName) {
+			t.ForeignKeys[i].Name = newName
+		}
+	}
+	for i := range t.Constraints {
+		if strings.EqualFold(t.Constraints[i].Name, oldName) {
+			t.Constraints[i].Name = newName
+		}
+	}
+	for i := range t.Columns {
+		if t.Columns[i].DefaultName != "" && strings.EqualFold(t.Columns[i].DefaultName, oldName) {
+			t.Columns[i].DefaultName = newName
+		}
+	}
+}
+
+// sameObject reports whether a possibly unqualified reference names the
+// object schemaName.name.
+func sameObject(refSchema, refName, schemaName, name string) bool {
+	if !strings.EqualFold(refName, name) {
+		return false
+	}
+	return refSchema == "" || schemaName == "" || strings.EqualFold(refSchema, schemaName)
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func renameInList(names []string, oldName, newName string) {
+	for i, n := range names {
+		if strings.EqualFold(n, oldName) {
+			names[i] = newName
+		}
+	}
+}
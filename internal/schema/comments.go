@@ -0,0 +1,199 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseComment handles PostgreSQL's
+//
+//	COMMENT ON {TABLE | COLUMN} name IS {'text' | NULL}
+//
+// Comments on other kinds of objects are not modeled.
+func (p *Parser) parseComment(s *Schema, ts *tokenStream) error {
+	kind, ok := ts.acceptAny("TABLE", "COLUMN")
+	if !ok {
+		return nil
+	}
+
+	nameTok := ts.peek()
+	parts, err := parseDottedName(ts)
+	if err != nil {
+		return err
+	}
+	if err := ts.expect("IS"); err != nil {
+		return err
+	}
+	comment := ""
+	if !ts.accept("NULL") {
+		if comment, err = parseStringLiteral(ts); err != nil {
+			return err
+		}
+	}
+
+	if kind == "TABLE" {
+		table := s.table(schemaPart(parts), parts[len(parts)-1])
+		if table == nil {
+			return ts.errorAt(nameTok, "COMMENT ON undefined table %q", strings.Join(parts, "."))
+		}
+		table.Comment = comment
+		return nil
+	}
+
+	if len(parts) < 2 {
+		return ts.errorAt(nameTok, "column name %q must be qualified by its table", parts[0])
+	}
+	tableParts := parts[:len(parts)-1]
+	table := s.table(schemaPart(tableParts), tableParts[len(tableParts)-1])
+	if table == nil {
+		return ts.errorAt(nameTok, "COMMENT ON column of undefined table %q", strings.Join(tableParts, "."))
+	}
+	col := table.column(parts[len(parts)-1])
+	if col == nil {
+		return ts.errorAt(nameTok, "unknown column %s in table %s", parts[len(parts)-1], table.Name)
+	}
+	col.Comment = comment
+	return nil
+}
+
+// parseDottedName parses a name of any number of dot-separated parts.
+func parseDottedName(ts *tokenStream) ([]string, error) {
+	var parts []string
+	for {
+		part, err := ts.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+		if !ts.acceptPunct(".") {
+			return parts, nil
+		}
+	}
+}
+
+// parseTableOptions reads the options after a CREATE TABLE column list,
+// recording MySQL's COMMENT, ENGINE, CHARSET and COLLATE and SQLite's
+// WITHOUT ROWID and STRICT, and skipping the rest.
+func (p *Parser) parseTableOptions(ts *tokenStream, table *Table) error {
+	for !ts.atEnd() {
+		ts.accept("DEFAULT")
+		switch {
+		case ts.accept("COMMENT"):
+			ts.acceptPunct("=")
+			comment, err := parseStringLiteral(ts)
+			if err != nil {
+				return err
+			}
+			table.Comment = comment
+		case ts.accept("ENGINE"):
+			ts.acceptPunct("=")
+			table.Engine = ts.next().Value
+		case ts.accept("CHARSET") || ts.accept("CHARACTER", "SET"):
+			ts.acceptPunct("=")
+			table.Charset = ts.next().Value
+		case ts.accept("COLLATE"):
+			ts.acceptPunct("=")
+			table.Collation = ts.next().Value
+		case p.dialect == "sqlite" && ts.accept("WITHOUT", "ROWID"):
+			table.WithoutRowID = true
+		case p.dialect == "sqlite" && ts.accept("STRICT"):
+			table.Strict = true
+		default:
+			ts.next()
+		}
+	}
+	return nil
+}
+
+// procArg is an argument of a SQL Server procedure call, named
+// (@name = value) or positional.
+type procArg struct {
+	name  string
+	value string
+}
+
+// parseProcArgs parses the string arguments of an EXEC statement. NULL
+// arguments have an empty value.
+func parseProcArgs(ts *tokenStream, proc string) ([]procArg, error) {
+	var args []procArg
+	var arg procArg
+	for !ts.atEnd() {
+		tok := ts.next()
+		switch {
+		case tok.Kind == TokenString:
+			arg.value = tok.Value
+		case tok.Is("NULL"):
+			arg.value = ""
+		case tok.IsPunct(","):
+			args = append(args, arg)
+			arg = procArg{}
+		case tok.IsPunct("="):
+		case tok.Kind == TokenIdent && strings.HasPrefix(tok.Value, "@"):
+			arg.name = strings.ToLower(strings.TrimPrefix(tok.Value, "@"))
+		default:
+			return nil, ts.errorf(tok, "expected string argument to %s", proc)
+		}
+	}
+	return append(args, arg), nil
+}
+
+// bindProcArgs maps arguments to parameter names, assigning positional
+// arguments in order.
+func bindProcArgs(args []procArg, params []string) map[string]string {
+	bound := make(map[string]string)
+	for i, arg := range args {
+		switch {
+		case arg.name != "":
+			bound[arg.name] = arg.value
+		case i < len(params):
+			bound[params[i]] = arg.value
+		}
+	}
+	return bound
+}
+
+var extendedPropertyParams = []string{
+	"name", "value", "level0type", "level0name", "level1type", "level1name", "level2type", "level2name",
+}
+
+// execExtendedProperty applies sp_addextendedproperty,
+// sp_updateextendedproperty and sp_dropextendedproperty calls that set the
+// MS_Description of a table or column, SQL Server's equivalent of a
+// comment.
+func (p *Parser) execExtendedProperty(s *Schema, ts *tokenStream, proc string) error {
+	args, err := parseProcArgs(ts, proc)
+	if err != nil {
+		return err
+	}
+
+	drop := strings.EqualFold(proc, "sp_dropextendedproperty")
+	params := extendedPropertyParams
+	if drop {
+		// sp_dropextendedproperty takes no value
+		params = append([]string{"name"}, extendedPropertyParams[2:]...)
+	}
+	bound := bindProcArgs(args, params)
+
+	if !strings.EqualFold(bound["name"], "MS_Description") || !strings.EqualFold(bound["level1type"], "TABLE") {
+		return nil
+	}
+	comment := bound["value"]
+	if drop {
+		comment = ""
+	}
+
+	table := s.table(bound["level0name"], bound["level1name"])
+	if table == nil {
+		return fmt.Errorf("%s: undefined table %q", proc, bound["level1name"])
+	}
+	if !strings.EqualFold(bound["level2type"], "COLUMN") {
+		table.Comment = comment
+		return nil
+	}
+	col := table.column(bound["level2name"])
+	if col == nil {
+		return fmt.Errorf("%s: unknown column %s in table %s", proc, bound["level2name"], table.Name)
+	}
+	col.Comment = comment
+	return nil
+}
@@ -0,0 +1,78 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseError describes a SQL statement that could not be parsed.
+type ParseError struct {
+	File    string `json:"file,omitempty" yaml:"file,omitempty"`
+	Line    int    `json:"line" yaml:"line"`
+	Column  int    `json:"column" yaml:"column"`
+	Message string `json:"message" yaml:"message"`
+	Snippet string `json:"snippet,omitempty" yaml:"snippet,omitempty"` // source line containing the error
+}
+
+func newParseError(pos Position, msg string) *ParseError {
+	return &ParseError{Line: pos.Line, Column: pos.Column, Message: msg}
+}
+
+// Error formats the error as file:line:column: message.
+func (e *ParseError) Error() string {
+	loc := fmt.Sprintf("%d:%d", e.Line, e.Column)
+	if e.File != "" {
+		loc = e.File + ":" + loc
+	}
+	return loc + ": " + e.Message
+}
+
+// Context returns the offending source line with a caret under the
+// error column, or an empty string if no snippet is available.
+func (e *ParseError) Context() string {
+	if e.Snippet == "" {
+		return ""
+	}
+
+	// Preserve tabs so the caret lines up with the snippet
+	var pad strings.Builder
+	for i, r := range []rune(e.Snippet) {
+		if i >= e.Column-1 {
+			break
+		}
+		if r == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteRune(' ')
+		}
+	}
+	return e.Snippet + "\n" + pad.String() + "^"
+}
+
+// annotate fills in the file and source snippet of a ParseError. Other
+// errors are wrapped in a ParseError at the given position.
+func annotate(err error, file, src string, pos Position) *ParseError {
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		pe = newParseError(pos, err.Error())
+	}
+	pe.File = file
+	pe.Snippet = sourceLine(src, pe.Line)
+	return pe
+}
+
+// sourceLine returns the 1-based line of src without its line ending.
+func sourceLine(src string, line int) string {
+	for i := 1; i < line; i++ {
+		nl := strings.IndexByte(src, '\n')
+		if nl == -1 {
+			return ""
+		}
+		src = src[nl+1:]
+	}
+	if nl := strings.IndexByte(src, '\n'); nl != -1 {
+		src = src[:nl]
+	}
+	return strings.TrimRight(src, "\r")
+}
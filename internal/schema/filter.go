@@ -0,0 +1,235 @@
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Filter selects the objects of a schema by name, so that objects such as
+// migration bookkeeping tables can be left out of analysis and comparison.
+//
+// Patterns are globs (*, ? and [...]) matched case-insensitively, or
+// regular expressions when written between slashes, as in /_p[0-9]+$/. A
+// pattern matches an object's name or its qualified name: schema.name for
+// tables, views and indexes, and table.column or schema.table.column for
+// columns.
+type Filter struct {
+	// Include, when it has patterns for a kind of object, keeps only the
+	// objects of that kind that match one of them
+	Include FilterRules `json:"include,omitempty" yaml:"include,omitempty"`
+
+	// Exclude leaves out the objects that match one of its patterns
+	Exclude FilterRules `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
+
+// FilterRules lists name patterns by kind of object.
+type FilterRules struct {
+	Tables  []string `json:"tables,omitempty" yaml:"tables,omitempty"`
+	Views   []string `json:"views,omitempty" yaml:"views,omitempty"`
+	Indexes []string `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+	Columns []string `json:"columns,omitempty" yaml:"columns,omitempty"`
+}
+
+// LoadFilter reads a filter from a YAML or JSON file:
+//
+//	exclude:
+//	  tables: [schema_migrations, ar_internal_metadata, "tmp_*"]
+//	  columns: ["*.legacy_*"]
+func LoadFilter(filePath string) (*Filter, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading filter: %w", err)
+	}
+
+	var f Filter
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing filter %s: %w", filePath, err)
+	}
+	if err := f.Validate(); err != nil {
+		return nil, fmt.Errorf("filter %s: %w", filePath, err)
+	}
+	return &f, nil
+}
+
+// Validate checks that every pattern of the filter is well formed.
+func (f *Filter) Validate() error {
+	_, err := f.compile()
+	return err
+}
+
+// IsEmpty reports whether the filter keeps every object.
+func (f *Filter) IsEmpty() bool {
+	return f == nil || (f.Include.isEmpty() && f.Exclude.isEmpty())
+}
+
+func (r FilterRules) isEmpty() bool {
+	return len(r.Tables) == 0 && len(r.Views) == 0 && len(r.Indexes) == 0 && len(r.Columns) == 0
+}
+
+// Apply returns a copy of s without the objects the filter leaves out.
+// Leaving out a table also leaves out its indexes and triggers, and leaving
+// out a column the keys and indexes that use it. Malformed patterns match
+// nothing; use Validate to report them.
+func (f *Filter) Apply(s *Schema) *Schema {
+	filtered := s.clone()
+	if f.IsEmpty() {
+		return filtered
+	}
+	c, _ := f.compile()
+
+	var tables []Table
+	for _, t := range filtered.Tables {
+		if c.tables.keep(qualifiedNames(t.Schema, t.Name)) {
+			tables = append(tables, t)
+		} else {
+			filtered.dropTable(t.Schema, t.Name, false)
+		}
+	}
+	filtered.Tables = tables
+
+	for i := range filtered.Tables {
+		t := &filtered.Tables[i]
+		for _, col := range t.Columns {
+			names := []string{col.Name, t.Name + "." + col.Name}
+			if t.Schema != "" {
+				names = append(names, t.Schema+"."+t.Name+"."+col.Name)
+			}
+			if !c.columns.keep(names) {
+				filtered.dropColumn(t, col.Name)
+			}
+		}
+		var indexes []Index
+		for _, idx := range t.Indexes {
+			if c.indexes.keep(qualifiedNames(idx.Schema, idx.Name)) {
+				indexes = append(indexes, idx)
+			}
+		}
+		t.Indexes = indexes
+	}
+
+	var indexes []Index
+	for _, idx := range filtered.Indexes {
+		if c.indexes.keep(qualifiedNames(idx.Schema, idx.Name)) {
+			indexes = append(indexes, idx)
+		}
+	}
+	filtered.Indexes = indexes
+
+	var views []View
+	for _, v := range filtered.Views {
+		if c.views.keep(qualifiedNames(v.Schema, v.Name)) {
+			views = append(views, v)
+		}
+	}
+	filtered.Views = views
+
+	return filtered
+}
+
+// qualifiedNames returns the names a pattern may match an object by.
+func qualifiedNames(schemaName, name string) []string {
+	if schemaName == "" {
+		return []string{name}
+	}
+	return []string{name, schemaName + "." + name}
+}
+
+// compiledFilter holds the compiled patterns of a filter by kind.
+type compiledFilter struct {
+	tables, views, indexes, columns patternRules
+}
+
+// patternRules are the compiled include and exclude patterns for one kind
+// of object.
+type patternRules struct {
+	include, exclude []namePattern
+}
+
+// compile compiles the patterns of the filter, reporting the first
+// malformed one.
+func (f *Filter) compile() (*compiledFilter, error) {
+	var firstErr error
+	patterns := func(list []string) []namePattern {
+		var compiled []namePattern
+		for _, p := range list {
+			np, err := compilePattern(p)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			compiled = append(compiled, np)
+		}
+		return compiled
+	}
+	rules := func(include, exclude []string) patternRules {
+		return patternRules{include: patterns(include), exclude: patterns(exclude)}
+	}
+	return &compiledFilter{
+		tables:  rules(f.Include.Tables, f.Exclude.Tables),
+		views:   rules(f.Include.Views, f.Exclude.Views),
+		indexes: rules(f.Include.Indexes, f.Exclude.Indexes),
+		columns: rules(f.Include.Columns, f.Exclude.Columns),
+	}, firstErr
+}
+
+// keep reports whether an object known by any of names passes the rules.
+func (r patternRules) keep(names []string) bool {
+	if len(r.include) > 0 && !matchAny(r.include, names) {
+		return false
+	}
+	return !matchAny(r.exclude, names)
+}
+
+func matchAny(patterns []namePattern, names []string) bool {
+	for _, p := range patterns {
+		for _, name := range names {
+			if p.match(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// namePattern is a lower-cased glob or a regular expression. The zero
+// value, left by a malformed pattern, matches nothing.
+type namePattern struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// compilePattern compiles a glob or a /regular expression/.
+func compilePattern(pattern string) (namePattern, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return namePattern{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		return namePattern{re: re}, nil
+	}
+	glob := strings.ToLower(pattern)
+	if _, err := path.Match(glob, ""); err != nil {
+		return namePattern{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return namePattern{glob: glob}, nil
+}
+
+func (p namePattern) match(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+	if p.glob == "" {
+		return false
+	}
+	ok, _ := path.Match(p.glob, strings.ToLower(name))
+	return ok
+}
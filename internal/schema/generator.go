@@ -7,7 +7,8 @@ import (
 
 // Generator generates SQL from a Schema.
 type Generator struct {
-	dialect string
+	dialect   string
+	userTypes map[string]bool
 }
 
 // NewGenerator creates a new SQL generator for the given dialect.
@@ -19,19 +20,57 @@ func NewGenerator(dialect string) *Generator {
 func (g *Generator) Generate(s *Schema) string {
 	var sb strings.Builder
 
+	g.userTypes = make(map[string]bool)
+	for _, e := range s.Enums {
+		g.userTypes[strings.ToLower(e.Name)] = true
+	}
+	for _, d := range s.Domains {
+		g.userTypes[strings.ToLower(d.Name)] = true
+	}
+
+	// Schemas first, as every other object may live in one
+	for _, name := range s.Schemas {
+		sb.WriteString(g.GenerateCreateSchema(name))
+		sb.WriteString("\n\n")
+	}
+
+	// Generate types first so tables can use them
+	types := g.generateTypes(s)
+	if types != "" {
+		sb.WriteString(types)
+	}
+
+	// Sequences come next so column defaults can draw from them
+	sequences := g.generateSequences(s)
+	if sequences != "" {
+		if types != "" {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(sequences)
+	}
+
 	// Generate CREATE TABLE statements
 	for i, table := range s.Tables {
-		if i > 0 {
+		if i > 0 || types != "" || sequences != "" {
 			sb.WriteString("\n")
 		}
 		sb.WriteString(g.generateCreateTable(&table))
 		sb.WriteString("\n")
 	}
 
+	// Tie sequences to the columns that own them
+	for _, seq := range s.Sequences {
+		if stmt := g.GenerateSequenceOwner(&seq); stmt != "" {
+			sb.WriteString("\n")
+			sb.WriteString(stmt)
+			sb.WriteString("\n")
+		}
+	}
+
 	// Generate standalone indexes
 	for _, idx := range s.Indexes {
 		sb.WriteString("\n")
-		sb.WriteString(g.generateCreateIndex(&idx))
+		sb.WriteString(g.GenerateIndex(&idx))
 		sb.WriteString("\n")
 	}
 
@@ -42,6 +81,18 @@ func (g *Generator) Generate(s *Schema) string {
 		sb.WriteString("\n")
 	}
 
+	// Routines and triggers last, as their bodies may use any other object
+	for _, r := range s.Routines {
+		sb.WriteString("\n")
+		sb.WriteString(g.GenerateRoutine(&r, false))
+		sb.WriteString("\n")
+	}
+	for _, t := range s.Triggers {
+		sb.WriteString("\n")
+		sb.WriteString(g.GenerateTrigger(&t, false))
+		sb.WriteString("\n")
+	}
+
 	return sb.String()
 }
 
@@ -55,19 +106,27 @@ func (g *Generator) generateCreateTable(t *Table) string {
 
 	sb.WriteString(fmt.Sprintf("CREATE TABLE %s (\n", tableName))
 
-	// Columns
+	// Columns; a primary key of several columns, or a named one, follows
+	// them
+	tableKey := g.tableLevelPrimaryKey(t)
 	for i, col := range t.Columns {
 		if i > 0 {
 			sb.WriteString(",\n")
 		}
+		switch {
+		case tableKey:
+			col.IsPrimaryKey = false
+		case t.PrimaryKey != nil && len(t.PrimaryKey.Columns) == 1:
+			col.IsPrimaryKey = strings.EqualFold(col.Name, t.PrimaryKey.Columns[0])
+		}
 		sb.WriteString("    ")
 		sb.WriteString(g.generateColumnDef(&col))
 	}
 
 	// Primary key constraint (if not inline)
-	if t.PrimaryKey != nil && len(t.PrimaryKey.Columns) > 1 {
+	if tableKey {
 		sb.WriteString(",\n    ")
-		sb.WriteString(g.generatePrimaryKey(t.PrimaryKey))
+		sb.WriteString(g.GeneratePrimaryKey(t.PrimaryKey))
 	}
 
 	// Foreign key constraints
@@ -79,16 +138,29 @@ func (g *Generator) generateCreateTable(t *Table) string {
 	// Other constraints
 	for _, c := range t.Constraints {
 		sb.WriteString(",\n    ")
-		sb.WriteString(g.generateConstraint(&c))
+		sb.WriteString(g.GenerateConstraint(&c))
 	}
 
-	sb.WriteString("\n);")
+	sb.WriteString("\n)")
+	switch g.dialect {
+	case "mysql":
+		sb.WriteString(g.generateTableOptions(t))
+	case "sqlite":
+		sb.WriteString(g.generateSQLiteTableOptions(t))
+	}
+	sb.WriteString(";")
+
+	// Comments, in dialects that set them with separate statements
+	if comments := g.generateComments(t); len(comments) > 0 {
+		sb.WriteString("\n\n")
+		sb.WriteString(strings.Join(comments, "\n"))
+	}
 
 	// Inline indexes for this table
 	for _, idx := range t.Indexes {
 		if !idx.IsPrimary {
 			sb.WriteString("\n\n")
-			sb.WriteString(g.generateCreateIndex(&idx))
+			sb.WriteString(g.GenerateIndex(&idx))
 		}
 	}
 
@@ -99,17 +171,37 @@ func (g *Generator) generateColumnDef(c *Column) string {
 	var parts []string
 
 	parts = append(parts, g.quoteName(c.Name))
-	parts = append(parts, g.mapType(c.Type, c.IsIdentity))
+
+	if c.Generated != nil {
+		// SQL Server computed columns take their type from the expression
+		if g.dialect != "sqlserver" && c.Type != "" {
+			parts = append(parts, g.mapType(c.Type, false))
+			parts = append(parts, g.columnCharset(c)...)
+		}
+		parts = append(parts, g.GenerateComputed(c.Generated))
+	} else if colType := g.columnType(c); colType != "" {
+		// SQLite columns may have no type
+		parts = append(parts, colType)
+		parts = append(parts, g.columnCharset(c)...)
+	}
 
 	if !c.Nullable {
 		parts = append(parts, "NOT NULL")
 	}
 
-	if c.Default != nil {
+	if c.Default != nil && c.Generated == nil {
+		if g.dialect == "sqlserver" && c.DefaultName != "" {
+			parts = append(parts, "CONSTRAINT", g.quoteName(c.DefaultName))
+		}
 		parts = append(parts, "DEFAULT", *c.Default)
 	}
 
-	if c.IsPrimaryKey && !c.IsIdentity {
+	if g.dialect == "mysql" && c.OnUpdate != "" {
+		parts = append(parts, "ON UPDATE", c.OnUpdate)
+	}
+
+	// SQLite's AUTOINCREMENT type includes PRIMARY KEY
+	if c.IsPrimaryKey && !(g.dialect == "sqlite" && c.IsIdentity) {
 		parts = append(parts, "PRIMARY KEY")
 	}
 
@@ -117,10 +209,256 @@ func (g *Generator) generateColumnDef(c *Column) string {
 		parts = append(parts, "UNIQUE")
 	}
 
+	if g.dialect == "mysql" && c.Comment != "" {
+		parts = append(parts, "COMMENT", QuoteString(c.Comment))
+	}
+
 	return strings.Join(parts, " ")
 }
 
-func (g *Generator) generatePrimaryKey(pk *PrimaryKey) string {
+// generateTableOptions renders the MySQL table options following the
+// column list of CREATE TABLE.
+func (g *Generator) generateTableOptions(t *Table) string {
+	var sb strings.Builder
+	if t.Engine != "" {
+		sb.WriteString(" ENGINE=" + t.Engine)
+	}
+	if t.Charset != "" {
+		sb.WriteString(" DEFAULT CHARSET=" + t.Charset)
+	}
+	if t.Collation != "" {
+		sb.WriteString(" COLLATE=" + t.Collation)
+	}
+	if t.Comment != "" {
+		sb.WriteString(" COMMENT=" + QuoteString(t.Comment))
+	}
+	return sb.String()
+}
+
+// generateSQLiteTableOptions renders the SQLite table options following
+// the column list of CREATE TABLE.
+func (g *Generator) generateSQLiteTableOptions(t *Table) string {
+	var options []string
+	if t.WithoutRowID {
+		options = append(options, "WITHOUT ROWID")
+	}
+	if t.Strict {
+		options = append(options, "STRICT")
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	return " " + strings.Join(options, ", ")
+}
+
+// columnCharset renders the MySQL CHARACTER SET and COLLATE clauses of a
+// column, or its SQLite COLLATE clause.
+func (g *Generator) columnCharset(c *Column) []string {
+	if g.dialect == "sqlite" && c.Collation != "" {
+		return []string{"COLLATE", c.Collation}
+	}
+	if g.dialect != "mysql" {
+		return nil
+	}
+	var parts []string
+	if c.Charset != "" {
+		parts = append(parts, "CHARACTER SET", c.Charset)
+	}
+	if c.Collation != "" {
+		parts = append(parts, "COLLATE", c.Collation)
+	}
+	return parts
+}
+
+// generateComments produces the statements setting the table and column
+// comments of t. MySQL keeps comments in the definitions instead, and
+// SQLite has none.
+func (g *Generator) generateComments(t *Table) []string {
+	if g.dialect == "mysql" || g.dialect == "sqlite" {
+		return nil
+	}
+
+	var comments []string
+	if t.Comment != "" {
+		comments = append(comments, g.GenerateComment(t.Schema, t.Name, "", "", t.Comment))
+	}
+	for _, col := range t.Columns {
+		if col.Comment != "" {
+			comments = append(comments, g.GenerateComment(t.Schema, t.Name, col.Name, "", col.Comment))
+		}
+	}
+	return comments
+}
+
+// GenerateComment produces the statement changing the comment of a table,
+// or of one of its columns when column is set, from oldComment to
+// newComment; an empty comment means none. PostgreSQL uses COMMENT ON and
+// SQL Server the MS_Description extended property. MySQL column comments
+// are part of the column definition, so only table comments are generated
+// there.
+func (g *Generator) GenerateComment(schemaName, table, column, oldComment, newComment string) string {
+	switch g.dialect {
+	case "postgres":
+		value := "NULL"
+		if newComment != "" {
+			value = QuoteString(newComment)
+		}
+		if column != "" {
+			return fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;", g.qualifiedName(schemaName, table), g.quoteName(column), value)
+		}
+		return fmt.Sprintf("COMMENT ON TABLE %s IS %s;", g.qualifiedName(schemaName, table), value)
+
+	case "mysql":
+		if column != "" {
+			return ""
+		}
+		return fmt.Sprintf("ALTER TABLE %s COMMENT = %s;", g.qualifiedName(schemaName, table), QuoteString(newComment))
+
+	case "sqlserver":
+		if schemaName == "" {
+			schemaName = "dbo"
+		}
+		proc := "sp_updateextendedproperty"
+		switch {
+		case oldComment == "":
+			proc = "sp_addextendedproperty"
+		case newComment == "":
+			proc = "sp_dropextendedproperty"
+		}
+
+		args := []string{"@name = N'MS_Description'"}
+		if newComment != "" {
+			args = append(args, "@value = N"+QuoteString(newComment))
+		}
+		args = append(args,
+			"@level0type = N'SCHEMA'", "@level0name = N"+QuoteString(schemaName),
+			"@level1type = N'TABLE'", "@level1name = N"+QuoteString(table))
+		if column != "" {
+			args = append(args, "@level2type = N'COLUMN'", "@level2name = N"+QuoteString(column))
+		}
+		return fmt.Sprintf("EXEC %s %s;", proc, strings.Join(args, ", "))
+
+	default:
+		return ""
+	}
+}
+
+// columnType renders the column's type including its identity clause:
+// GENERATED ... AS IDENTITY or SERIAL in PostgreSQL, AUTO_INCREMENT in
+// MySQL, IDENTITY(seed, increment) in SQL Server and INTEGER PRIMARY KEY
+// AUTOINCREMENT in SQLite.
+func (g *Generator) columnType(c *Column) string {
+	switch g.dialect {
+	case "postgres":
+		if c.Identity != nil && (c.Identity.Generation != "" || !c.Identity.IsDefault()) {
+			return g.mapType(serialBaseType(c.Type), false) + " " + g.GenerateIdentity(c.Identity)
+		}
+		if c.Default != nil && isSequenceDefault(*c.Default) {
+			// The default names its sequence, which is created separately
+			return g.mapType(serialBaseType(c.Type), false)
+		}
+
+	case "sqlserver":
+		if c.IsIdentity && c.Identity != nil {
+			return g.mapType(serialBaseType(c.Type), false) + " " + g.GenerateIdentity(c.Identity)
+		}
+
+	case "sqlite":
+		// AUTOINCREMENT is only allowed on an INTEGER PRIMARY KEY
+		if c.IsIdentity && c.IsPrimaryKey {
+			return "INTEGER PRIMARY KEY AUTOINCREMENT"
+		}
+	}
+
+	return g.mapType(c.Type, c.IsIdentity)
+}
+
+// GenerateComputed renders the generation clause of a generated column:
+// GENERATED ALWAYS AS (expr) {STORED | VIRTUAL}, or AS (expr) [PERSISTED]
+// in SQL Server.
+func (g *Generator) GenerateComputed(gen *Generated) string {
+	if g.dialect == "sqlserver" {
+		clause := fmt.Sprintf("AS (%s)", gen.Expression)
+		if gen.Stored {
+			clause += " PERSISTED"
+		}
+		return clause
+	}
+
+	storage := "VIRTUAL"
+	if gen.Stored {
+		storage = "STORED"
+	}
+	return fmt.Sprintf("GENERATED ALWAYS AS (%s) %s", gen.Expression, storage)
+}
+
+// GenerateIdentity renders the identity clause of a column definition.
+func (g *Generator) GenerateIdentity(identity *Identity) string {
+	switch g.dialect {
+	case "mysql":
+		return "AUTO_INCREMENT"
+
+	case "sqlserver":
+		start, increment := int64(1), int64(1)
+		if identity.Start != nil {
+			start = *identity.Start
+		}
+		if identity.Increment != nil {
+			increment = *identity.Increment
+		}
+		return fmt.Sprintf("IDENTITY(%d,%d)", start, increment)
+
+	default: // postgres
+		generation := identity.Generation
+		if generation == "" {
+			generation = "BY DEFAULT"
+		}
+		clause := fmt.Sprintf("GENERATED %s AS IDENTITY", generation)
+		if opts := g.GenerateSequenceOptions(&identity.SequenceOptions); opts != "" {
+			clause += " (" + opts + ")"
+		}
+		return clause
+	}
+}
+
+// serialBaseType returns the integer type underlying a SERIAL type.
+func serialBaseType(t string) string {
+	switch strings.ToUpper(t) {
+	case "SERIAL", "SERIAL4":
+		return "INTEGER"
+	case "BIGSERIAL", "SERIAL8":
+		return "BIGINT"
+	case "SMALLSERIAL", "SERIAL2":
+		return "SMALLINT"
+	default:
+		return t
+	}
+}
+
+// tableLevelPrimaryKey reports whether the primary key of t is declared
+// after the columns rather than on its column: when it has several
+// columns, or a name, except on a SQLite AUTOINCREMENT column.
+func (g *Generator) tableLevelPrimaryKey(t *Table) bool {
+	pk := t.PrimaryKey
+	if pk == nil || len(pk.Columns) == 0 {
+		return false
+	}
+	if len(pk.Columns) > 1 {
+		return true
+	}
+	if g.dialect == "sqlite" {
+		for _, col := range t.Columns {
+			if strings.EqualFold(col.Name, pk.Columns[0]) && col.IsIdentity {
+				return false
+			}
+		}
+	}
+	return pk.Name != ""
+}
+
+// GeneratePrimaryKey returns the table constraint declaring a primary key:
+// [CONSTRAINT name] PRIMARY KEY (columns).
+func (g *Generator) GeneratePrimaryKey(pk *PrimaryKey) string {
 	cols := make([]string, len(pk.Columns))
 	for i, c := range pk.Columns {
 		cols[i] = g.quoteName(c)
@@ -158,17 +496,28 @@ func (g *Generator) generateForeignKey(fk *ForeignKey) string {
 	sb.WriteString(fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
 		strings.Join(localCols, ", "), refTable, strings.Join(refCols, ", ")))
 
+	if fk.Match != "" && g.dialect == "postgres" {
+		sb.WriteString(" MATCH " + fk.Match)
+	}
 	if fk.OnDelete != "" {
 		sb.WriteString(" ON DELETE " + fk.OnDelete)
 	}
 	if fk.OnUpdate != "" {
 		sb.WriteString(" ON UPDATE " + fk.OnUpdate)
 	}
+	if fk.Deferrable && (g.dialect == "postgres" || g.dialect == "sqlite") {
+		sb.WriteString(" DEFERRABLE")
+		if fk.InitiallyDeferred {
+			sb.WriteString(" INITIALLY DEFERRED")
+		}
+	}
 
 	return sb.String()
 }
 
-func (g *Generator) generateConstraint(c *Constraint) string {
+// GenerateConstraint returns the definition of a CHECK or UNIQUE
+// constraint, as written in CREATE TABLE or after ALTER TABLE ... ADD.
+func (g *Generator) GenerateConstraint(c *Constraint) string {
 	var sb strings.Builder
 
 	if c.Name != "" {
@@ -190,10 +539,172 @@ func (g *Generator) generateConstraint(c *Constraint) string {
 	return sb.String()
 }
 
-func (g *Generator) generateCreateIndex(idx *Index) string {
-	cols := make([]string, len(idx.Columns))
-	for i, c := range idx.Columns {
-		cols[i] = g.quoteName(c)
+// generateTypes produces the enum and domain definitions the dialect has
+// standalone syntax for: enums and domains in PostgreSQL, alias types in
+// SQL Server.
+func (g *Generator) generateTypes(s *Schema) string {
+	var sb strings.Builder
+
+	for _, e := range s.Enums {
+		if stmt := g.GenerateEnum(&e); stmt != "" {
+			sb.WriteString(stmt)
+			sb.WriteString("\n")
+		}
+	}
+	for _, d := range s.Domains {
+		if stmt := g.GenerateDomain(&d); stmt != "" {
+			sb.WriteString(stmt)
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// GenerateEnum produces the CREATE TYPE statement for an enum. Only
+// PostgreSQL has named enums; other dialects return "".
+func (g *Generator) GenerateEnum(e *Enum) string {
+	if g.dialect != "postgres" {
+		return ""
+	}
+
+	values := make([]string, len(e.Values))
+	for i, v := range e.Values {
+		values[i] = QuoteString(v)
+	}
+	return fmt.Sprintf("CREATE TYPE %s AS ENUM (%s);",
+		g.qualifiedName(e.Schema, e.Name), strings.Join(values, ", "))
+}
+
+// GenerateDomain produces the statement creating a domain: CREATE DOMAIN
+// in PostgreSQL, CREATE TYPE ... FROM in SQL Server. MySQL has no domains
+// and returns "".
+func (g *Generator) GenerateDomain(d *Domain) string {
+	switch g.dialect {
+	case "postgres":
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("CREATE DOMAIN %s AS %s", g.qualifiedName(d.Schema, d.Name), d.Type))
+		if d.Collation != "" {
+			sb.WriteString(" COLLATE " + d.Collation)
+		}
+		if d.Default != nil {
+			sb.WriteString(" DEFAULT " + *d.Default)
+		}
+		if d.NotNull {
+			sb.WriteString(" NOT NULL")
+		}
+		for _, c := range d.Checks {
+			if c.Name != "" {
+				sb.WriteString(" CONSTRAINT " + g.quoteName(c.Name))
+			}
+			sb.WriteString(fmt.Sprintf(" CHECK (%s)", c.Expression))
+		}
+		sb.WriteString(";")
+		return sb.String()
+
+	case "sqlserver":
+		nullable := " NULL"
+		if d.NotNull {
+			nullable = " NOT NULL"
+		}
+		return fmt.Sprintf("CREATE TYPE %s FROM %s%s;", g.qualifiedName(d.Schema, d.Name), d.Type, nullable)
+
+	default:
+		return ""
+	}
+}
+
+// generateSequences produces the CREATE SEQUENCE statements of the schema.
+func (g *Generator) generateSequences(s *Schema) string {
+	var sb strings.Builder
+
+	for _, seq := range s.Sequences {
+		if stmt := g.GenerateSequence(&seq); stmt != "" {
+			sb.WriteString(stmt)
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// GenerateSequence produces the CREATE SEQUENCE statement for a sequence.
+// MySQL has no sequences and returns "".
+func (g *Generator) GenerateSequence(seq *Sequence) string {
+	if g.dialect != "postgres" && g.dialect != "sqlserver" {
+		return ""
+	}
+
+	stmt := "CREATE SEQUENCE " + g.qualifiedName(seq.Schema, seq.Name)
+	if seq.Type != "" {
+		stmt += " AS " + seq.Type
+	}
+	if opts := g.GenerateSequenceOptions(&seq.SequenceOptions); opts != "" {
+		stmt += " " + opts
+	}
+	return stmt + ";"
+}
+
+// GenerateSequenceOwner produces the ALTER SEQUENCE ... OWNED BY statement
+// tying a sequence to its column. Only PostgreSQL has sequence ownership;
+// other dialects and unowned sequences return "".
+func (g *Generator) GenerateSequenceOwner(seq *Sequence) string {
+	if g.dialect != "postgres" || seq.OwnedBy == "" {
+		return ""
+	}
+
+	table, column := splitTypeName(seq.OwnedBy)
+	owner := g.quoteName(column)
+	if table != "" {
+		owner = g.qualifiedName(seq.Schema, table) + "." + owner
+	}
+	return fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s;", g.qualifiedName(seq.Schema, seq.Name), owner)
+}
+
+// GenerateSequenceOptions renders the options that are set, in the order
+// CREATE SEQUENCE lists them.
+func (g *Generator) GenerateSequenceOptions(opts *SequenceOptions) string {
+	var parts []string
+	if opts.Increment != nil {
+		parts = append(parts, fmt.Sprintf("INCREMENT BY %d", *opts.Increment))
+	}
+	if opts.MinValue != nil {
+		parts = append(parts, fmt.Sprintf("MINVALUE %d", *opts.MinValue))
+	}
+	if opts.MaxValue != nil {
+		parts = append(parts, fmt.Sprintf("MAXVALUE %d", *opts.MaxValue))
+	}
+	if opts.Start != nil {
+		parts = append(parts, fmt.Sprintf("START WITH %d", *opts.Start))
+	}
+	if opts.Cache != nil {
+		parts = append(parts, fmt.Sprintf("CACHE %d", *opts.Cache))
+	}
+	if opts.Cycle {
+		parts = append(parts, "CYCLE")
+	}
+	return strings.Join(parts, " ")
+}
+
+func (g *Generator) qualifiedName(schemaName, name string) string {
+	if schemaName != "" {
+		return g.quoteName(schemaName) + "." + g.quoteName(name)
+	}
+	return g.quoteName(name)
+}
+
+// QuoteString renders s as a SQL string literal.
+func QuoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// GenerateIndex produces the CREATE INDEX statement for an index. Key
+// options the dialect has no syntax for are left out.
+func (g *Generator) GenerateIndex(idx *Index) string {
+	keys := idx.KeyColumns()
+	cols := make([]string, len(keys))
+	for i, k := range keys {
+		cols[i] = g.generateIndexKey(k)
 	}
 
 	tableName := g.quoteName(idx.Table)
@@ -206,8 +717,63 @@ func (g *Generator) generateCreateIndex(idx *Index) string {
 		unique = "UNIQUE "
 	}
 
-	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s);",
-		unique, g.quoteName(idx.Name), tableName, strings.Join(cols, ", "))
+	using := ""
+	if g.dialect == "postgres" && idx.Type != "" && !strings.EqualFold(idx.Type, "btree") {
+		using = " USING " + strings.ToLower(idx.Type)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("CREATE %sINDEX %s ON %s%s (%s)",
+		unique, g.quoteName(idx.Name), tableName, using, strings.Join(cols, ", ")))
+
+	if len(idx.Include) > 0 && g.dialect != "mysql" && g.dialect != "sqlite" {
+		include := make([]string, len(idx.Include))
+		for i, c := range idx.Include {
+			include[i] = g.quoteName(c)
+		}
+		sb.WriteString(fmt.Sprintf(" INCLUDE (%s)", strings.Join(include, ", ")))
+	}
+	if idx.Where != "" && g.dialect != "mysql" {
+		sb.WriteString(" WHERE " + idx.Where)
+	}
+
+	sb.WriteString(";")
+	return sb.String()
+}
+
+func (g *Generator) generateIndexKey(k IndexColumn) string {
+	var sb strings.Builder
+
+	if k.Expression != "" {
+		if g.dialect == "mysql" && !strings.HasPrefix(k.Expression, "(") {
+			// MySQL functional key parts must be parenthesized
+			sb.WriteString("(" + k.Expression + ")")
+		} else {
+			sb.WriteString(k.Expression)
+		}
+	} else {
+		sb.WriteString(g.quoteName(k.Name))
+		if k.Length > 0 && g.dialect == "mysql" {
+			sb.WriteString(fmt.Sprintf("(%d)", k.Length))
+		}
+	}
+
+	if k.Collation != "" && (g.dialect == "postgres" || g.dialect == "sqlite") {
+		sb.WriteString(" COLLATE " + k.Collation)
+	}
+	if g.dialect == "postgres" {
+		if k.OpClass != "" {
+			sb.WriteString(" " + k.OpClass)
+		}
+	}
+	if k.Descending {
+		sb.WriteString(" DESC")
+	}
+	if k.Nulls != "" && g.dialect == "postgres" {
+		sb.WriteString(" NULLS " + k.Nulls)
+	}
+
+	return sb.String()
 }
 
 func (g *Generator) generateCreateView(v *View) string {
@@ -225,12 +791,18 @@ func (g *Generator) quoteName(name string) string {
 		return "`" + name + "`"
 	case "sqlserver":
 		return "[" + name + "]"
-	default: // postgres
+	default: // postgres, sqlite
 		return `"` + name + `"`
 	}
 }
 
 func (g *Generator) mapType(t string, isIdentity bool) string {
+	// Enum and domain names, and types with string literals such as
+	// ENUM('a','b'), are kept as written
+	if _, name := splitTypeName(t); g.userTypes[strings.ToLower(name)] || strings.Contains(t, "'") {
+		return t
+	}
+
 	upper := strings.ToUpper(t)
 
 	switch g.dialect {
@@ -238,6 +810,8 @@ func (g *Generator) mapType(t string, isIdentity bool) string {
 		return g.toMySQL(upper, isIdentity)
 	case "sqlserver":
 		return g.toSQLServer(upper, isIdentity)
+	case "sqlite":
+		return g.toSQLite(upper, isIdentity)
 	default: // postgres
 		return g.toPostgres(upper, isIdentity)
 	}
@@ -245,8 +819,11 @@ func (g *Generator) mapType(t string, isIdentity bool) string {
 
 func (g *Generator) toPostgres(t string, isIdentity bool) string {
 	if isIdentity {
-		if strings.Contains(t, "BIG") {
+		switch {
+		case strings.Contains(t, "BIG") || t == "SERIAL8" || t == "INT8":
 			return "BIGSERIAL"
+		case strings.Contains(t, "SMALL") || t == "SERIAL2" || t == "INT2":
+			return "SMALLSERIAL"
 		}
 		return "SERIAL"
 	}
@@ -284,8 +861,10 @@ func (g *Generator) toPostgres(t string, isIdentity bool) string {
 func (g *Generator) toMySQL(t string, isIdentity bool) string {
 	if isIdentity {
 		baseType := "INT"
-		if strings.Contains(t, "BIG") || t == "BIGSERIAL" {
+		if strings.Contains(t, "BIG") || t == "SERIAL8" || t == "INT8" {
 			baseType = "BIGINT"
+		} else if strings.Contains(t, "SMALL") || t == "SERIAL2" || t == "INT2" {
+			baseType = "SMALLINT"
 		}
 		return baseType + " AUTO_INCREMENT"
 	}
@@ -295,6 +874,8 @@ func (g *Generator) toMySQL(t string, isIdentity bool) string {
 		return "INT AUTO_INCREMENT"
 	case t == "BIGSERIAL":
 		return "BIGINT AUTO_INCREMENT"
+	case t == "SMALLSERIAL":
+		return "SMALLINT AUTO_INCREMENT"
 	case t == "INTEGER":
 		return "INT"
 	case t == "BOOLEAN" || t == "BOOL":
@@ -315,8 +896,10 @@ func (g *Generator) toMySQL(t string, isIdentity bool) string {
 func (g *Generator) toSQLServer(t string, isIdentity bool) string {
 	if isIdentity {
 		baseType := "INT"
-		if strings.Contains(t, "BIG") || t == "BIGSERIAL" {
+		if strings.Contains(t, "BIG") || t == "SERIAL8" || t == "INT8" {
 			baseType = "BIGINT"
+		} else if strings.Contains(t, "SMALL") || t == "SERIAL2" || t == "INT2" {
+			baseType = "SMALLINT"
 		}
 		return baseType + " IDENTITY(1,1)"
 	}
@@ -326,6 +909,8 @@ func (g *Generator) toSQLServer(t string, isIdentity bool) string {
 		return "INT IDENTITY(1,1)"
 	case t == "BIGSERIAL":
 		return "BIGINT IDENTITY(1,1)"
+	case t == "SMALLSERIAL":
+		return "SMALLINT IDENTITY(1,1)"
 	case t == "INTEGER":
 		return "INT"
 	case t == "BOOLEAN" || t == "BOOL":
@@ -343,3 +928,27 @@ func (g *Generator) toSQLServer(t string, isIdentity bool) string {
 		return t
 	}
 }
+
+// toSQLite maps types SQLite would reject or give the wrong affinity. SQLite
+// accepts any other type name, deriving the column's affinity from it.
+func (g *Generator) toSQLite(t string, isIdentity bool) string {
+	if isIdentity {
+		return "INTEGER"
+	}
+
+	switch {
+	case isSerialType(t):
+		return "INTEGER"
+	case strings.HasSuffix(t, "[]"):
+		// Arrays are stored as JSON text
+		return "TEXT"
+	case t == "BYTEA" || t == "IMAGE" || strings.HasPrefix(t, "VARBINARY") || strings.HasPrefix(t, "BINARY"):
+		return "BLOB"
+	case strings.HasSuffix(t, "(MAX)") || t == "NTEXT":
+		return "TEXT"
+	case t == "UUID" || t == "UNIQUEIDENTIFIER" || t == "JSON" || t == "JSONB" || t == "XML":
+		return "TEXT"
+	default:
+		return t
+	}
+}
@@ -0,0 +1,778 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind identifies the lexical class of a token.
+type TokenKind int
+
+const (
+	// TokenEOF marks the end of input.
+	TokenEOF TokenKind = iota
+	// TokenIdent is a bare identifier or keyword.
+	TokenIdent
+	// TokenQuotedIdent is a delimited identifier ("x", `x` or [x]).
+	TokenQuotedIdent
+	// TokenString is a string literal ('x', E'x', N'x', $$x$$, ...).
+	TokenString
+	// TokenNumber is a numeric literal.
+	TokenNumber
+	// TokenPunct is an operator or punctuation character sequence.
+	TokenPunct
+)
+
+// Position is a location in SQL source text.
+type Position struct {
+	Offset int `json:"offset" yaml:"offset"`
+	Line   int `json:"line" yaml:"line"`
+	Column int `json:"column" yaml:"column"`
+}
+
+// String returns the position as line:column.
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Token is a single lexical token.
+type Token struct {
+	Kind  TokenKind
+	Value string // identifier name or string contents with quoting removed
+	Raw   string // exact source text
+	Pos   Position
+}
+
+// End returns the byte offset just past the token.
+func (t Token) End() int {
+	return t.Pos.Offset + len(t.Raw)
+}
+
+// Is reports whether the token is the given keyword (case-insensitive).
+// Quoted identifiers never match keywords.
+func (t Token) Is(keyword string) bool {
+	return t.Kind == TokenIdent && strings.EqualFold(t.Value, keyword)
+}
+
+// IsPunct reports whether the token is the given punctuation.
+func (t Token) IsPunct(p string) bool {
+	return t.Kind == TokenPunct && t.Value == p
+}
+
+// Lexer splits SQL source into tokens according to dialect quoting rules.
+type Lexer struct {
+	src     string
+	dialect string
+	pos     int
+	line    int
+	col     int
+
+	// inExecComment is set inside a MySQL executable comment (/*! ... */),
+	// whose contents are lexed as SQL
+	inExecComment bool
+}
+
+// NewLexer creates a lexer for the given SQL source and dialect.
+func NewLexer(src, dialect string) *Lexer {
+	return &Lexer{src: src, dialect: dialect, line: 1, col: 1}
+}
+
+// Tokenize returns all tokens in the source, excluding whitespace and
+// comments. The final token is always TokenEOF.
+func (l *Lexer) Tokenize() ([]Token, error) {
+	var tokens []Token
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, tok)
+		if tok.Kind == TokenEOF {
+			return tokens, nil
+		}
+		if tok.IsPunct(";") && l.hasDollarQuotes() && copiesFromStdin(tokens) {
+			l.skipCopyData()
+		}
+	}
+}
+
+// copiesFromStdin reports whether tokens end with a PostgreSQL
+// COPY ... FROM STDIN statement, as written by pg_dump.
+func copiesFromStdin(tokens []Token) bool {
+	fromStdin := false
+	for i := len(tokens) - 2; i >= 0; i-- {
+		switch {
+		case tokens[i].IsPunct(";"):
+			return false
+		case tokens[i].Is("STDIN") && i > 0 && tokens[i-1].Is("FROM"):
+			fromStdin = true
+		case i == 0 || tokens[i-1].IsPunct(";"):
+			return fromStdin && tokens[i].Is("COPY")
+		}
+	}
+	return false
+}
+
+// skipCopyData skips the data rows that follow COPY ... FROM STDIN, up to
+// and including the line holding only \.
+func (l *Lexer) skipCopyData() {
+	for first := true; l.pos < len(l.src); first = false {
+		line := l.src[l.pos:]
+		if nl := strings.IndexByte(line, '\n'); nl >= 0 {
+			line = line[:nl+1]
+		}
+		l.advance(len(line))
+		// The rows start on the line after the statement
+		if !first && strings.TrimRight(line, "\r\n") == `\.` {
+			return
+		}
+	}
+}
+
+// Next returns the next token.
+func (l *Lexer) Next() (Token, error) {
+	if err := l.skipSpaceAndComments(); err != nil {
+		return Token{}, err
+	}
+
+	start := l.position()
+	if l.pos >= len(l.src) {
+		return Token{Kind: TokenEOF, Pos: start}, nil
+	}
+
+	ch := l.src[l.pos]
+	var kind TokenKind
+	var value string
+	var err error
+
+	switch {
+	case l.hasStringPrefix():
+		kind = TokenString
+		value, err = l.scanPrefixedString()
+
+	case ch == '\'':
+		kind = TokenString
+		value, err = l.scanQuoted('\'', l.backslashEscapes())
+
+	case ch == '"':
+		if l.dialect == "mysql" {
+			kind = TokenString
+			value, err = l.scanQuoted('"', true)
+		} else {
+			kind = TokenQuotedIdent
+			value, err = l.scanQuoted('"', false)
+		}
+
+	case ch == '`' && (l.dialect == "mysql" || l.dialect == "sqlite"):
+		kind = TokenQuotedIdent
+		value, err = l.scanQuoted('`', false)
+
+	case ch == '[' && (l.dialect == "sqlserver" || l.dialect == "sqlite"):
+		kind = TokenQuotedIdent
+		value, err = l.scanBracketed()
+
+	case ch == '$' && l.hasDollarQuotes() && l.isDollarQuote():
+		kind = TokenString
+		value, err = l.scanDollarQuoted()
+
+	case isDigit(ch) || (ch == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+		kind = TokenNumber
+		value = l.scanNumber()
+
+	case isIdentStart(l.peekRune()) || (l.dialect == "sqlserver" && (ch == '@' || ch == '#')):
+		kind = TokenIdent
+		value = l.scanIdent()
+
+	default:
+		kind = TokenPunct
+		value = l.scanPunct()
+	}
+
+	if err != nil {
+		return Token{}, err
+	}
+
+	return Token{
+		Kind:  kind,
+		Value: value,
+		Raw:   l.src[start.Offset:l.pos],
+		Pos:   start,
+	}, nil
+}
+
+func (l *Lexer) position() Position {
+	return Position{Offset: l.pos, Line: l.line, Column: l.col}
+}
+
+func (l *Lexer) advance(n int) {
+	for i := 0; i < n && l.pos < len(l.src); i++ {
+		if l.src[l.pos] == '\n' {
+			l.line++
+			l.col = 1
+		} else if l.src[l.pos]&0xC0 != 0x80 {
+			// Count columns in runes, not bytes
+			l.col++
+		}
+		l.pos++
+	}
+}
+
+func (l *Lexer) peekRune() rune {
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return r
+}
+
+func (l *Lexer) hasPrefix(s string) bool {
+	return strings.HasPrefix(l.src[l.pos:], s)
+}
+
+func (l *Lexer) errorf(pos Position, format string, args ...interface{}) error {
+	return newParseError(pos, fmt.Sprintf(format, args...))
+}
+
+// hasDollarQuotes reports whether the dialect has PostgreSQL's
+// dollar-quoted and E'...' strings.
+func (l *Lexer) hasDollarQuotes() bool {
+	return l.dialect != "mysql" && l.dialect != "sqlserver" && l.dialect != "sqlite"
+}
+
+func (l *Lexer) backslashEscapes() bool {
+	return l.dialect == "mysql"
+}
+
+func (l *Lexer) skipSpaceAndComments() error {
+	for l.pos < len(l.src) {
+		ch := l.src[l.pos]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f' || ch == '\v':
+			l.advance(1)
+
+		case l.hasPrefix("--") && l.isLineComment():
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.advance(1)
+			}
+
+		case ch == '#' && l.dialect == "mysql":
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.advance(1)
+			}
+
+		case l.dialect == "mysql" && l.hasPrefix("/*!"):
+			// mysqldump wraps statements in /*!50003 ... */; MySQL runs
+			// them, so only the markers and version number are skipped
+			l.advance(3)
+			for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+				l.advance(1)
+			}
+			l.inExecComment = true
+
+		case l.inExecComment && l.hasPrefix("*/"):
+			l.advance(2)
+			l.inExecComment = false
+
+		case l.hasPrefix("/*"):
+			if err := l.skipBlockComment(); err != nil {
+				return err
+			}
+
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// isLineComment reports whether "--" at the current position starts a
+// comment. MySQL requires whitespace after the dashes.
+func (l *Lexer) isLineComment() bool {
+	if l.dialect != "mysql" {
+		return true
+	}
+	if l.pos+2 >= len(l.src) {
+		return true
+	}
+	next := l.src[l.pos+2]
+	return next == ' ' || next == '\t' || next == '\n' || next == '\r'
+}
+
+func (l *Lexer) skipBlockComment() error {
+	start := l.position()
+	// MySQL and SQLite block comments do not nest; Postgres and SQL
+	// Server ones do
+	nested := l.dialect != "mysql" && l.dialect != "sqlite"
+	depth := 0
+	for l.pos < len(l.src) {
+		switch {
+		case l.hasPrefix("/*") && (depth == 0 || nested):
+			depth++
+			l.advance(2)
+		case l.hasPrefix("*/"):
+			depth--
+			l.advance(2)
+			if depth == 0 {
+				return nil
+			}
+		default:
+			l.advance(1)
+		}
+	}
+	return l.errorf(start, "unterminated block comment")
+}
+
+// hasStringPrefix reports whether the current position starts a prefixed
+// string literal such as E'...', N'...', X'...' or B'...'.
+func (l *Lexer) hasStringPrefix() bool {
+	if l.pos+1 >= len(l.src) || l.src[l.pos+1] != '\'' {
+		return false
+	}
+	switch l.src[l.pos] {
+	case 'E', 'e':
+		return l.hasDollarQuotes()
+	case 'N', 'n', 'X', 'x', 'B', 'b':
+		return true
+	}
+	return false
+}
+
+func (l *Lexer) scanPrefixedString() (string, error) {
+	prefix := l.src[l.pos]
+	l.advance(1)
+	escapes := l.backslashEscapes() || prefix == 'E' || prefix == 'e'
+	return l.scanQuoted('\'', escapes)
+}
+
+// scanQuoted scans a literal delimited by quote, where a doubled quote
+// stands for a single one.
+func (l *Lexer) scanQuoted(quote byte, backslash bool) (string, error) {
+	start := l.position()
+	var sb strings.Builder
+	l.advance(1)
+
+	for l.pos < len(l.src) {
+		ch := l.src[l.pos]
+		switch {
+		case backslash && ch == '\\' && l.pos+1 < len(l.src):
+			sb.WriteByte(unescape(l.src[l.pos+1]))
+			l.advance(2)
+		case ch == quote:
+			if l.pos+1 < len(l.src) && l.src[l.pos+1] == quote {
+				sb.WriteByte(quote)
+				l.advance(2)
+				continue
+			}
+			l.advance(1)
+			return sb.String(), nil
+		default:
+			sb.WriteByte(ch)
+			l.advance(1)
+		}
+	}
+
+	return "", l.errorf(start, "unterminated quoted literal")
+}
+
+func unescape(ch byte) byte {
+	switch ch {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	default:
+		return ch
+	}
+}
+
+func (l *Lexer) scanBracketed() (string, error) {
+	start := l.position()
+	var sb strings.Builder
+	l.advance(1)
+
+	for l.pos < len(l.src) {
+		ch := l.src[l.pos]
+		if ch == ']' {
+			if l.pos+1 < len(l.src) && l.src[l.pos+1] == ']' {
+				sb.WriteByte(']')
+				l.advance(2)
+				continue
+			}
+			l.advance(1)
+			return sb.String(), nil
+		}
+		sb.WriteByte(ch)
+		l.advance(1)
+	}
+
+	return "", l.errorf(start, "unterminated bracketed identifier")
+}
+
+// isDollarQuote reports whether the current '$' opens a dollar-quoted
+// string ($$ or $tag$) rather than a positional parameter like $1.
+func (l *Lexer) isDollarQuote() bool {
+	return l.dollarTag() != ""
+}
+
+func (l *Lexer) dollarTag() string {
+	rest := l.src[l.pos:]
+	if len(rest) < 2 {
+		return ""
+	}
+	if rest[1] == '$' {
+		return "$$"
+	}
+	if !isIdentStart(rune(rest[1])) {
+		return ""
+	}
+	for i := 2; i < len(rest); i++ {
+		if rest[i] == '$' {
+			return rest[:i+1]
+		}
+		if !isIdentPart(rune(rest[i])) {
+			return ""
+		}
+	}
+	return ""
+}
+
+func (l *Lexer) scanDollarQuoted() (string, error) {
+	start := l.position()
+	tag := l.dollarTag()
+	l.advance(len(tag))
+
+	end := strings.Index(l.src[l.pos:], tag)
+	if end == -1 {
+		l.advance(len(l.src) - l.pos)
+		return "", l.errorf(start, "unterminated dollar-quoted string %s", tag)
+	}
+
+	body := l.src[l.pos : l.pos+end]
+	l.advance(end + len(tag))
+	return body, nil
+}
+
+func (l *Lexer) scanNumber() string {
+	start := l.pos
+	for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.advance(1)
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		next := l.pos + 1
+		if next < len(l.src) && (l.src[next] == '+' || l.src[next] == '-') {
+			next++
+		}
+		if next < len(l.src) && isDigit(l.src[next]) {
+			l.advance(next - l.pos)
+			for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+				l.advance(1)
+			}
+		}
+	}
+	return l.src[start:l.pos]
+}
+
+func (l *Lexer) scanIdent() string {
+	start := l.pos
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !isIdentPart(r) && !l.isDialectIdentPart(r) {
+			break
+		}
+		l.advance(size)
+	}
+	return l.src[start:l.pos]
+}
+
+// multiCharPunct lists operators scanned as a single token.
+var multiCharPunct = []string{"::", "<=", ">=", "<>", "!=", "||", "=>", "->>", "->", ":="}
+
+func (l *Lexer) scanPunct() string {
+	for _, op := range multiCharPunct {
+		if l.hasPrefix(op) {
+			l.advance(len(op))
+			return op
+		}
+	}
+	_, size := utf8.DecodeRuneInString(l.src[l.pos:])
+	start := l.pos
+	l.advance(size)
+	return l.src[start:l.pos]
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// isDialectIdentPart reports whether r may continue an identifier in the
+// lexer's dialect in addition to letters, digits and underscores.
+func (l *Lexer) isDialectIdentPart(r rune) bool {
+	switch l.dialect {
+	case "sqlserver":
+		return r == '@' || r == '#' || r == '$'
+	default:
+		return r == '$'
+	}
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// tokenStream is a cursor over the tokens of a single statement or clause.
+type tokenStream struct {
+	tokens []Token
+	pos    int
+}
+
+func newTokenStream(tokens []Token) *tokenStream {
+	return &tokenStream{tokens: tokens}
+}
+
+// peek returns the current token, or an EOF token past the end.
+func (ts *tokenStream) peek() Token {
+	return ts.peekN(0)
+}
+
+// peekN returns the token n positions ahead of the current one.
+func (ts *tokenStream) peekN(n int) Token {
+	if ts.pos+n < len(ts.tokens) {
+		return ts.tokens[ts.pos+n]
+	}
+	eof := Token{Kind: TokenEOF}
+	if len(ts.tokens) > 0 {
+		last := ts.tokens[len(ts.tokens)-1]
+		eof.Pos = last.Pos
+		eof.Pos.Offset = last.End()
+		eof.Pos.Column += len(last.Raw)
+	}
+	return eof
+}
+
+func (ts *tokenStream) next() Token {
+	tok := ts.peek()
+	if ts.pos < len(ts.tokens) {
+		ts.pos++
+	}
+	return tok
+}
+
+func (ts *tokenStream) atEnd() bool {
+	return ts.pos >= len(ts.tokens)
+}
+
+// rest returns the unconsumed tokens and moves the cursor to the end.
+func (ts *tokenStream) rest() []Token {
+	rest := ts.tokens[ts.pos:]
+	ts.pos = len(ts.tokens)
+	return rest
+}
+
+// accept consumes the given keyword sequence if it appears next in full.
+func (ts *tokenStream) accept(keywords ...string) bool {
+	for i, kw := range keywords {
+		if !ts.peekN(i).Is(kw) {
+			return false
+		}
+	}
+	ts.pos += len(keywords)
+	return true
+}
+
+// acceptAny consumes and returns the next token if it is one of the keywords.
+func (ts *tokenStream) acceptAny(keywords ...string) (string, bool) {
+	tok := ts.peek()
+	for _, kw := range keywords {
+		if tok.Is(kw) {
+			ts.next()
+			return strings.ToUpper(tok.Value), true
+		}
+	}
+	return "", false
+}
+
+func (ts *tokenStream) acceptPunct(p string) bool {
+	if ts.peek().IsPunct(p) {
+		ts.next()
+		return true
+	}
+	return false
+}
+
+func (ts *tokenStream) expect(keywords ...string) error {
+	for _, kw := range keywords {
+		tok := ts.peek()
+		if !tok.Is(kw) {
+			return ts.errorf(tok, "expected %s", kw)
+		}
+		ts.next()
+	}
+	return nil
+}
+
+func (ts *tokenStream) expectPunct(p string) error {
+	tok := ts.peek()
+	if !tok.IsPunct(p) {
+		return ts.errorf(tok, "expected %q", p)
+	}
+	ts.next()
+	return nil
+}
+
+func (ts *tokenStream) errorf(tok Token, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if tok.Kind == TokenEOF {
+		return newParseError(tok.Pos, msg+", found end of statement")
+	}
+	return newParseError(tok.Pos, fmt.Sprintf("%s, found %q", msg, tok.Raw))
+}
+
+// errorAt returns an error located at tok without quoting it.
+func (ts *tokenStream) errorAt(tok Token, format string, args ...interface{}) error {
+	return newParseError(tok.Pos, fmt.Sprintf(format, args...))
+}
+
+// parseIdent consumes a bare or quoted identifier.
+func (ts *tokenStream) parseIdent() (string, error) {
+	tok := ts.peek()
+	if tok.Kind != TokenIdent && tok.Kind != TokenQuotedIdent {
+		return "", ts.errorf(tok, "expected identifier")
+	}
+	ts.next()
+	return tok.Value, nil
+}
+
+// parseQualifiedName consumes a dotted name and returns its schema and
+// object parts. Database prefixes (db.schema.name) are discarded.
+func (ts *tokenStream) parseQualifiedName() (string, string, error) {
+	parts := []string{}
+	for {
+		part, err := ts.parseIdent()
+		if err != nil {
+			return "", "", err
+		}
+		parts = append(parts, part)
+		if !ts.acceptPunct(".") {
+			break
+		}
+	}
+
+	name := parts[len(parts)-1]
+	schemaName := ""
+	if len(parts) > 1 {
+		schemaName = parts[len(parts)-2]
+	}
+	return schemaName, name, nil
+}
+
+// parseParenGroups consumes a parenthesized, comma-separated list and
+// returns the tokens of each element.
+func (ts *tokenStream) parseParenGroups() ([][]Token, error) {
+	open := ts.peek()
+	if err := ts.expectPunct("("); err != nil {
+		return nil, err
+	}
+
+	var groups [][]Token
+	var current []Token
+	depth := 0
+
+	for !ts.atEnd() {
+		tok := ts.next()
+		switch {
+		case tok.IsPunct("(") || tok.IsPunct("["):
+			depth++
+		case tok.IsPunct(")") && depth == 0:
+			if len(current) > 0 || len(groups) > 0 {
+				groups = append(groups, current)
+			}
+			return groups, nil
+		case tok.IsPunct(")") || tok.IsPunct("]"):
+			depth--
+		case tok.IsPunct(",") && depth == 0:
+			groups = append(groups, current)
+			current = nil
+			continue
+		}
+		current = append(current, tok)
+	}
+
+	return nil, ts.errorf(open, "unbalanced parentheses")
+}
+
+// parseIdentList consumes a parenthesized list of identifiers.
+func (ts *tokenStream) parseIdentList() ([]string, error) {
+	groups, err := ts.parseParenGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		sub := newTokenStream(g)
+		name, err := sub.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// parseExpr consumes an expression up to a top-level comma or closing
+// parenthesis, or until stop reports true for a top-level token.
+func (ts *tokenStream) parseExpr(stop func(ts *tokenStream) bool) []Token {
+	var expr []Token
+	depth := 0
+
+	for !ts.atEnd() {
+		tok := ts.peek()
+		if depth == 0 {
+			if tok.IsPunct(",") || tok.IsPunct(")") {
+				break
+			}
+			if len(expr) > 0 && stop != nil && stop(ts) {
+				break
+			}
+		}
+		switch {
+		case tok.IsPunct("(") || tok.IsPunct("["):
+			depth++
+		case tok.IsPunct(")") || tok.IsPunct("]"):
+			depth--
+		}
+		expr = append(expr, ts.next())
+	}
+
+	return expr
+}
+
+// skipElement consumes a single token, or a whole parenthesized group.
+func (ts *tokenStream) skipElement() {
+	if ts.peek().IsPunct("(") {
+		if _, err := ts.parseParenGroups(); err == nil {
+			return
+		}
+	}
+	ts.next()
+}
+
+// joinTokens renders tokens back to SQL, collapsing any whitespace or
+// comments between them to a single space.
+func joinTokens(tokens []Token) string {
+	var sb strings.Builder
+	for i, tok := range tokens {
+		if i > 0 && tok.Pos.Offset > tokens[i-1].End() {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(tok.Raw)
+	}
+	return sb.String()
+}
@@ -0,0 +1,147 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ParseDir replays a directory of sequential migration files and returns
+// the resulting schema.
+func ParseDir(dir string, dialect string) (*Schema, error) {
+	s, _, err := ParseDirWithOptions(dir, dialect, ParseOptions{})
+	return s, err
+}
+
+// ParseDirWithOptions replays the migration files in dir in order, applying
+// each file's CREATE, ALTER, DROP and RENAME statements to the schema built
+// by the files before it.
+//
+// Files are ordered by their version prefix (0001_init.up.sql,
+// V2__add_posts.sql, 20240101120000_users.sql). Down migrations
+// (*.down.sql) are skipped.
+func ParseDirWithOptions(dir string, dialect string, opts ParseOptions) (*Schema, []*ParseError, error) {
+	files, err := migrationFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("no .sql migration files found in %s", dir)
+	}
+
+	s := &Schema{
+		Tables:  []Table{},
+		Indexes: []Index{},
+		Views:   []View{},
+	}
+	parser := NewParserWithOptions(dialect, opts)
+	var diagnostics []*ParseError
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading file: %w", err)
+		}
+
+		parser.opts.File = file
+		parser.diagnostics = nil
+		if err := parser.parseInto(s, string(content)); err != nil {
+			return nil, nil, err
+		}
+		diagnostics = append(diagnostics, parser.Diagnostics()...)
+	}
+	s.CollapseSerials()
+
+	return s, diagnostics, nil
+}
+
+// ParsePathWithOptions parses a SQL file or a directory of migration files.
+func ParsePathWithOptions(path string, dialect string, opts ParseOptions) (*Schema, []*ParseError, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading file: %w", err)
+	}
+	if info.IsDir() {
+		return ParseDirWithOptions(path, dialect, opts)
+	}
+	return ParseFileWithOptions(path, dialect, opts)
+}
+
+// migrationFiles returns the up-migration files in dir in version order.
+func migrationFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading directory: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		lower := strings.ToLower(name)
+		if e.IsDir() || !strings.HasSuffix(lower, ".sql") {
+			continue
+		}
+		if strings.HasSuffix(lower, ".down.sql") || strings.HasSuffix(lower, "_down.sql") {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	sort.SliceStable(names, func(i, j int) bool {
+		return naturalLess(names[i], names[j])
+	})
+
+	files := make([]string, len(names))
+	for i, name := range names {
+		files[i] = filepath.Join(dir, name)
+	}
+	return files, nil
+}
+
+// naturalLess compares file names so that embedded numbers sort by value:
+// 2_b.sql before 10_a.sql, and V9__x.sql before V10__y.sql.
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		ra, rb := a[0], b[0]
+		if isDigit(ra) && isDigit(rb) {
+			na, restA := leadingNumber(a)
+			nb, restB := leadingNumber(b)
+			if na != nb {
+				// Compare by length first so leading zeros and long
+				// timestamps need no integer conversion
+				ta, tb := strings.TrimLeft(na, "0"), strings.TrimLeft(nb, "0")
+				if len(ta) != len(tb) {
+					return len(ta) < len(tb)
+				}
+				if ta != tb {
+					return ta < tb
+				}
+			}
+			a, b = restA, restB
+			continue
+		}
+		la, lb := lowerASCII(ra), lowerASCII(rb)
+		if la != lb {
+			return la < lb
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func leadingNumber(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func lowerASCII(ch byte) byte {
+	if ch >= 'A' && ch <= 'Z' {
+		return ch + 'a' - 'A'
+	}
+	return ch
+}
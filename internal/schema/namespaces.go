@@ -0,0 +1,356 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSchema returns the schema unqualified names resolve to in a
+// dialect: public for PostgreSQL, dbo for SQL Server and main for SQLite.
+// MySQL has none, as its schemas are databases.
+func DefaultSchema(dialect string) string {
+	switch dialect {
+	case "postgres":
+		return "public"
+	case "sqlserver":
+		return "dbo"
+	case "sqlite":
+		return "main"
+	default:
+		return ""
+	}
+}
+
+// parseCreateSchema handles
+//
+//	CREATE SCHEMA [IF NOT EXISTS] {name [AUTHORIZATION role] | AUTHORIZATION role}
+//
+// Objects created as part of the statement are not modeled.
+func (p *Parser) parseCreateSchema(s *Schema, ts *tokenStream) error {
+	ts.accept("IF", "NOT", "EXISTS")
+
+	// CREATE SCHEMA AUTHORIZATION role names the schema after the role
+	ts.accept("AUTHORIZATION")
+	name, err := ts.parseIdent()
+	if err != nil {
+		return err
+	}
+	p.addSchema(s, name)
+	return nil
+}
+
+// parseDropSchema handles DROP SCHEMA [IF EXISTS] name [, ...] [CASCADE],
+// dropping the objects in the schema along with it.
+func (p *Parser) parseDropSchema(s *Schema, ts *tokenStream) error {
+	ts.accept("IF", "EXISTS")
+	for {
+		name, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		s.dropNamespace(name, DefaultSchema(p.dialect))
+		if !ts.acceptPunct(",") {
+			return nil
+		}
+	}
+}
+
+// parseAlterSchema handles PostgreSQL's ALTER SCHEMA name RENAME TO name
+// and SQL Server's ALTER SCHEMA name TRANSFER [class::]object.
+func (p *Parser) parseAlterSchema(s *Schema, ts *tokenStream) error {
+	nameTok := ts.peek()
+	name, err := ts.parseIdent()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case ts.accept("RENAME", "TO"):
+		newName, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		if !containsName(s.Schemas, name) {
+			return ts.errorAt(nameTok, "ALTER SCHEMA of undefined schema %q", name)
+		}
+		defaultSchema := DefaultSchema(p.dialect)
+		s.mapNamespaces(func(ns string) string {
+			if strings.EqualFold(namespace(ns, defaultSchema), name) {
+				return newName
+			}
+			return ns
+		})
+
+	case ts.accept("TRANSFER"):
+		if ts.peekN(1).IsPunct("::") {
+			// OBJECT:: or TYPE::
+			ts.next()
+			ts.next()
+		}
+		objTok := ts.peek()
+		objSchema, objName, err := ts.parseQualifiedName()
+		if err != nil {
+			return err
+		}
+		if !s.transferObject(objSchema, objName, name) {
+			return ts.errorAt(objTok, "ALTER SCHEMA TRANSFER of undefined object %q", objName)
+		}
+	}
+	return nil
+}
+
+// addSchema records a schema unless it is the dialect's default schema,
+// which always exists.
+func (p *Parser) addSchema(s *Schema, name string) {
+	if strings.EqualFold(name, DefaultSchema(p.dialect)) || containsName(s.Schemas, name) {
+		return
+	}
+	s.Schemas = append(s.Schemas, name)
+}
+
+// transferObject moves a table, view, sequence or routine to another
+// schema.
+func (s *Schema) transferObject(schemaName, name, newSchema string) bool {
+	if table := s.table(schemaName, name); table != nil {
+		s.moveTable(table, newSchema, table.Name)
+		return true
+	}
+	if view := s.view(schemaName, name); view != nil {
+		view.Schema = newSchema
+		return true
+	}
+	if seq := s.sequence(schemaName, name); seq != nil {
+		seq.Schema = newSchema
+		return true
+	}
+	moved := false
+	for i := range s.Routines {
+		r := &s.Routines[i]
+		if sameObject(schemaName, name, r.Schema, r.Name) {
+			r.Schema = newSchema
+			moved = true
+		}
+	}
+	return moved
+}
+
+// dropNamespace removes a schema and every object in it.
+func (s *Schema) dropNamespace(name, defaultSchema string) {
+	*s = *s.FilterSchemas(nil, []string{name}, defaultSchema)
+}
+
+// namespace returns the schema an object belongs to, resolving an empty
+// schema name to the default schema.
+func namespace(schemaName, defaultSchema string) string {
+	if schemaName == "" {
+		return defaultSchema
+	}
+	return schemaName
+}
+
+// FilterSchemas returns a copy of s holding only the objects whose schema
+// is in include, when include is not empty, and not in exclude. Objects
+// with no schema belong to defaultSchema.
+func (s *Schema) FilterSchemas(include, exclude []string, defaultSchema string) *Schema {
+	keep := func(schemaName string) bool {
+		ns := namespace(schemaName, defaultSchema)
+		if len(include) > 0 && !containsName(include, ns) {
+			return false
+		}
+		return !containsName(exclude, ns)
+	}
+
+	filtered := &Schema{}
+	for _, name := range s.Schemas {
+		if keep(name) {
+			filtered.Schemas = append(filtered.Schemas, name)
+		}
+	}
+	for _, t := range s.Tables {
+		if keep(t.Schema) {
+			filtered.Tables = append(filtered.Tables, t)
+		}
+	}
+	for _, idx := range s.Indexes {
+		if keep(idx.Schema) {
+			filtered.Indexes = append(filtered.Indexes, idx)
+		}
+	}
+	for _, v := range s.Views {
+		if keep(v.Schema) {
+			filtered.Views = append(filtered.Views, v)
+		}
+	}
+	for _, e := range s.Enums {
+		if keep(e.Schema) {
+			filtered.Enums = append(filtered.Enums, e)
+		}
+	}
+	for _, d := range s.Domains {
+		if keep(d.Schema) {
+			filtered.Domains = append(filtered.Domains, d)
+		}
+	}
+	for _, seq := range s.Sequences {
+		if keep(seq.Schema) {
+			filtered.Sequences = append(filtered.Sequences, seq)
+		}
+	}
+	for _, r := range s.Routines {
+		if keep(r.Schema) {
+			filtered.Routines = append(filtered.Routines, r)
+		}
+	}
+	for _, t := range s.Triggers {
+		if keep(t.Schema) {
+			filtered.Triggers = append(filtered.Triggers, t)
+		}
+	}
+	return filtered
+}
+
+// MapSchemas returns a copy of s with objects moved between schemas as
+// mapping (old name to new name) says, including the schemas foreign keys
+// and column types refer to. Objects with no schema belong to
+// defaultSchema.
+func (s *Schema) MapSchemas(mapping map[string]string, defaultSchema string) *Schema {
+	mapped := s.clone()
+	mapped.mapNamespaces(func(ns string) string {
+		for from, to := range mapping {
+			if strings.EqualFold(namespace(ns, defaultSchema), from) {
+				return to
+			}
+		}
+		return ns
+	})
+
+	// Mapping a schema onto an existing one merges them
+	var schemas []string
+	for _, name := range mapped.Schemas {
+		if !containsName(schemas, name) && !strings.EqualFold(name, defaultSchema) {
+			schemas = append(schemas, name)
+		}
+	}
+	mapped.Schemas = schemas
+	return mapped
+}
+
+// mapNamespaces replaces every schema name in s with fn(name).
+func (s *Schema) mapNamespaces(fn func(string) string) {
+	for i, name := range s.Schemas {
+		s.Schemas[i] = fn(name)
+	}
+	for i := range s.Tables {
+		t := &s.Tables[i]
+		t.Schema = fn(t.Schema)
+		for j := range t.Columns {
+			t.Columns[j].Type = mapTypeNamespace(t.Columns[j].Type, fn)
+		}
+		for j := range t.ForeignKeys {
+			t.ForeignKeys[j].ReferencedSchema = fn(t.ForeignKeys[j].ReferencedSchema)
+		}
+		for j := range t.Indexes {
+			t.Indexes[j].Schema = fn(t.Indexes[j].Schema)
+		}
+	}
+	for i := range s.Indexes {
+		s.Indexes[i].Schema = fn(s.Indexes[i].Schema)
+	}
+	for i := range s.Views {
+		s.Views[i].Schema = fn(s.Views[i].Schema)
+	}
+	for i := range s.Enums {
+		s.Enums[i].Schema = fn(s.Enums[i].Schema)
+	}
+	for i := range s.Domains {
+		d := &s.Domains[i]
+		d.Schema = fn(d.Schema)
+		d.Type = mapTypeNamespace(d.Type, fn)
+	}
+	for i := range s.Sequences {
+		s.Sequences[i].Schema = fn(s.Sequences[i].Schema)
+	}
+	for i := range s.Routines {
+		s.Routines[i].Schema = fn(s.Routines[i].Schema)
+	}
+	for i := range s.Triggers {
+		s.Triggers[i].Schema = fn(s.Triggers[i].Schema)
+	}
+}
+
+// mapTypeNamespace applies fn to the schema of a schema-qualified type
+// name, such as an enum or domain in another schema.
+func mapTypeNamespace(t string, fn func(string) string) string {
+	dot := strings.Index(t, ".")
+	if dot < 0 || strings.ContainsAny(t[:dot], "( ") {
+		return t
+	}
+	schemaName := strings.Trim(t[:dot], `"`)
+	return fn(schemaName) + t[dot:]
+}
+
+// clone copies the object lists of s, and the per-table and per-index
+// lists that mapNamespaces and renameColumn rewrite, so the copy can be
+// rewritten without changing s.
+func (s *Schema) clone() *Schema {
+	c := &Schema{
+		Schemas:   append([]string(nil), s.Schemas...),
+		Tables:    append([]Table(nil), s.Tables...),
+		Indexes:   append([]Index(nil), s.Indexes...),
+		Views:     append([]View(nil), s.Views...),
+		Enums:     append([]Enum(nil), s.Enums...),
+		Domains:   append([]Domain(nil), s.Domains...),
+		Sequences: append([]Sequence(nil), s.Sequences...),
+		Routines:  append([]Routine(nil), s.Routines...),
+		Triggers:  append([]Trigger(nil), s.Triggers...),
+	}
+	for i := range c.Tables {
+		t := &c.Tables[i]
+		t.Columns = append([]Column(nil), t.Columns...)
+		t.ForeignKeys = append([]ForeignKey(nil), t.ForeignKeys...)
+		t.Indexes = append([]Index(nil), t.Indexes...)
+		t.Constraints = append([]Constraint(nil), t.Constraints...)
+		if t.PrimaryKey != nil {
+			pk := *t.PrimaryKey
+			pk.Columns = append([]string(nil), pk.Columns...)
+			t.PrimaryKey = &pk
+		}
+		for j := range t.ForeignKeys {
+			fk := &t.ForeignKeys[j]
+			fk.Columns = append([]string(nil), fk.Columns...)
+			fk.ReferencedCols = append([]string(nil), fk.ReferencedCols...)
+		}
+		for j := range t.Constraints {
+			t.Constraints[j].Columns = append([]string(nil), t.Constraints[j].Columns...)
+		}
+		for j := range t.Indexes {
+			t.Indexes[j].copyColumns()
+		}
+	}
+	for i := range c.Indexes {
+		c.Indexes[i].copyColumns()
+	}
+	return c
+}
+
+// copyColumns gives the index column lists of its own.
+func (idx *Index) copyColumns() {
+	idx.Columns = append([]string(nil), idx.Columns...)
+	idx.Keys = append([]IndexColumn(nil), idx.Keys...)
+	idx.Include = append([]string(nil), idx.Include...)
+}
+
+// GenerateCreateSchema returns the CREATE SCHEMA statement for a schema.
+// SQL Server runs it in a batch of its own.
+func (g *Generator) GenerateCreateSchema(name string) string {
+	stmt := "CREATE SCHEMA " + g.quoteName(name)
+	if g.dialect == "sqlserver" {
+		return g.wrapBody(stmt, "")
+	}
+	return stmt + ";"
+}
+
+// GenerateDropSchema returns the DROP SCHEMA statement for a schema.
+func (g *Generator) GenerateDropSchema(name string) string {
+	return fmt.Sprintf("DROP SCHEMA IF EXISTS %s;", g.quoteName(name))
+}
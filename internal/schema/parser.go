@@ -1,18 +1,42 @@
 package schema
 
 import (
-	"regexp"
+	"fmt"
+	"strconv"
 	"strings"
 )
 
+// ParseOptions controls how SQL source is parsed.
+type ParseOptions struct {
+	// File is the source path reported in parse errors.
+	File string
+	// Strict fails on the first statement that cannot be parsed instead of
+	// recording a diagnostic and moving on to the next one.
+	Strict bool
+}
+
 // Parser parses SQL statements into a Schema.
 type Parser struct {
-	dialect string
+	dialect     string
+	opts        ParseOptions
+	src         string
+	diagnostics []*ParseError
 }
 
 // NewParser creates a new SQL parser for the given dialect.
 func NewParser(dialect string) *Parser {
-	return &Parser{dialect: dialect}
+	return NewParserWithOptions(dialect, ParseOptions{})
+}
+
+// NewParserWithOptions creates a new SQL parser with the given options.
+func NewParserWithOptions(dialect string, opts ParseOptions) *Parser {
+	return &Parser{dialect: dialect, opts: opts}
+}
+
+// Diagnostics returns the errors recorded by the last call to Parse in
+// lenient mode.
+func (p *Parser) Diagnostics() []*ParseError {
+	return p.diagnostics
 }
 
 // Parse parses SQL content and returns a Schema.
@@ -22,49 +46,245 @@ func (p *Parser) Parse(sql string) (*Schema, error) {
 		Indexes: []Index{},
 		Views:   []View{},
 	}
+	p.diagnostics = nil
 
-	// Normalize line endings and remove comments
-	sql = normalizeSQL(sql)
+	if err := p.parseInto(schema, sql); err != nil {
+		return nil, err
+	}
+	schema.CollapseSerials()
+	return schema, nil
+}
 
-	// Split into statements
-	statements := splitStatements(sql)
+// parseInto applies the statements in sql to an existing schema, so that
+// later files can alter or drop objects created by earlier ones.
+func (p *Parser) parseInto(s *Schema, sql string) error {
+	p.src = sql
 
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
-		if stmt == "" {
-			continue
+	tokens, err := NewLexer(sql, p.dialect).Tokenize()
+	if err != nil {
+		// The tokens read before the error are still parsed
+		if err := p.report(err, Position{}); err != nil {
+			return err
+		}
+	}
+
+	for _, stmt := range splitStatements(sql, tokens, p.dialect) {
+		if err := p.parseStatement(s, newTokenStream(stmt)); err != nil {
+			if err := p.report(err, stmt[0].Pos); err != nil {
+				return err
+			}
+		}
+	}
+
+	resolveReferencedColumns(s)
+
+	return nil
+}
+
+// resolveReferencedColumns fills in the referenced columns of foreign keys
+// written as "REFERENCES t" with the primary key of t, so that they compare
+// equal to the spelled-out form.
+func resolveReferencedColumns(s *Schema) {
+	for i := range s.Tables {
+		for j := range s.Tables[i].ForeignKeys {
+			fk := &s.Tables[i].ForeignKeys[j]
+			if len(fk.ReferencedCols) > 0 {
+				continue
+			}
+			ref := s.table(fk.ReferencedSchema, fk.ReferencedTable)
+			if ref != nil && ref.PrimaryKey != nil {
+				fk.ReferencedCols = append([]string(nil), ref.PrimaryKey.Columns...)
+			}
 		}
+	}
+}
+
+// report records err as a diagnostic, or returns it in strict mode.
+func (p *Parser) report(err error, pos Position) error {
+	pe := annotate(err, p.opts.File, p.src, pos)
+	if p.opts.Strict {
+		return pe
+	}
+	p.diagnostics = append(p.diagnostics, pe)
+	return nil
+}
+
+func (p *Parser) parseStatement(s *Schema, ts *tokenStream) error {
+	switch {
+	case ts.accept("CREATE"):
+		return p.parseCreate(s, ts)
+	case ts.accept("ALTER", "TABLE"):
+		return p.parseAlterTable(s, ts)
+	case ts.accept("ALTER", "INDEX"):
+		return p.parseAlterIndex(s, ts)
+	case ts.accept("ALTER", "TYPE"):
+		return p.parseAlterType(s, ts)
+	case ts.accept("ALTER", "DOMAIN"):
+		return p.parseAlterDomain(s, ts)
+	case ts.accept("ALTER", "SEQUENCE"):
+		return p.parseAlterSequence(s, ts)
+	case ts.accept("ALTER", "SCHEMA"):
+		return p.parseAlterSchema(s, ts)
+	case ts.accept("DROP"):
+		return p.parseDrop(s, ts)
+	case ts.accept("RENAME", "TABLE"):
+		return p.parseRenameTable(s, ts)
+	case ts.accept("COMMENT", "ON"):
+		return p.parseComment(s, ts)
+	case p.dialect == "sqlserver" && (ts.accept("EXEC") || ts.accept("EXECUTE")):
+		return p.parseExec(s, ts)
+	case ts.accept("ALTER"):
+		// Changes to the options or owners of views and routines are not tracked
+		if _, ok := ts.acceptAny("VIEW", "FUNCTION", "PROCEDURE", "PROC", "TRIGGER"); ok {
+			return nil
+		}
+		return skipObjectKind(ts, "ALTER")
+	case containsKeyword(ignoredStatements, ts.peek()):
+		return nil
+	}
+	return ts.errorAt(ts.peek(), "unrecognized statement %q", ts.peek().Raw)
+}
 
-		upper := strings.ToUpper(stmt)
+// ignoredStatements lists the leading keywords of statements that carry no
+// schema information, such as data changes, session settings, transaction
+// control and privileges.
+var ignoredStatements = []string{
+	"SELECT", "INSERT", "UPDATE", "DELETE", "REPLACE", "MERGE", "WITH", "VALUES",
+	"COPY", "TRUNCATE", "LOAD", "BULK", "SET", "RESET", "USE", "PRAGMA", "ATTACH",
+	"DETACH", "BEGIN", "START", "COMMIT", "ROLLBACK", "END", "ABORT", "SAVEPOINT",
+	"RELEASE", "LOCK", "UNLOCK", "GRANT", "REVOKE", "DENY", "SECURITY", "ANALYZE",
+	"VACUUM", "REINDEX", "CLUSTER", "CHECKPOINT", "REFRESH", "OPTIMIZE", "REPAIR",
+	"FLUSH", "DO", "CALL", "EXEC", "EXECUTE", "PREPARE", "DEALLOCATE", "DECLARE",
+	"IF", "PRINT", "RAISERROR", "THROW", "WAITFOR", "DBCC", "NOTIFY", "LISTEN",
+	"UNLISTEN", "DISCARD", "SHOW", "EXPLAIN", "DESCRIBE",
+}
 
+// ignoredObjectKinds lists the kinds of object that CREATE, ALTER and DROP
+// may name but that are not modeled.
+var ignoredObjectKinds = []string{
+	"DATABASE", "EXTENSION", "MATERIALIZED", "ROLE", "USER", "GROUP", "LOGIN",
+	"TABLESPACE", "RULE", "POLICY", "AGGREGATE", "OPERATOR", "CAST", "COLLATION",
+	"CONVERSION", "EVENT", "PUBLICATION", "SUBSCRIPTION", "SERVER", "FOREIGN",
+	"STATISTICS", "TEXT", "LANGUAGE", "TRUSTED", "PROCEDURAL", "ACCESS", "TRANSFORM",
+	"DEFAULT", "SYSTEM", "VIRTUAL", "SYNONYM", "ASSEMBLY", "PARTITION", "XML",
+	"CATALOG", "MASTER", "CERTIFICATE", "SYMMETRIC", "ASYMMETRIC", "CREDENTIAL",
+	"AUTHORIZATION", "LOGFILE", "RESOURCE", "INSTANCE", "UNDO", "OWNED", "PRIMARY",
+	"COLUMNSTORE", "QUEUE", "SERVICE", "CONTRACT", "ROUTE", "ENDPOINT", "MESSAGE",
+	"REMOTE", "BROKER", "APPLICATION", "SEARCH", "EXTERNAL", "WORKLOAD",
+}
+
+// skipObjectKind accepts a statement on a kind of object that is not
+// modeled and reports any other word, which is more likely a typo.
+func skipObjectKind(ts *tokenStream, verb string) error {
+	tok := ts.peek()
+	if containsKeyword(ignoredObjectKinds, tok) {
+		return nil
+	}
+	return ts.errorf(tok, "expected object type after %s", verb)
+}
+
+func (p *Parser) parseCreate(s *Schema, ts *tokenStream) error {
+	ts.accept("OR", "REPLACE")
+	ts.accept("OR", "ALTER")
+
+	unique := false
+	indexType := ""
+
+	for {
 		switch {
-		case strings.HasPrefix(upper, "CREATE TABLE"):
-			table, err := p.parseCreateTable(stmt)
+		case ts.accept("TABLE"):
+			ifNotExists := ts.peek().Is("IF")
+			table, err := p.parseCreateTable(ts)
 			if err != nil {
-				continue // Skip unparseable statements
+				return err
+			}
+			if existing := s.tableIn(table.Schema, table.Name); existing != nil {
+				if !ifNotExists {
+					*existing = *table
+				}
+				return nil
 			}
-			schema.Tables = append(schema.Tables, *table)
+			s.Tables = append(s.Tables, *table)
+			return nil
 
-		case strings.HasPrefix(upper, "CREATE INDEX") || strings.HasPrefix(upper, "CREATE UNIQUE INDEX"):
-			idx, err := p.parseCreateIndex(stmt)
+		case ts.accept("INDEX"):
+			idx, err := p.parseCreateIndex(ts, unique, indexType)
 			if err != nil {
-				continue
+				return err
 			}
-			schema.Indexes = append(schema.Indexes, *idx)
+			s.Indexes = append(s.Indexes, *idx)
+			return nil
 
-		case strings.HasPrefix(upper, "CREATE VIEW") || strings.HasPrefix(upper, "CREATE OR REPLACE VIEW"):
-			view, err := p.parseCreateView(stmt)
+		case ts.accept("VIEW"):
+			view, err := p.parseCreateView(ts)
 			if err != nil {
-				continue
+				return err
 			}
-			schema.Views = append(schema.Views, *view)
+			if existing := s.viewIn(view.Schema, view.Name); existing != nil {
+				*existing = *view
+				return nil
+			}
+			s.Views = append(s.Views, *view)
+			return nil
+
+		case ts.accept("TYPE"):
+			return p.parseCreateType(s, ts)
+
+		case ts.accept("DOMAIN"):
+			return p.parseCreateDomain(s, ts)
+
+		case ts.accept("SEQUENCE"):
+			return p.parseCreateSequence(s, ts)
+
+		case ts.accept("SCHEMA"):
+			return p.parseCreateSchema(s, ts)
+
+		case ts.accept("FUNCTION"):
+			return p.parseCreateRoutine(s, ts, "FUNCTION", ts.pos-1)
+
+		case ts.accept("PROCEDURE") || ts.accept("PROC"):
+			return p.parseCreateRoutine(s, ts, "PROCEDURE", ts.pos-1)
+
+		case ts.accept("TRIGGER"):
+			return p.parseCreateTrigger(s, ts, ts.pos-1)
+
+		case ts.accept("CONSTRAINT", "TRIGGER"):
+			// PostgreSQL constraint triggers
+			return p.parseCreateTrigger(s, ts, ts.pos-2)
+
+		case ts.accept("UNIQUE"):
+			unique = true
+
+		case ts.peek().Is("CLUSTERED") || ts.peek().Is("NONCLUSTERED") ||
+			ts.peek().Is("FULLTEXT") || ts.peek().Is("SPATIAL"):
+			indexType = strings.ToLower(ts.next().Value)
+
+		case ts.peek().Is("TEMP") || ts.peek().Is("TEMPORARY") || ts.peek().Is("GLOBAL") ||
+			ts.peek().Is("LOCAL") || ts.peek().Is("UNLOGGED") || ts.peek().Is("RECURSIVE"):
+			ts.next()
+
+		case ts.accept("ALGORITHM"):
+			// MySQL view options: ALGORITHM = x, DEFINER = user@host, SQL SECURITY x
+			ts.acceptPunct("=")
+			ts.next()
+
+		case ts.accept("DEFINER"):
+			ts.acceptPunct("=")
+			ts.next()
+			if ts.acceptPunct("@") {
+				ts.next()
+			}
+
+		case ts.accept("SQL", "SECURITY"):
+			ts.next()
+
+		default:
+			return skipObjectKind(ts, "CREATE")
 		}
 	}
-
-	return schema, nil
 }
 
-func (p *Parser) parseCreateTable(stmt string) (*Table, error) {
+func (p *Parser) parseCreateTable(ts *tokenStream) (*Table, error) {
 	table := &Table{
 		Columns:     []Column{},
 		ForeignKeys: []ForeignKey{},
@@ -72,361 +292,1182 @@ func (p *Parser) parseCreateTable(stmt string) (*Table, error) {
 		Constraints: []Constraint{},
 	}
 
-	// Extract table name
-	nameRe := regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:(\w+)\.)?["']?(\w+)["']?`)
-	matches := nameRe.FindStringSubmatch(stmt)
-	if len(matches) >= 3 {
-		table.Schema = matches[1]
-		table.Name = matches[2]
+	ts.accept("IF", "NOT", "EXISTS")
+
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return nil, err
 	}
+	table.Schema = schemaName
+	table.Name = name
 
-	// Extract column definitions (between parentheses)
-	parenStart := strings.Index(stmt, "(")
-	parenEnd := strings.LastIndex(stmt, ")")
-	if parenStart == -1 || parenEnd == -1 {
+	// CREATE TABLE ... AS SELECT, PARTITION OF, etc. have no column list
+	if !ts.peek().IsPunct("(") {
 		return table, nil
 	}
 
-	body := stmt[parenStart+1 : parenEnd]
-	definitions := splitColumnDefs(body)
+	definitions, err := ts.parseParenGroups()
+	if err != nil {
+		return nil, err
+	}
 
 	for _, def := range definitions {
-		def = strings.TrimSpace(def)
-		if def == "" {
+		if len(def) == 0 {
 			continue
 		}
+		if err := p.parseTableElement(newTokenStream(def), table); err != nil {
+			// Keep the rest of the table in lenient mode
+			if err := p.report(err, def[0].Pos); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	table.syncPrimaryKey()
+
+	if err := p.parseTableOptions(ts, table); err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+func (p *Parser) parseTableElement(ts *tokenStream, table *Table) error {
+	tok := ts.peek()
+
+	switch {
+	case tok.Is("CONSTRAINT"):
+		ts.next()
+		name, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		return p.parseTableConstraint(ts, table, name)
 
-		upper := strings.ToUpper(def)
+	case tok.Is("PRIMARY") || tok.Is("FOREIGN") || tok.Is("UNIQUE") || tok.Is("CHECK"):
+		return p.parseTableConstraint(ts, table, "")
+
+	case (tok.Is("KEY") || tok.Is("INDEX")) && (p.dialect == "mysql" || p.dialect == "sqlserver"):
+		idx, err := p.parseInlineIndex(ts, table, "")
+		if err != nil {
+			return err
+		}
+		table.Indexes = append(table.Indexes, *idx)
+		return nil
+
+	case (tok.Is("FULLTEXT") || tok.Is("SPATIAL")) && p.dialect == "mysql":
+		indexType := strings.ToLower(ts.next().Value)
+		idx, err := p.parseInlineIndex(ts, table, indexType)
+		if err != nil {
+			return err
+		}
+		table.Indexes = append(table.Indexes, *idx)
+		return nil
+
+	case tok.Is("EXCLUDE") || tok.Is("LIKE") || tok.Is("PERIOD"):
+		// Not modeled
+		return nil
+
+	default:
+		col, err := p.parseColumnDef(ts, table)
+		if err != nil {
+			return err
+		}
+		table.Columns = append(table.Columns, *col)
+		return nil
+	}
+}
+
+func (p *Parser) parseTableConstraint(ts *tokenStream, table *Table, name string) error {
+	switch {
+	case ts.accept("PRIMARY", "KEY"):
+		pk, err := p.parsePrimaryKeyConstraint(ts)
+		if err != nil {
+			return err
+		}
+		pk.Name = name
+		table.PrimaryKey = pk
+
+	case ts.accept("FOREIGN", "KEY"):
+		fk, err := p.parseForeignKeyConstraint(ts)
+		if err != nil {
+			return err
+		}
+		fk.Name = name
+		table.ForeignKeys = append(table.ForeignKeys, *fk)
+
+	case ts.accept("UNIQUE"):
+		constraint, err := p.parseUniqueConstraint(ts)
+		if err != nil {
+			return err
+		}
+		if constraint.Name == "" {
+			constraint.Name = name
+		}
+		table.Constraints = append(table.Constraints, *constraint)
+
+	case ts.accept("CHECK"):
+		constraint, err := p.parseCheckConstraint(ts)
+		if err != nil {
+			return err
+		}
+		constraint.Name = name
+		table.Constraints = append(table.Constraints, *constraint)
+
+	default:
+		return ts.errorf(ts.peek(), "expected PRIMARY KEY, FOREIGN KEY, UNIQUE or CHECK")
+	}
+
+	return nil
+}
+
+// parseColumnDef parses a column definition. Column constraints other than
+// NOT NULL, DEFAULT and UNIQUE are added to table.
+func (p *Parser) parseColumnDef(ts *tokenStream, table *Table) (*Column, error) {
+	name, err := ts.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	col := Column{
+		Name:     name,
+		Nullable: true,
+	}
+
+	switch {
+	case ts.accept("AS"):
+		// SQL Server computed columns have no type: name AS expr [PERSISTED]
+		if err := p.parseGeneratedColumn(ts, &col); err != nil {
+			return nil, err
+		}
+	case p.dialect == "sqlite" && (ts.atEnd() || isColumnConstraintStart(ts)):
+		// SQLite columns may be declared without a type
+	default:
+		if col.Type, err = p.parseDataType(ts); err != nil {
+			return nil, err
+		}
+	}
+	if isSerialType(col.Type) {
+		// A serial column is NOT NULL as well as drawing from its sequence
+		col.IsIdentity = true
+		col.Nullable = false
+	}
+	col.EnumValues = InlineEnumValues(col.Type)
+
+	constraintName := ""
+	for !ts.atEnd() {
+		if ts.accept("CONSTRAINT") {
+			constraintName, err = ts.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
 
 		switch {
-		case strings.HasPrefix(upper, "PRIMARY KEY"):
-			pk := p.parsePrimaryKeyConstraint(def)
-			table.PrimaryKey = pk
-
-		case strings.HasPrefix(upper, "FOREIGN KEY"):
-			fk := p.parseForeignKeyConstraint(def)
-			if fk != nil {
-				table.ForeignKeys = append(table.ForeignKeys, *fk)
+		case ts.accept("NOT", "NULL"):
+			col.Nullable = false
+
+		case ts.accept("NULL"):
+			col.Nullable = true
+
+		case ts.accept("PRIMARY", "KEY"):
+			col.IsPrimaryKey = true
+			col.Nullable = false
+			ts.acceptAny("ASC", "DESC")
+			ts.acceptAny("CLUSTERED", "NONCLUSTERED")
+			table.PrimaryKey = &PrimaryKey{Name: constraintName, Columns: []string{name}}
+
+		case ts.accept("UNIQUE"):
+			ts.accept("KEY")
+			ts.acceptAny("CLUSTERED", "NONCLUSTERED")
+			col.IsUnique = true
+
+		case ts.accept("DEFAULT"):
+			defaultVal := joinTokens(ts.parseExpr(isColumnConstraintStart))
+			col.Default = &defaultVal
+			col.DefaultName = constraintName
+
+		case ts.accept("CHECK"):
+			constraint, err := p.parseCheckConstraint(ts)
+			if err != nil {
+				return nil, err
 			}
+			constraint.Name = constraintName
+			constraint.Columns = []string{name}
+			table.Constraints = append(table.Constraints, *constraint)
 
-		case strings.HasPrefix(upper, "UNIQUE"):
-			// Handle UNIQUE constraint
-			constraint := p.parseUniqueConstraint(def)
-			if constraint != nil {
-				table.Constraints = append(table.Constraints, *constraint)
+		case ts.accept("REFERENCES"):
+			fk, err := p.parseReferences(ts)
+			if err != nil {
+				return nil, err
 			}
+			fk.Name = constraintName
+			fk.Columns = []string{name}
+			table.ForeignKeys = append(table.ForeignKeys, *fk)
 
-		case strings.HasPrefix(upper, "CHECK"):
-			// Handle CHECK constraint
-			constraint := p.parseCheckConstraint(def)
-			if constraint != nil {
-				table.Constraints = append(table.Constraints, *constraint)
+		case ts.accept("AUTO_INCREMENT") || ts.accept("AUTOINCREMENT"):
+			col.IsIdentity = true
+
+		case ts.accept("IDENTITY"):
+			// SQL Server: IDENTITY[(seed, increment)]
+			col.IsIdentity = true
+			col.Identity = &Identity{}
+			if ts.acceptPunct("(") {
+				if col.Identity.Start, err = parseSignedInt(ts); err != nil {
+					return nil, err
+				}
+				if err := ts.expectPunct(","); err != nil {
+					return nil, err
+				}
+				if col.Identity.Increment, err = parseSignedInt(ts); err != nil {
+					return nil, err
+				}
+				if err := ts.expectPunct(")"); err != nil {
+					return nil, err
+				}
+				col.Identity.clearDefaults()
 			}
 
-		case strings.HasPrefix(upper, "CONSTRAINT"):
-			// Named constraint - could be PK, FK, UNIQUE, or CHECK
-			p.parseNamedConstraint(def, table)
+		case ts.accept("GENERATED"):
+			generation := parseIdentityGeneration(ts)
+			switch {
+			case ts.accept("AS", "IDENTITY"):
+				// Identity columns are implicitly NOT NULL
+				col.IsIdentity = true
+				col.Nullable = false
+				col.Identity = &Identity{Generation: generation}
+				if err := p.parseIdentityOptions(ts, col.Identity); err != nil {
+					return nil, err
+				}
+			case ts.accept("AS"):
+				if err := p.parseGeneratedColumn(ts, &col); err != nil {
+					return nil, err
+				}
+			}
+
+		case ts.peek().Is("AS") && ts.peekN(1).IsPunct("("):
+			// MySQL: AS (expr) [VIRTUAL | STORED]
+			ts.next()
+			if err := p.parseGeneratedColumn(ts, &col); err != nil {
+				return nil, err
+			}
+
+		case ts.accept("CHARACTER", "SET") || ts.accept("CHARSET"):
+			// MySQL
+			col.Charset = ts.next().Value
+
+		case ts.accept("COLLATE"):
+			_, collation, err := ts.parseQualifiedName()
+			if err != nil {
+				return nil, err
+			}
+			if p.dialect == "mysql" || p.dialect == "sqlite" {
+				col.Collation = collation
+			}
+
+		case ts.accept("COMMENT"):
+			// MySQL: COMMENT 'text'
+			col.Comment, err = parseStringLiteral(ts)
+			if err != nil {
+				return nil, err
+			}
+
+		case ts.accept("ON", "UPDATE"):
+			// MySQL: ON UPDATE CURRENT_TIMESTAMP[(fsp)]
+			col.OnUpdate = joinTokens(ts.parseExpr(isColumnConstraintStart))
+
+		case ts.accept("ON", "CONFLICT"):
+			// SQLite conflict clause
+			ts.next()
+
+		case ts.accept("NOT", "FOR", "REPLICATION"):
+			// SQL Server
+
+		case ts.accept("MASKED", "WITH") || ts.accept("ENCRYPTED", "WITH"):
+			// SQL Server: MASKED WITH (FUNCTION = '...'), ENCRYPTED WITH (...)
+			ts.skipElement()
+
+		case ts.accept("STORAGE") || ts.accept("COMPRESSION") || ts.accept("COLUMN_FORMAT") ||
+			ts.accept("SRID"):
+			// PostgreSQL STORAGE and COMPRESSION, MySQL COLUMN_FORMAT, STORAGE and SRID
+			ts.next()
+
+		case ts.accept("ENGINE_ATTRIBUTE") || ts.accept("SECONDARY_ENGINE_ATTRIBUTE"):
+			ts.acceptPunct("=")
+			ts.next()
 
 		default:
-			// Column definition
-			col := p.parseColumnDef(def)
-			if col != nil {
-				table.Columns = append(table.Columns, *col)
+			if _, ok := ts.acceptAny(ignoredColumnOptions...); !ok {
+				return nil, ts.errorf(ts.peek(), "expected column constraint")
 			}
 		}
+
+		constraintName = ""
 	}
 
-	return table, nil
+	return &col, nil
 }
 
-func (p *Parser) parseColumnDef(def string) *Column {
-	parts := strings.Fields(def)
-	if len(parts) < 2 {
-		return nil
+// parseGeneratedColumn parses the expression after AS in a generated
+// column definition and its STORED, PERSISTED or VIRTUAL storage.
+func (p *Parser) parseGeneratedColumn(ts *tokenStream, col *Column) error {
+	tok := ts.peek()
+	expr := ts.parseExpr(func(ts *tokenStream) bool {
+		return ts.peek().Is("STORED") || ts.peek().Is("VIRTUAL") || ts.peek().Is("PERSISTED") ||
+			isColumnConstraintStart(ts)
+	})
+	if len(expr) == 0 {
+		return ts.errorf(tok, "expected generation expression")
 	}
+	expr = unwrapParens(expr)
 
-	col := &Column{
-		Name:     strings.Trim(parts[0], `"'` + "`"),
-		Type:     parts[1],
-		Nullable: true,
+	col.Generated = &Generated{Expression: joinTokens(expr)}
+	if kw, ok := ts.acceptAny("STORED", "PERSISTED", "VIRTUAL"); ok {
+		col.Generated.Stored = kw != "VIRTUAL"
 	}
+	return nil
+}
 
-	upper := strings.ToUpper(def)
+// unwrapParens strips parentheses that enclose all of tokens.
+func unwrapParens(tokens []Token) []Token {
+	for len(tokens) >= 2 && tokens[0].IsPunct("(") && tokens[len(tokens)-1].IsPunct(")") {
+		depth := 0
+		for i, tok := range tokens {
+			switch {
+			case tok.IsPunct("("):
+				depth++
+			case tok.IsPunct(")"):
+				depth--
+			}
+			if depth == 0 && i < len(tokens)-1 {
+				return tokens
+			}
+		}
+		tokens = tokens[1 : len(tokens)-1]
+	}
+	return tokens
+}
 
-	// Check for NOT NULL
-	if strings.Contains(upper, "NOT NULL") {
-		col.Nullable = false
+// isColumnConstraintStart reports whether the stream is positioned at the
+// start of a column constraint, which terminates a DEFAULT expression.
+func isColumnConstraintStart(ts *tokenStream) bool {
+	tok := ts.peek()
+	switch {
+	case tok.Is("NOT"):
+		return ts.peekN(1).Is("NULL")
+	case tok.Is("ON"):
+		return ts.peekN(1).Is("UPDATE")
 	}
+	return containsKeyword(columnConstraintKeywords, tok)
+}
 
-	// Check for PRIMARY KEY
-	if strings.Contains(upper, "PRIMARY KEY") {
-		col.IsPrimaryKey = true
-		col.Nullable = false
+// ignoredColumnOptions lists single-word column options that do not affect
+// the modeled schema.
+var ignoredColumnOptions = []string{
+	"VISIBLE", "INVISIBLE", "ROWGUIDCOL", "SPARSE", "FILESTREAM", "HIDDEN",
+	"DEFERRABLE", "UNSIGNED", "ZEROFILL", "BINARY",
+}
+
+var columnConstraintKeywords = []string{
+	"NULL", "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "REFERENCES", "DEFAULT",
+	"COLLATE", "GENERATED", "AUTO_INCREMENT", "AUTOINCREMENT", "IDENTITY", "COMMENT",
+}
+
+// typeContinuations lists the words that may follow a type keyword to form
+// a multi-word type name such as DOUBLE PRECISION.
+var typeContinuations = map[string][]string{
+	"DOUBLE":    {"PRECISION"},
+	"CHARACTER": {"VARYING", "LARGE"},
+	"CHAR":      {"VARYING", "LARGE"},
+	"NATIONAL":  {"CHARACTER", "CHAR"},
+	"NCHAR":     {"VARYING"},
+	"BIT":       {"VARYING"},
+	"BINARY":    {"VARYING", "LARGE"},
+	"LARGE":     {"OBJECT"},
+	"LONG":      {"VARCHAR", "VARBINARY"},
+}
+
+// parseDataType consumes a column type, including multi-word names,
+// parameters, time zone qualifiers and array suffixes.
+func (p *Parser) parseDataType(ts *tokenStream) (string, error) {
+	if tok := ts.peek(); tok.Kind != TokenIdent && tok.Kind != TokenQuotedIdent {
+		return "", ts.errorf(tok, "expected data type")
 	}
 
-	// Check for UNIQUE
-	if strings.Contains(upper, "UNIQUE") {
-		col.IsUnique = true
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return "", err
+	}
+	if schemaName != "" {
+		name = schemaName + "." + name
 	}
 
-	// Check for identity/auto-increment
-	if strings.Contains(upper, "SERIAL") ||
-		strings.Contains(upper, "AUTO_INCREMENT") ||
-		strings.Contains(upper, "IDENTITY") ||
-		strings.Contains(upper, "AUTOINCREMENT") {
-		col.IsIdentity = true
+	words := []string{name}
+	for {
+		allowed := typeContinuations[strings.ToUpper(words[len(words)-1])]
+		tok := ts.peek()
+		if !containsKeyword(allowed, tok) && !p.isSQLiteTypeWord(ts) {
+			break
+		}
+		words = append(words, ts.next().Raw)
 	}
+	dataType := strings.Join(words, " ")
 
-	// Check for DEFAULT
-	defaultRe := regexp.MustCompile(`(?i)DEFAULT\s+(.+?)(?:\s+(?:NOT\s+)?NULL|\s+PRIMARY|\s+UNIQUE|\s+CHECK|\s+REFERENCES|,|$)`)
-	if matches := defaultRe.FindStringSubmatch(def); len(matches) >= 2 {
-		defaultVal := strings.TrimSpace(matches[1])
-		col.Default = &defaultVal
+	if ts.peek().IsPunct("(") {
+		groups, err := ts.parseParenGroups()
+		if err != nil {
+			return "", err
+		}
+		args := make([]string, len(groups))
+		for i, g := range groups {
+			args[i] = joinTokens(g)
+		}
+		dataType += "(" + strings.Join(args, ",") + ")"
 	}
 
-	return col
-}
+	for _, kw := range []string{"WITH", "WITHOUT"} {
+		if ts.peek().Is(kw) && ts.peekN(1).Is("TIME") && ts.peekN(2).Is("ZONE") {
+			parts := []string{ts.next().Raw, ts.next().Raw, ts.next().Raw}
+			dataType += " " + strings.Join(parts, " ")
+		}
+	}
 
-func (p *Parser) parsePrimaryKeyConstraint(def string) *PrimaryKey {
-	pk := &PrimaryKey{}
+	for {
+		tok := ts.peek()
+		if !tok.Is("UNSIGNED") && !tok.Is("SIGNED") && !tok.Is("ZEROFILL") {
+			break
+		}
+		dataType += " " + ts.next().Raw
+	}
 
-	// Extract columns
-	colsRe := regexp.MustCompile(`\(([^)]+)\)`)
-	if matches := colsRe.FindStringSubmatch(def); len(matches) >= 2 {
-		cols := strings.Split(matches[1], ",")
-		for _, c := range cols {
-			pk.Columns = append(pk.Columns, strings.TrimSpace(strings.Trim(c, `"'` + "`")))
+	for ts.peek().IsPunct("[") {
+		ts.next()
+		size := ""
+		if ts.peek().Kind == TokenNumber {
+			size = ts.next().Raw
 		}
+		if err := ts.expectPunct("]"); err != nil {
+			return "", err
+		}
+		dataType += "[" + size + "]"
+	}
+	if ts.accept("ARRAY") {
+		dataType += "[]"
 	}
 
-	return pk
+	return dataType, nil
 }
 
-func (p *Parser) parseForeignKeyConstraint(def string) *ForeignKey {
+// isSQLiteTypeWord reports whether the next token continues a SQLite type
+// name, which may be any sequence of words, as in UNSIGNED BIG INT.
+func (p *Parser) isSQLiteTypeWord(ts *tokenStream) bool {
+	tok := ts.peek()
+	return p.dialect == "sqlite" && tok.Kind == TokenIdent && !tok.Is("AS") && !isColumnConstraintStart(ts)
+}
+
+func isSerialType(t string) bool {
+	switch strings.ToUpper(t) {
+	case "SERIAL", "BIGSERIAL", "SMALLSERIAL", "SERIAL4", "SERIAL8", "SERIAL2":
+		return true
+	}
+	return false
+}
+
+// isSequenceDefault reports whether a default draws from a sequence, as
+// SERIAL columns do once expanded by pg_dump.
+func isSequenceDefault(expr string) bool {
+	return strings.HasPrefix(strings.ToLower(expr), "nextval(")
+}
+
+func (p *Parser) parsePrimaryKeyConstraint(ts *tokenStream) (*PrimaryKey, error) {
+	ts.acceptAny("CLUSTERED", "NONCLUSTERED")
+
+	cols, err := p.parseKeyColumns(ts)
+	if err != nil {
+		return nil, err
+	}
+	return &PrimaryKey{Columns: cols}, nil
+}
+
+func (p *Parser) parseForeignKeyConstraint(ts *tokenStream) (*ForeignKey, error) {
+	cols, err := ts.parseIdentList()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ts.expect("REFERENCES"); err != nil {
+		return nil, err
+	}
+
+	fk, err := p.parseReferences(ts)
+	if err != nil {
+		return nil, err
+	}
+	fk.Columns = cols
+	return fk, nil
+}
+
+// parseReferences parses the target and actions of a REFERENCES clause.
+func (p *Parser) parseReferences(ts *tokenStream) (*ForeignKey, error) {
 	fk := &ForeignKey{}
 
-	// Extract local columns
-	localRe := regexp.MustCompile(`(?i)FOREIGN\s+KEY\s*\(([^)]+)\)`)
-	if matches := localRe.FindStringSubmatch(def); len(matches) >= 2 {
-		cols := strings.Split(matches[1], ",")
-		for _, c := range cols {
-			fk.Columns = append(fk.Columns, strings.TrimSpace(strings.Trim(c, `"'` + "`")))
-		}
+	var err error
+	fk.ReferencedSchema, fk.ReferencedTable, err = ts.parseQualifiedName()
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract referenced table and columns
-	refRe := regexp.MustCompile(`(?i)REFERENCES\s+(?:(\w+)\.)?["']?(\w+)["']?\s*\(([^)]+)\)`)
-	if matches := refRe.FindStringSubmatch(def); len(matches) >= 4 {
-		fk.ReferencedSchema = matches[1]
-		fk.ReferencedTable = matches[2]
-		cols := strings.Split(matches[3], ",")
-		for _, c := range cols {
-			fk.ReferencedCols = append(fk.ReferencedCols, strings.TrimSpace(strings.Trim(c, `"'` + "`")))
+	if ts.peek().IsPunct("(") {
+		fk.ReferencedCols, err = ts.parseIdentList()
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	// ON DELETE
-	if strings.Contains(strings.ToUpper(def), "ON DELETE CASCADE") {
-		fk.OnDelete = "CASCADE"
-	} else if strings.Contains(strings.ToUpper(def), "ON DELETE SET NULL") {
-		fk.OnDelete = "SET NULL"
+	for !ts.atEnd() {
+		switch {
+		case ts.accept("ON", "DELETE"):
+			fk.OnDelete = parseReferentialAction(ts)
+		case ts.accept("ON", "UPDATE"):
+			fk.OnUpdate = parseReferentialAction(ts)
+		case ts.accept("MATCH"):
+			fk.Match = strings.ToUpper(ts.next().Value)
+		case ts.accept("NOT", "DEFERRABLE"):
+			fk.Deferrable = false
+		case ts.accept("DEFERRABLE"):
+			fk.Deferrable = true
+		case ts.accept("INITIALLY", "DEFERRED"):
+			fk.InitiallyDeferred = true
+		case ts.accept("INITIALLY", "IMMEDIATE"):
+			fk.InitiallyDeferred = false
+		case ts.accept("NOT", "FOR", "REPLICATION"):
+		default:
+			return fk, nil
+		}
 	}
 
-	// ON UPDATE
-	if strings.Contains(strings.ToUpper(def), "ON UPDATE CASCADE") {
-		fk.OnUpdate = "CASCADE"
-	}
+	return fk, nil
+}
 
-	return fk
+func parseReferentialAction(ts *tokenStream) string {
+	switch {
+	case ts.accept("CASCADE"):
+		return "CASCADE"
+	case ts.accept("SET", "NULL"):
+		return "SET NULL"
+	case ts.accept("SET", "DEFAULT"):
+		return "SET DEFAULT"
+	case ts.accept("RESTRICT"):
+		return "RESTRICT"
+	case ts.accept("NO", "ACTION"):
+		return "NO ACTION"
+	}
+	return ""
 }
 
-func (p *Parser) parseUniqueConstraint(def string) *Constraint {
+func (p *Parser) parseUniqueConstraint(ts *tokenStream) (*Constraint, error) {
 	constraint := &Constraint{Type: "UNIQUE"}
 
-	colsRe := regexp.MustCompile(`\(([^)]+)\)`)
-	if matches := colsRe.FindStringSubmatch(def); len(matches) >= 2 {
-		cols := strings.Split(matches[1], ",")
-		for _, c := range cols {
-			constraint.Columns = append(constraint.Columns, strings.TrimSpace(strings.Trim(c, `"'` + "`")))
+	// MySQL: UNIQUE [KEY|INDEX] [name] (cols)
+	ts.acceptAny("KEY", "INDEX")
+	ts.acceptAny("CLUSTERED", "NONCLUSTERED")
+	if !ts.peek().IsPunct("(") {
+		name, err := ts.parseIdent()
+		if err != nil {
+			return nil, err
 		}
+		constraint.Name = name
 	}
 
-	return constraint
+	cols, err := p.parseKeyColumns(ts)
+	if err != nil {
+		return nil, err
+	}
+	constraint.Columns = cols
+	return constraint, nil
 }
 
-func (p *Parser) parseCheckConstraint(def string) *Constraint {
-	constraint := &Constraint{Type: "CHECK"}
+func (p *Parser) parseCheckConstraint(ts *tokenStream) (*Constraint, error) {
+	if err := ts.expectPunct("("); err != nil {
+		return nil, err
+	}
 
-	// Extract expression
-	checkRe := regexp.MustCompile(`(?i)CHECK\s*\((.+)\)`)
-	if matches := checkRe.FindStringSubmatch(def); len(matches) >= 2 {
-		constraint.Expression = matches[1]
+	expr := ts.parseExpr(nil)
+	if err := ts.expectPunct(")"); err != nil {
+		return nil, err
 	}
 
-	return constraint
+	return &Constraint{Type: "CHECK", Expression: joinTokens(expr)}, nil
 }
 
-func (p *Parser) parseNamedConstraint(def string, table *Table) {
-	upper := strings.ToUpper(def)
+// parseInlineIndex parses a MySQL or SQL Server index declared inside
+// CREATE TABLE: {KEY|INDEX} [name] [USING type] (cols).
+func (p *Parser) parseInlineIndex(ts *tokenStream, table *Table, indexType string) (*Index, error) {
+	ts.acceptAny("KEY", "INDEX")
 
-	// Extract constraint name
-	nameRe := regexp.MustCompile(`(?i)CONSTRAINT\s+["']?(\w+)["']?`)
-	var name string
-	if matches := nameRe.FindStringSubmatch(def); len(matches) >= 2 {
-		name = matches[1]
+	idx := &Index{
+		Table:  table.Name,
+		Schema: table.Schema,
+		Type:   indexType,
 	}
 
-	switch {
-	case strings.Contains(upper, "PRIMARY KEY"):
-		pk := p.parsePrimaryKeyConstraint(def)
-		pk.Name = name
-		table.PrimaryKey = pk
-
-	case strings.Contains(upper, "FOREIGN KEY"):
-		fk := p.parseForeignKeyConstraint(def)
-		if fk != nil {
-			fk.Name = name
-			table.ForeignKeys = append(table.ForeignKeys, *fk)
+	if !ts.peek().IsPunct("(") && !ts.peek().Is("USING") {
+		name, err := ts.parseIdent()
+		if err != nil {
+			return nil, err
 		}
+		idx.Name = name
+	}
 
-	case strings.Contains(upper, "UNIQUE"):
-		constraint := p.parseUniqueConstraint(def)
-		if constraint != nil {
-			constraint.Name = name
-			table.Constraints = append(table.Constraints, *constraint)
+	if kind, ok := ts.acceptAny("CLUSTERED", "NONCLUSTERED"); ok {
+		idx.Type = strings.ToLower(kind)
+	}
+	if ts.accept("USING") {
+		idx.Type = strings.ToLower(ts.next().Value)
+	}
+
+	keys, err := p.parseIndexKeys(ts)
+	if err != nil {
+		return nil, err
+	}
+	idx.SetKeys(keys)
+
+	if err := p.parseIndexOptions(ts, idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// parseKeyColumns parses a parenthesized key column list, dropping sort
+// order and prefix lengths.
+func (p *Parser) parseKeyColumns(ts *tokenStream) ([]string, error) {
+	keys, err := p.parseIndexKeys(ts)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make([]string, len(keys))
+	for i, k := range keys {
+		cols[i] = k.Name
+		if k.Expression != "" {
+			cols[i] = k.Expression
 		}
+	}
+	return cols, nil
+}
 
-	case strings.Contains(upper, "CHECK"):
-		constraint := p.parseCheckConstraint(def)
-		if constraint != nil {
-			constraint.Name = name
-			table.Constraints = append(table.Constraints, *constraint)
+// parseIndexKeys parses a parenthesized index key list.
+func (p *Parser) parseIndexKeys(ts *tokenStream) ([]IndexColumn, error) {
+	groups, err := ts.parseParenGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]IndexColumn, 0, len(groups))
+	for _, g := range groups {
+		keys = append(keys, p.parseIndexKey(g))
+	}
+	return keys, nil
+}
+
+// parseIndexKey parses one index element:
+//
+//	{column | expression} [COLLATE collation] [opclass] [ASC|DESC] [NULLS {FIRST|LAST}]
+func (p *Parser) parseIndexKey(elem []Token) IndexColumn {
+	var key IndexColumn
+
+	// Trailing ordering options
+	if n := len(elem); n > 2 && elem[n-2].Is("NULLS") && (elem[n-1].Is("FIRST") || elem[n-1].Is("LAST")) {
+		key.Nulls = strings.ToUpper(elem[n-1].Value)
+		elem = elem[:n-2]
+	}
+	if n := len(elem); n > 1 && (elem[n-1].Is("ASC") || elem[n-1].Is("DESC")) {
+		key.Descending = elem[n-1].Is("DESC")
+		elem = elem[:n-1]
+	}
+	// NULLS LAST is the default for ascending keys and NULLS FIRST for
+	// descending ones
+	if (key.Nulls == "LAST" && !key.Descending) || (key.Nulls == "FIRST" && key.Descending) {
+		key.Nulls = ""
+	}
+
+	// COLLATE collation [opclass]
+	depth := 0
+collate:
+	for i, tok := range elem {
+		switch {
+		case tok.IsPunct("("):
+			depth++
+		case tok.IsPunct(")"):
+			depth--
+		case depth == 0 && i > 0 && tok.Is("COLLATE"):
+			rest := elem[i+1:]
+			name := rest
+			for j := 1; j < len(rest); j++ {
+				if !rest[j].IsPunct(".") && !rest[j-1].IsPunct(".") {
+					name, key.OpClass = rest[:j], joinTokens(rest[j:])
+					break
+				}
+			}
+			key.Collation = joinTokens(name)
+			elem = elem[:i]
+			break collate
 		}
 	}
+
+	// Trailing operator class: col opclass, func(col) opclass
+	if n := len(elem); key.OpClass == "" && n > 1 && isIdentToken(elem[n-1]) &&
+		(isIdentToken(elem[n-2]) || elem[n-2].IsPunct(")")) {
+		key.OpClass = elem[n-1].Value
+		elem = elem[:n-1]
+	}
+
+	switch {
+	case len(elem) == 1 && isIdentToken(elem[0]):
+		key.Name = elem[0].Value
+
+	case len(elem) == 4 && isIdentToken(elem[0]) && elem[1].IsPunct("(") &&
+		elem[2].Kind == TokenNumber && elem[3].IsPunct(")") && p.dialect == "mysql":
+		// MySQL prefix length: col(10)
+		key.Name = elem[0].Value
+		key.Length, _ = strconv.Atoi(elem[2].Value)
+
+	default:
+		key.Expression = joinTokens(elem)
+	}
+	return key
+}
+
+func isIdentToken(tok Token) bool {
+	return tok.Kind == TokenIdent || tok.Kind == TokenQuotedIdent
 }
 
-func (p *Parser) parseCreateIndex(stmt string) (*Index, error) {
-	idx := &Index{}
+// parseIndexOptions parses the clauses that follow an index key list:
+// INCLUDE (cols), WHERE predicate, and storage options, which are skipped.
+func (p *Parser) parseIndexOptions(ts *tokenStream, idx *Index) error {
+	for !ts.atEnd() {
+		switch {
+		case ts.accept("INCLUDE"):
+			cols, err := ts.parseIdentList()
+			if err != nil {
+				return err
+			}
+			idx.Include = cols
+
+		case ts.accept("WHERE"):
+			pred := ts.parseExpr(func(ts *tokenStream) bool {
+				return ts.peek().Is("WITH") || ts.peek().Is("ON") || ts.peek().Is("TABLESPACE")
+			})
+			if len(pred) == 0 {
+				return ts.errorf(ts.peek(), "expected index predicate")
+			}
+			idx.Where = joinTokens(pred)
 
-	upper := strings.ToUpper(stmt)
-	idx.IsUnique = strings.Contains(upper, "UNIQUE")
+		case ts.accept("USING") && !ts.atEnd():
+			idx.Type = strings.ToLower(ts.next().Value)
 
-	// Extract index name
-	nameRe := regexp.MustCompile(`(?i)CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:CONCURRENTLY\s+)?["']?(\w+)["']?`)
-	if matches := nameRe.FindStringSubmatch(stmt); len(matches) >= 2 {
-		idx.Name = matches[1]
+		default:
+			ts.skipElement()
+		}
 	}
+	return nil
+}
 
-	// Extract table name
-	tableRe := regexp.MustCompile(`(?i)ON\s+(?:(\w+)\.)?["']?(\w+)["']?`)
-	if matches := tableRe.FindStringSubmatch(stmt); len(matches) >= 3 {
-		idx.Schema = matches[1]
-		idx.Table = matches[2]
+func (p *Parser) parseCreateIndex(ts *tokenStream, unique bool, indexType string) (*Index, error) {
+	idx := &Index{
+		IsUnique: unique,
+		Type:     indexType,
 	}
 
-	// Extract columns
-	colsRe := regexp.MustCompile(`\(([^)]+)\)`)
-	if matches := colsRe.FindStringSubmatch(stmt); len(matches) >= 2 {
-		cols := strings.Split(matches[1], ",")
-		for _, c := range cols {
-			c = strings.TrimSpace(c)
-			// Remove ASC/DESC
-			c = regexp.MustCompile(`(?i)\s+(ASC|DESC)$`).ReplaceAllString(c, "")
-			idx.Columns = append(idx.Columns, strings.Trim(c, `"'` + "`"))
+	ts.accept("CONCURRENTLY")
+	ts.accept("IF", "NOT", "EXISTS")
+
+	if !ts.peek().Is("ON") {
+		name, err := ts.parseIdent()
+		if err != nil {
+			return nil, err
 		}
+		idx.Name = name
+	}
+
+	if ts.accept("USING") {
+		idx.Type = strings.ToLower(ts.next().Value)
+	}
+
+	if err := ts.expect("ON"); err != nil {
+		return nil, err
+	}
+	ts.accept("ONLY")
+
+	var err error
+	idx.Schema, idx.Table, err = ts.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+
+	if ts.accept("USING") {
+		idx.Type = strings.ToLower(ts.next().Value)
+	}
+
+	keys, err := p.parseIndexKeys(ts)
+	if err != nil {
+		return nil, err
+	}
+	idx.SetKeys(keys)
+
+	if err := p.parseIndexOptions(ts, idx); err != nil {
+		return nil, err
 	}
 
 	return idx, nil
 }
 
-func (p *Parser) parseCreateView(stmt string) (*View, error) {
+func (p *Parser) parseCreateView(ts *tokenStream) (*View, error) {
 	view := &View{}
 
-	// Extract view name
-	nameRe := regexp.MustCompile(`(?i)CREATE\s+(?:OR\s+REPLACE\s+)?VIEW\s+(?:(\w+)\.)?["']?(\w+)["']?`)
-	if matches := nameRe.FindStringSubmatch(stmt); len(matches) >= 3 {
-		view.Schema = matches[1]
-		view.Name = matches[2]
+	ts.accept("IF", "NOT", "EXISTS")
+
+	var err error
+	view.Schema, view.Name, err = ts.parseQualifiedName()
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract definition (everything after AS)
-	asIdx := regexp.MustCompile(`(?i)\sAS\s`).FindStringIndex(stmt)
-	if asIdx != nil {
-		view.Definition = strings.TrimSpace(stmt[asIdx[1]:])
+	// Skip column list and view options up to AS
+	for !ts.atEnd() && !ts.peek().Is("AS") {
+		ts.skipElement()
+	}
+	if err := ts.expect("AS"); err != nil {
+		return nil, err
 	}
 
+	view.Definition = joinTokens(ts.rest())
 	return view, nil
 }
 
-// Helper functions
+// parseDrop handles DROP TABLE, INDEX, VIEW, TYPE, DOMAIN and SEQUENCE.
+func (p *Parser) parseDrop(s *Schema, ts *tokenStream) error {
+	if ts.accept("SCHEMA") {
+		return p.parseDropSchema(s, ts)
+	}
+	kind, ok := ts.acceptAny("TABLE", "INDEX", "VIEW", "TYPE", "DOMAIN", "SEQUENCE",
+		"FUNCTION", "PROCEDURE", "PROC", "TRIGGER")
+	if !ok {
+		return skipObjectKind(ts, "DROP")
+	}
+	if kind == "PROC" {
+		kind = "PROCEDURE"
+	}
 
-func normalizeSQL(sql string) string {
-	// Remove single-line comments
-	sql = regexp.MustCompile(`--[^\n]*`).ReplaceAllString(sql, "")
-	// Remove multi-line comments
-	sql = regexp.MustCompile(`/\*[\s\S]*?\*/`).ReplaceAllString(sql, "")
-	// Normalize whitespace
-	sql = regexp.MustCompile(`\s+`).ReplaceAllString(sql, " ")
-	return strings.TrimSpace(sql)
+	ts.accept("CONCURRENTLY")
+	ts.accept("IF", "EXISTS")
+
+	type objectName struct{ schema, name, args string }
+	var names []objectName
+	for {
+		schemaName, name, err := ts.parseQualifiedName()
+		if err != nil {
+			return err
+		}
+		args := ""
+		if ts.peek().IsPunct("(") {
+			// PostgreSQL: DROP FUNCTION name(argtypes)
+			from := ts.pos
+			if _, err := ts.parseParenGroups(); err != nil {
+				return err
+			}
+			args = p.sourceText(ts, from, ts.pos)
+		}
+		names = append(names, objectName{schemaName, name, args})
+		if !ts.acceptPunct(",") {
+			break
+		}
+	}
+
+	// MySQL and SQL Server: DROP INDEX name ON table
+	// PostgreSQL: DROP TRIGGER name ON table
+	var onTable *Table
+	onTableName := ""
+	if (kind == "INDEX" || kind == "TRIGGER") && ts.accept("ON") {
+		schemaName, name, err := ts.parseQualifiedName()
+		if err != nil {
+			return err
+		}
+		onTable = s.table(schemaName, name)
+		onTableName = name
+	}
+	cascade := ts.accept("CASCADE")
+
+	for _, n := range names {
+		switch kind {
+		case "TABLE":
+			s.dropTable(n.schema, n.name, cascade)
+		case "VIEW":
+			s.dropView(n.schema, n.name)
+		case "TYPE":
+			s.dropEnum(n.schema, n.name)
+			s.dropDomain(n.schema, n.name)
+		case "DOMAIN":
+			s.dropDomain(n.schema, n.name)
+		case "SEQUENCE":
+			s.dropSequence(n.schema, n.name)
+		case "FUNCTION", "PROCEDURE":
+			s.dropRoutine(kind, n.schema, n.name, n.args)
+		case "TRIGGER":
+			s.dropTrigger(n.schema, n.name, onTableName)
+		case "INDEX":
+			table := onTable
+			indexName := n.name
+			if table == nil && p.dialect == "sqlserver" && n.schema != "" {
+				// SQL Server: DROP INDEX table.index
+				table = s.table("", n.schema)
+			}
+			if table == nil {
+				table = s.indexTable(n.schema, indexName)
+			}
+			if table != nil {
+				s.dropIndex(table, indexName)
+			} else {
+				s.dropStandaloneIndex(n.schema, indexName)
+			}
+		}
+	}
+
+	return nil
 }
 
-func splitStatements(sql string) []string {
-	var statements []string
-	var current strings.Builder
-	inString := false
-	stringChar := rune(0)
+// parseRenameTable handles MySQL's RENAME TABLE a TO b [, c TO d].
+func (p *Parser) parseRenameTable(s *Schema, ts *tokenStream) error {
+	for {
+		nameTok := ts.peek()
+		oldSchema, oldName, err := ts.parseQualifiedName()
+		if err != nil {
+			return err
+		}
+		if err := ts.expect("TO"); err != nil {
+			return err
+		}
+		newSchema, newName, err := ts.parseQualifiedName()
+		if err != nil {
+			return err
+		}
+
+		table := s.table(oldSchema, oldName)
+		if table == nil {
+			return ts.errorAt(nameTok, "RENAME TABLE of undefined table %s", oldName)
+		}
+		if newSchema == "" {
+			newSchema = table.Schema
+		}
+		s.moveTable(table, newSchema, newName)
 
-	for _, ch := range sql {
-		if !inString && (ch == '\'' || ch == '"') {
-			inString = true
-			stringChar = ch
-		} else if inString && ch == stringChar {
-			inString = false
+		if !ts.acceptPunct(",") {
+			return nil
 		}
+	}
+}
+
+// parseAlterIndex handles ALTER INDEX name RENAME TO new. Other index
+// options are ignored.
+func (p *Parser) parseAlterIndex(s *Schema, ts *tokenStream) error {
+	ts.accept("IF", "EXISTS")
 
-		if ch == ';' && !inString {
-			statements = append(statements, current.String())
-			current.Reset()
-		} else {
-			current.WriteRune(ch)
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+	if !ts.accept("RENAME", "TO") {
+		return nil
+	}
+	newName, err := ts.parseIdent()
+	if err != nil {
+		return err
+	}
+
+	if table := s.indexTable(schemaName, name); table != nil {
+		s.renameIndex(table, name, newName)
+	} else {
+		for i := range s.Indexes {
+			if sameObject(schemaName, name, s.Indexes[i].Schema, s.Indexes[i].Name) {
+				s.Indexes[i].Name = newName
+			}
 		}
 	}
+	return nil
+}
 
-	if current.Len() > 0 {
-		statements = append(statements, current.String())
+// parseExec handles the SQL Server procedures that change the schema:
+// sp_rename and the extended property procedures that set descriptions.
+func (p *Parser) parseExec(s *Schema, ts *tokenStream) error {
+	_, proc, err := ts.parseQualifiedName()
+	if err != nil {
+		return err
 	}
 
-	return statements
+	switch strings.ToLower(proc) {
+	case "sp_rename":
+		return p.execRename(s, ts)
+	case "sp_addextendedproperty", "sp_updateextendedproperty", "sp_dropextendedproperty":
+		return p.execExtendedProperty(s, ts, proc)
+	}
+	return nil
 }
 
-func splitColumnDefs(body string) []string {
-	var defs []string
-	var current strings.Builder
-	parenDepth := 0
+// execRename applies
+// EXEC sp_rename 'schema.table[.column]', 'new_name' [, 'COLUMN' | 'INDEX' | 'OBJECT'].
+func (p *Parser) execRename(s *Schema, ts *tokenStream) error {
+	args, err := parseProcArgs(ts, "sp_rename")
+	if err != nil {
+		return err
+	}
+	bound := bindProcArgs(args, []string{"objname", "newname", "objtype"})
+	if bound["objname"] == "" || bound["newname"] == "" {
+		return fmt.Errorf("sp_rename requires an object name and a new name")
+	}
 
-	for _, ch := range body {
-		switch ch {
-		case '(':
-			parenDepth++
-			current.WriteRune(ch)
-		case ')':
-			parenDepth--
-			current.WriteRune(ch)
-		case ',':
-			if parenDepth == 0 {
-				defs = append(defs, current.String())
-				current.Reset()
-			} else {
-				current.WriteRune(ch)
-			}
-		default:
-			current.WriteRune(ch)
+	objType := "OBJECT"
+	if t := bound["objtype"]; t != "" {
+		objType = strings.ToUpper(t)
+	}
+
+	objName := bound["objname"]
+	parts := splitSQLServerName(objName)
+	newName := bound["newname"]
+
+	switch objType {
+	case "COLUMN":
+		if len(parts) < 2 {
+			return fmt.Errorf("sp_rename: column name %q must be qualified by its table", objName)
+		}
+		table := s.table(schemaPart(parts[:len(parts)-1]), parts[len(parts)-2])
+		if table == nil {
+			return fmt.Errorf("sp_rename: undefined table in %q", objName)
+		}
+		s.renameColumn(table, parts[len(parts)-1], newName)
+
+	case "INDEX":
+		if len(parts) < 2 {
+			return fmt.Errorf("sp_rename: index name %q must be qualified by its table", objName)
+		}
+		table := s.table(schemaPart(parts[:len(parts)-1]), parts[len(parts)-2])
+		if table == nil {
+			return fmt.Errorf("sp_rename: undefined table in %q", objName)
+		}
+		s.renameIndex(table, parts[len(parts)-1], newName)
+
+	default:
+		table := s.table(schemaPart(parts), parts[len(parts)-1])
+		if table == nil {
+			return fmt.Errorf("sp_rename: undefined table %q", objName)
 		}
+		s.moveTable(table, table.Schema, newName)
 	}
 
-	if current.Len() > 0 {
-		defs = append(defs, current.String())
+	return nil
+}
+
+// splitSQLServerName splits a dotted, optionally bracketed object name.
+func splitSQLServerName(name string) []string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = strings.TrimSuffix(strings.TrimPrefix(part, "["), "]")
 	}
+	return parts
+}
 
-	return defs
+// schemaPart returns the schema component of a split name whose last
+// element is the object name.
+func schemaPart(parts []string) string {
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2]
+}
+
+// Helper functions
+
+func containsKeyword(keywords []string, tok Token) bool {
+	for _, kw := range keywords {
+		if tok.Is(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// table returns the named table. An empty schema name matches any schema,
+// preferring a table declared without one.
+func (s *Schema) table(schemaName, name string) *Table {
+	if t := s.tableIn(schemaName, name); t != nil || schemaName != "" {
+		return t
+	}
+	for i := range s.Tables {
+		t := &s.Tables[i]
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// tableIn returns the named table declared in exactly the given schema.
+func (s *Schema) tableIn(schemaName, name string) *Table {
+	for i := range s.Tables {
+		t := &s.Tables[i]
+		if strings.EqualFold(t.Name, name) && strings.EqualFold(t.Schema, schemaName) {
+			return t
+		}
+	}
+	return nil
+}
+
+// view returns the named view. An empty schema name matches any schema,
+// preferring a view declared without one.
+func (s *Schema) view(schemaName, name string) *View {
+	if v := s.viewIn(schemaName, name); v != nil || schemaName != "" {
+		return v
+	}
+	for i := range s.Views {
+		v := &s.Views[i]
+		if strings.EqualFold(v.Name, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// viewIn returns the named view declared in exactly the given schema.
+func (s *Schema) viewIn(schemaName, name string) *View {
+	for i := range s.Views {
+		v := &s.Views[i]
+		if strings.EqualFold(v.Name, name) && strings.EqualFold(v.Schema, schemaName) {
+			return v
+		}
+	}
+	return nil
+}
+
+// column returns the named column, matching case-insensitively.
+func (t *Table) column(name string) *Column {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// syncPrimaryKey marks the columns of the table-level primary key as
+// primary key columns.
+func (t *Table) syncPrimaryKey() {
+	if t.PrimaryKey == nil {
+		return
+	}
+	for _, name := range t.PrimaryKey.Columns {
+		if col := t.column(name); col != nil {
+			col.IsPrimaryKey = true
+			col.Nullable = false
+		}
+	}
 }
@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseDiagnostics(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		sql     string
+		want    []string
+	}{
+		{
+			name:    "misspelled statement",
+			dialect: "postgres",
+			sql:     "CRATE TABLE t (id INT);",
+			want:    []string{`1:1: unrecognized statement "CRATE"`},
+		},
+		{
+			name:    "unknown CREATE kind",
+			dialect: "postgres",
+			sql:     "CREATE WIDGET w;",
+			want:    []string{`1:8: expected object type after CREATE, found "WIDGET"`},
+		},
+		{
+			name:    "unknown ALTER kind",
+			dialect: "mysql",
+			sql:     "ALTER THING x;",
+			want:    []string{`1:7: expected object type after ALTER, found "THING"`},
+		},
+		{
+			name:    "unknown DROP kind",
+			dialect: "sqlserver",
+			sql:     "DROP GADGET g;",
+			want:    []string{`1:6: expected object type after DROP, found "GADGET"`},
+		},
+		{
+			name:    "unknown column constraint",
+			dialect: "postgres",
+			sql:     "CREATE TABLE t (id INT PRIMARY KEY, name TEXT NOTNULL);",
+			want:    []string{`1:47: expected column constraint, found "NOTNULL"`},
+		},
+		{
+			name:    "one diagnostic per bad statement",
+			dialect: "postgres",
+			sql:     "CRATE TABLE t (id INT);\nCREATE TABLE u (id INT);\nCREATE WIDGET w;",
+			want: []string{
+				`1:1: unrecognized statement "CRATE"`,
+				`3:8: expected object type after CREATE, found "WIDGET"`,
+			},
+		},
+		{
+			name:    "data and session statements",
+			dialect: "postgres",
+			sql:     "SET search_path = public;\nINSERT INTO t VALUES (1);\nGRANT SELECT ON t TO app;\nBEGIN;\nCOMMIT;",
+		},
+		{
+			name:    "unmodeled object kinds",
+			dialect: "postgres",
+			sql:     "CREATE EXTENSION pgcrypto;\nCREATE MATERIALIZED VIEW mv AS SELECT 1;\nDROP ROLE app;",
+		},
+		{
+			name:    "ignorable column options",
+			dialect: "mysql",
+			sql:     "CREATE TABLE t (id INT UNSIGNED ZEROFILL, note TEXT INVISIBLE);",
+		},
+		{
+			name:    "SQL Server column options",
+			dialect: "sqlserver",
+			sql:     "CREATE TABLE t (id INT IDENTITY(1,1) NOT FOR REPLICATION, g UNIQUEIDENTIFIER ROWGUIDCOL);",
+		},
+		{
+			name:    "pg_dump COPY data",
+			dialect: "postgres",
+			sql:     "CREATE TABLE t (id INT, name TEXT);\nCOPY public.t (id, name) FROM stdin;\n1\tnot a statement\n\\.\n",
+		},
+		{
+			name:    "pg_dump sequence owner",
+			dialect: "postgres",
+			sql:     "CREATE SEQUENCE s;\nALTER TABLE s OWNER TO app;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(tt.dialect)
+			if _, err := p.Parse(tt.sql); err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			var got []string
+			for _, d := range p.Diagnostics() {
+				got = append(got, d.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diagnostics = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	p := NewParserWithOptions("postgres", ParseOptions{File: "schema.sql", Strict: true})
+	_, err := p.Parse("CREATE TABLE t (id INT);\nCRATE TABLE u (id INT);\nCREATE WIDGET w;")
+
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Parse error = %v, want a *ParseError", err)
+	}
+	if want := `schema.sql:2:1: unrecognized statement "CRATE"`; perr.Error() != want {
+		t.Errorf("Parse error = %q, want %q", perr.Error(), want)
+	}
+}
+
+func TestParseSerialColumns(t *testing.T) {
+	tests := []struct {
+		name         string
+		sql          string
+		wantNullable bool
+		wantIdentity bool
+		wantType     string
+	}{
+		{
+			name:         "serial",
+			sql:          "CREATE TABLE t (id SERIAL);",
+			wantIdentity: true,
+			wantType:     "SERIAL",
+		},
+		{
+			name:         "smallserial",
+			sql:          "CREATE TABLE t (id SMALLSERIAL);",
+			wantIdentity: true,
+			wantType:     "SMALLSERIAL",
+		},
+		{
+			name:         "bigserial",
+			sql:          "CREATE TABLE t (id BIGSERIAL);",
+			wantIdentity: true,
+			wantType:     "BIGSERIAL",
+		},
+		{
+			name:         "sequence default",
+			sql:          "CREATE TABLE t (id INTEGER DEFAULT nextval('t_seq'::regclass));",
+			wantNullable: true,
+			wantType:     "INTEGER",
+		},
+		{
+			name:         "sequence default set by ALTER TABLE",
+			sql:          "CREATE TABLE t (id INTEGER);\nALTER TABLE t ALTER COLUMN id SET DEFAULT nextval('t_seq'::regclass);",
+			wantNullable: true,
+			wantType:     "INTEGER",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.sql, "postgres")
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			col := s.Tables[0].Columns[0]
+			if col.Nullable != tt.wantNullable {
+				t.Errorf("Nullable = %v, want %v", col.Nullable, tt.wantNullable)
+			}
+			if col.IsIdentity != tt.wantIdentity {
+				t.Errorf("IsIdentity = %v, want %v", col.IsIdentity, tt.wantIdentity)
+			}
+			if got := NewGenerator("postgres").columnType(&col); got != tt.wantType {
+				t.Errorf("columnType = %q, want %q", got, tt.wantType)
+			}
+		})
+	}
+}
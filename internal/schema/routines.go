@@ -0,0 +1,442 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseCreateRoutine handles CREATE FUNCTION and CREATE PROCEDURE. start is
+// the index of the FUNCTION or PROCEDURE keyword, where the recorded
+// definition begins.
+func (p *Parser) parseCreateRoutine(s *Schema, ts *tokenStream, kind string, start int) error {
+	ts.accept("IF", "NOT", "EXISTS")
+
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+	routine := Routine{Name: name, Schema: schemaName, Kind: kind}
+
+	// Parameters are parenthesized, except for SQL Server procedures
+	from := ts.pos
+	if ts.peek().IsPunct("(") {
+		if _, err := ts.parseParenGroups(); err != nil {
+			return err
+		}
+	} else if p.dialect == "sqlserver" {
+		for !ts.atEnd() && !isRoutineClause(ts) {
+			ts.skipElement()
+		}
+	}
+	routine.Signature = p.sourceText(ts, from, ts.pos)
+
+	for !ts.atEnd() {
+		switch {
+		case ts.accept("RETURNS", "NULL", "ON", "NULL", "INPUT"):
+
+		case ts.accept("RETURNS"):
+			from := ts.pos
+			ts.parseExpr(isRoutineClause)
+			routine.Returns = p.sourceText(ts, from, ts.pos)
+
+		case ts.accept("LANGUAGE"):
+			routine.Language = strings.ToLower(ts.next().Value)
+
+		case p.dialect == "mysql" && skipMySQLCharacteristic(ts):
+
+		case p.dialect == "sqlserver" && ts.accept("WITH"):
+			skipSQLServerRoutineOptions(ts)
+
+		case p.dialect == "sqlserver" && ts.accept("AS"):
+			routine.Body = p.restText(ts)
+
+		case ts.peek().Is("AS") && ts.peekN(1).Kind == TokenString:
+			// PostgreSQL: AS 'body' or AS $$body$$
+			ts.next()
+			routine.Body = ts.next().Value
+
+		case p.dialect == "mysql" || ts.peek().Is("BEGIN") || ts.peek().Is("RETURN"):
+			// MySQL and SQL-standard bodies run to the end of the statement
+			routine.Body = p.restText(ts)
+
+		default:
+			ts.skipElement()
+		}
+	}
+	if routine.Body == "" {
+		return ts.errorf(ts.peek(), "expected body of %s %s", strings.ToLower(kind), name)
+	}
+	routine.Definition = p.sourceText(ts, start, len(ts.tokens))
+
+	if existing := s.routine(&routine); existing != nil {
+		*existing = routine
+		return nil
+	}
+	s.Routines = append(s.Routines, routine)
+	return nil
+}
+
+// isRoutineClause reports whether the next token starts a clause of a
+// routine definition, ending the return type or SQL Server parameters.
+func isRoutineClause(ts *tokenStream) bool {
+	tok := ts.peek()
+	for _, kw := range []string{
+		"RETURNS", "LANGUAGE", "AS", "BEGIN", "RETURN", "WITH", "FOR",
+		"IMMUTABLE", "STABLE", "VOLATILE", "STRICT", "CALLED", "SECURITY", "EXTERNAL",
+		"LEAKPROOF", "NOT", "PARALLEL", "COST", "ROWS", "SUPPORT", "SET", "WINDOW", "TRANSFORM",
+		"COMMENT", "DETERMINISTIC", "CONTAINS", "NO", "READS", "MODIFIES", "SQL",
+	} {
+		if tok.Is(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipMySQLCharacteristic consumes a MySQL routine characteristic such as
+// DETERMINISTIC or SQL SECURITY INVOKER.
+func skipMySQLCharacteristic(ts *tokenStream) bool {
+	switch {
+	case ts.accept("COMMENT"):
+		ts.next()
+	case ts.accept("LANGUAGE", "SQL"),
+		ts.accept("NOT", "DETERMINISTIC"), ts.accept("DETERMINISTIC"),
+		ts.accept("CONTAINS", "SQL"), ts.accept("NO", "SQL"),
+		ts.accept("READS", "SQL", "DATA"), ts.accept("MODIFIES", "SQL", "DATA"):
+	case ts.accept("SQL", "SECURITY"):
+		ts.next()
+	default:
+		return false
+	}
+	return true
+}
+
+// skipSQLServerRoutineOptions consumes the options after WITH, such as
+// SCHEMABINDING or EXECUTE AS OWNER.
+func skipSQLServerRoutineOptions(ts *tokenStream) {
+	for !ts.atEnd() {
+		if ts.accept("EXECUTE", "AS") {
+			ts.next()
+		} else {
+			ts.skipElement()
+		}
+		if !ts.acceptPunct(",") {
+			return
+		}
+	}
+}
+
+// parseCreateTrigger handles CREATE [CONSTRAINT] TRIGGER. start is the index
+// of the keyword where the recorded definition begins.
+func (p *Parser) parseCreateTrigger(s *Schema, ts *tokenStream, start int) error {
+	ts.accept("IF", "NOT", "EXISTS")
+
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+	trigger := Trigger{Name: name, Schema: schemaName}
+
+	for !ts.atEnd() {
+		switch {
+		case ts.accept("BEFORE"):
+			trigger.Timing = "BEFORE"
+
+		case ts.accept("AFTER"):
+			trigger.Timing = "AFTER"
+
+		case ts.accept("INSTEAD", "OF"):
+			trigger.Timing = "INSTEAD OF"
+
+		case ts.peek().Is("INSERT") || ts.peek().Is("UPDATE") ||
+			ts.peek().Is("DELETE") || ts.peek().Is("TRUNCATE"):
+			trigger.Events = append(trigger.Events, strings.ToUpper(ts.next().Value))
+			if ts.accept("OF") {
+				// UPDATE OF column, ...
+				for {
+					if _, err := ts.parseIdent(); err != nil {
+						return err
+					}
+					if !ts.peek().IsPunct(",") || isTriggerEvent(ts.peekN(1)) {
+						break
+					}
+					ts.next()
+				}
+			}
+
+		case ts.accept("OR") || ts.acceptPunct(","):
+
+		case ts.accept("ON"):
+			if ts.peek().Is("DATABASE") || ts.peek().Is("ALL") {
+				// SQL Server DDL triggers are not modeled
+				return nil
+			}
+			tableSchema, table, err := ts.parseQualifiedName()
+			if err != nil {
+				return err
+			}
+			trigger.Table = table
+			if trigger.Schema == "" {
+				trigger.Schema = tableSchema
+			}
+
+		case ts.accept("FOR"):
+			ts.accept("EACH")
+			if kw, ok := ts.acceptAny("ROW", "STATEMENT"); ok {
+				trigger.ForEach = kw
+			} else if p.dialect == "sqlserver" && trigger.Timing == "" {
+				// SQL Server: FOR is a synonym for AFTER
+				trigger.Timing = "AFTER"
+			}
+
+		case p.dialect == "sqlserver" && ts.accept("WITH"):
+			skipSQLServerRoutineOptions(ts)
+
+		case ts.accept("WHEN"):
+			from := ts.pos
+			if p.dialect == "sqlite" {
+				// SQLite: WHEN expr BEGIN ... END
+				ts.parseExpr(func(ts *tokenStream) bool { return ts.peek().Is("BEGIN") })
+			} else {
+				ts.skipElement()
+			}
+			trigger.When = joinTokens(unwrapParens(ts.tokens[from:ts.pos]))
+
+		case p.dialect == "postgres" && ts.peek().Is("EXECUTE"):
+			trigger.Body = p.restText(ts)
+
+		case p.dialect == "sqlserver" && ts.accept("AS"):
+			trigger.Body = p.restText(ts)
+
+		case p.dialect == "mysql" && (ts.accept("FOLLOWS") || ts.accept("PRECEDES")):
+			ts.next()
+
+		case p.dialect == "mysql" && trigger.ForEach != "":
+			trigger.Body = p.restText(ts)
+
+		case p.dialect == "sqlite" && ts.peek().Is("BEGIN"):
+			trigger.Body = p.restText(ts)
+
+		default:
+			ts.skipElement()
+		}
+	}
+	if trigger.Table == "" {
+		return ts.errorf(ts.peek(), "expected ON table in trigger %s", name)
+	}
+	if trigger.Body == "" {
+		return ts.errorf(ts.peek(), "expected body of trigger %s", name)
+	}
+	trigger.Definition = p.sourceText(ts, start, len(ts.tokens))
+
+	if existing := s.trigger(schemaName, name); existing != nil {
+		*existing = trigger
+		return nil
+	}
+	s.Triggers = append(s.Triggers, trigger)
+	return nil
+}
+
+func isTriggerEvent(tok Token) bool {
+	return tok.Is("INSERT") || tok.Is("UPDATE") || tok.Is("DELETE") || tok.Is("TRUNCATE")
+}
+
+// sourceText returns the source of the tokens from index from up to but
+// not including index to, preserving the original layout.
+func (p *Parser) sourceText(ts *tokenStream, from, to int) string {
+	if from >= to || to > len(ts.tokens) {
+		return ""
+	}
+	return p.src[ts.tokens[from].Pos.Offset:ts.tokens[to-1].End()]
+}
+
+// restText consumes the rest of the statement and returns its source.
+func (p *Parser) restText(ts *tokenStream) string {
+	from := ts.pos
+	ts.rest()
+	return p.sourceText(ts, from, len(ts.tokens))
+}
+
+// ArgTypes returns the normalized types of the routine's input parameters,
+// which together with its name identify a PostgreSQL overload.
+func (r *Routine) ArgTypes() []string {
+	return routineArgTypes(r.Signature)
+}
+
+// routineArgTypes extracts the input parameter types from a parameter list
+// such as (IN a integer, b text DEFAULT 'x') or (integer, text).
+func routineArgTypes(signature string) []string {
+	tokens, err := NewLexer(signature, "postgres").Tokenize()
+	if err != nil {
+		return nil
+	}
+	ts := newTokenStream(tokens)
+	if !ts.peek().IsPunct("(") {
+		return nil
+	}
+	groups, err := ts.parseParenGroups()
+	if err != nil {
+		return nil
+	}
+
+	types := []string{}
+	for _, group := range groups {
+		// Drop the default
+		for i, tok := range group {
+			if tok.Is("DEFAULT") || tok.IsPunct("=") {
+				group = group[:i]
+				break
+			}
+		}
+		if len(group) == 0 {
+			continue
+		}
+
+		mode := strings.ToUpper(group[0].Value)
+		switch mode {
+		case "OUT":
+			continue
+		case "IN", "INOUT", "VARIADIC":
+			group = group[1:]
+		}
+		if len(group) > 1 && isParamName(group[0], group[1]) {
+			group = group[1:]
+		}
+		types = append(types, strings.ToLower(joinTokens(group)))
+	}
+	return types
+}
+
+// isParamName reports whether a parameter's first token is its name rather
+// than the start of its type, given the token that follows.
+func isParamName(first, second Token) bool {
+	if first.Kind != TokenIdent && first.Kind != TokenQuotedIdent {
+		return false
+	}
+	if second.Kind == TokenPunct {
+		return false
+	}
+	for _, kw := range []string{"DOUBLE", "CHARACTER", "CHAR", "NATIONAL", "BIT", "TIMESTAMP", "TIME", "INTERVAL"} {
+		if first.Kind == TokenIdent && first.Is(kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// routine finds an existing routine that a CREATE OR REPLACE of r replaces.
+func (s *Schema) routine(r *Routine) *Routine {
+	for i := range s.Routines {
+		existing := &s.Routines[i]
+		if existing.Kind == r.Kind && sameObject(r.Schema, r.Name, existing.Schema, existing.Name) &&
+			sameArgTypes(existing.ArgTypes(), r.ArgTypes()) {
+			return existing
+		}
+	}
+	return nil
+}
+
+// dropRoutine removes the routines of a kind with the given name. With
+// args, only the overload taking those argument types is removed.
+func (s *Schema) dropRoutine(kind, schemaName, name, args string) {
+	var routines []Routine
+	for _, r := range s.Routines {
+		if r.Kind == kind && sameObject(schemaName, name, r.Schema, r.Name) &&
+			(args == "" || sameArgTypes(r.ArgTypes(), routineArgTypes(args))) {
+			continue
+		}
+		routines = append(routines, r)
+	}
+	s.Routines = routines
+}
+
+func sameArgTypes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Schema) trigger(schemaName, name string) *Trigger {
+	for i := range s.Triggers {
+		t := &s.Triggers[i]
+		if sameObject(schemaName, name, t.Schema, t.Name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// dropTrigger removes a trigger, optionally only from the named table.
+func (s *Schema) dropTrigger(schemaName, name, table string) {
+	var triggers []Trigger
+	for _, t := range s.Triggers {
+		if sameObject(schemaName, name, t.Schema, t.Name) && (table == "" || strings.EqualFold(table, t.Table)) {
+			continue
+		}
+		triggers = append(triggers, t)
+	}
+	s.Triggers = triggers
+}
+
+// GenerateRoutine returns the CREATE statement for a routine. With replace,
+// PostgreSQL and SQL Server replace an existing routine in place.
+func (g *Generator) GenerateRoutine(r *Routine, replace bool) string {
+	return g.wrapBody(g.createPrefix(replace)+r.Definition, r.Body)
+}
+
+// GenerateTrigger returns the CREATE statement for a trigger. With replace,
+// SQL Server replaces an existing trigger in place.
+func (g *Generator) GenerateTrigger(t *Trigger, replace bool) string {
+	return g.wrapBody(g.createPrefix(replace && g.dialect == "sqlserver")+t.Definition, t.Body)
+}
+
+func (g *Generator) createPrefix(replace bool) string {
+	if !replace {
+		return "CREATE "
+	}
+	switch g.dialect {
+	case "postgres":
+		return "CREATE OR REPLACE "
+	case "sqlserver":
+		return "CREATE OR ALTER "
+	default:
+		return "CREATE "
+	}
+}
+
+// wrapBody terminates a statement whose body may contain semicolons: MySQL
+// switches the client delimiter and SQL Server runs it in its own batch.
+func (g *Generator) wrapBody(stmt, body string) string {
+	switch g.dialect {
+	case "mysql":
+		if strings.Contains(body, ";") {
+			return "DELIMITER //\n" + stmt + " //\nDELIMITER ;"
+		}
+	case "sqlserver":
+		return "GO\n" + stmt + "\nGO"
+	}
+	return stmt + ";"
+}
+
+// GenerateDropRoutine returns the DROP statement for a routine.
+func (g *Generator) GenerateDropRoutine(r *Routine) string {
+	name := g.qualifiedName(r.Schema, r.Name)
+	if g.dialect == "postgres" {
+		name += "(" + strings.Join(r.ArgTypes(), ", ") + ")"
+	}
+	return fmt.Sprintf("DROP %s IF EXISTS %s;", r.Kind, name)
+}
+
+// GenerateDropTrigger returns the DROP statement for a trigger.
+func (g *Generator) GenerateDropTrigger(t *Trigger) string {
+	if g.dialect == "postgres" {
+		return fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s;", g.quoteName(t.Name), g.qualifiedName(t.Schema, t.Table))
+	}
+	return fmt.Sprintf("DROP TRIGGER IF EXISTS %s;", g.qualifiedName(t.Schema, t.Name))
+}
@@ -13,9 +13,15 @@ import (
 
 // Schema represents a complete database schema.
 type Schema struct {
-	Tables  []Table  `json:"tables" yaml:"tables"`
-	Indexes []Index  `json:"indexes,omitempty" yaml:"indexes,omitempty"`
-	Views   []View   `json:"views,omitempty" yaml:"views,omitempty"`
+	Schemas   []string   `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	Tables    []Table    `json:"tables" yaml:"tables"`
+	Indexes   []Index    `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+	Views     []View     `json:"views,omitempty" yaml:"views,omitempty"`
+	Enums     []Enum     `json:"enums,omitempty" yaml:"enums,omitempty"`
+	Domains   []Domain   `json:"domains,omitempty" yaml:"domains,omitempty"`
+	Sequences []Sequence `json:"sequences,omitempty" yaml:"sequences,omitempty"`
+	Routines  []Routine  `json:"routines,omitempty" yaml:"routines,omitempty"`
+	Triggers  []Trigger  `json:"triggers,omitempty" yaml:"triggers,omitempty"`
 }
 
 // Table represents a database table.
@@ -27,18 +33,75 @@ type Table struct {
 	ForeignKeys []ForeignKey `json:"foreign_keys,omitempty" yaml:"foreign_keys,omitempty"`
 	Indexes     []Index      `json:"indexes,omitempty" yaml:"indexes,omitempty"`
 	Constraints []Constraint `json:"constraints,omitempty" yaml:"constraints,omitempty"`
+	Comment     string       `json:"comment,omitempty" yaml:"comment,omitempty"`
+
+	// MySQL table options
+	Engine    string `json:"engine,omitempty" yaml:"engine,omitempty"`
+	Charset   string `json:"charset,omitempty" yaml:"charset,omitempty"`
+	Collation string `json:"collation,omitempty" yaml:"collation,omitempty"`
+
+	// SQLite table options
+	WithoutRowID bool `json:"without_rowid,omitempty" yaml:"without_rowid,omitempty"`
+	Strict       bool `json:"strict,omitempty" yaml:"strict,omitempty"`
 }
 
 // Column represents a table column.
 type Column struct {
-	Name         string  `json:"name" yaml:"name"`
-	Type         string  `json:"type" yaml:"type"`
-	Nullable     bool    `json:"nullable" yaml:"nullable"`
-	Default      *string `json:"default,omitempty" yaml:"default,omitempty"`
-	IsPrimaryKey bool    `json:"is_primary_key,omitempty" yaml:"is_primary_key,omitempty"`
-	IsUnique     bool    `json:"is_unique,omitempty" yaml:"is_unique,omitempty"`
-	IsIdentity   bool    `json:"is_identity,omitempty" yaml:"is_identity,omitempty"`
-	Comment      string  `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Name         string     `json:"name" yaml:"name"`
+	Type         string     `json:"type" yaml:"type"`
+	Nullable     bool       `json:"nullable" yaml:"nullable"`
+	Default      *string    `json:"default,omitempty" yaml:"default,omitempty"`
+	DefaultName  string     `json:"default_name,omitempty" yaml:"default_name,omitempty"` // SQL Server default constraint
+	OnUpdate     string     `json:"on_update,omitempty" yaml:"on_update,omitempty"`       // MySQL ON UPDATE, such as CURRENT_TIMESTAMP
+	IsPrimaryKey bool       `json:"is_primary_key,omitempty" yaml:"is_primary_key,omitempty"`
+	IsUnique     bool       `json:"is_unique,omitempty" yaml:"is_unique,omitempty"`
+	IsIdentity   bool       `json:"is_identity,omitempty" yaml:"is_identity,omitempty"`
+	Identity     *Identity  `json:"identity,omitempty" yaml:"identity,omitempty"`
+	Generated    *Generated `json:"generated,omitempty" yaml:"generated,omitempty"`
+	EnumValues   []string   `json:"enum_values,omitempty" yaml:"enum_values,omitempty"` // inline MySQL ENUM(...)
+	Comment      string     `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Charset      string     `json:"charset,omitempty" yaml:"charset,omitempty"`     // MySQL, when not the table's; SQLite
+	Collation    string     `json:"collation,omitempty" yaml:"collation,omitempty"` // MySQL, when not the table's
+}
+
+// Generated describes a generated (computed) column's expression and
+// whether its value is stored or computed on read.
+type Generated struct {
+	Expression string `json:"expression" yaml:"expression"`
+	Stored     bool   `json:"stored,omitempty" yaml:"stored,omitempty"` // STORED, PERSISTED; otherwise VIRTUAL
+}
+
+// SequenceOptions are the number generation options shared by sequences
+// and identity columns. Nil values mean the database default.
+type SequenceOptions struct {
+	Start     *int64 `json:"start,omitempty" yaml:"start,omitempty"`
+	Increment *int64 `json:"increment,omitempty" yaml:"increment,omitempty"`
+	MinValue  *int64 `json:"min_value,omitempty" yaml:"min_value,omitempty"`
+	MaxValue  *int64 `json:"max_value,omitempty" yaml:"max_value,omitempty"`
+	Cache     *int64 `json:"cache,omitempty" yaml:"cache,omitempty"`
+	Cycle     bool   `json:"cycle,omitempty" yaml:"cycle,omitempty"`
+}
+
+// IsDefault reports whether no option is set.
+func (o SequenceOptions) IsDefault() bool {
+	return o.Start == nil && o.Increment == nil && o.MinValue == nil &&
+		o.MaxValue == nil && o.Cache == nil && !o.Cycle
+}
+
+// Identity describes how an identity column generates its values
+// (GENERATED ... AS IDENTITY, SQL Server IDENTITY(seed, increment)).
+type Identity struct {
+	Generation      string `json:"generation,omitempty" yaml:"generation,omitempty"` // ALWAYS or BY DEFAULT
+	SequenceOptions `yaml:",inline"`
+}
+
+// Sequence represents a standalone sequence.
+type Sequence struct {
+	Name            string `json:"name" yaml:"name"`
+	Schema          string `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Type            string `json:"type,omitempty" yaml:"type,omitempty"`
+	SequenceOptions `yaml:",inline"`
+	OwnedBy         string `json:"owned_by,omitempty" yaml:"owned_by,omitempty"` // table.column
 }
 
 // PrimaryKey represents a primary key constraint.
@@ -49,24 +112,81 @@ type PrimaryKey struct {
 
 // ForeignKey represents a foreign key constraint.
 type ForeignKey struct {
-	Name             string   `json:"name,omitempty" yaml:"name,omitempty"`
-	Columns          []string `json:"columns" yaml:"columns"`
-	ReferencedTable  string   `json:"referenced_table" yaml:"referenced_table"`
-	ReferencedSchema string   `json:"referenced_schema,omitempty" yaml:"referenced_schema,omitempty"`
-	ReferencedCols   []string `json:"referenced_columns" yaml:"referenced_columns"`
-	OnDelete         string   `json:"on_delete,omitempty" yaml:"on_delete,omitempty"`
-	OnUpdate         string   `json:"on_update,omitempty" yaml:"on_update,omitempty"`
+	Name              string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Columns           []string `json:"columns" yaml:"columns"`
+	ReferencedTable   string   `json:"referenced_table" yaml:"referenced_table"`
+	ReferencedSchema  string   `json:"referenced_schema,omitempty" yaml:"referenced_schema,omitempty"`
+	ReferencedCols    []string `json:"referenced_columns" yaml:"referenced_columns"`
+	OnDelete          string   `json:"on_delete,omitempty" yaml:"on_delete,omitempty"`
+	OnUpdate          string   `json:"on_update,omitempty" yaml:"on_update,omitempty"`
+	Match             string   `json:"match,omitempty" yaml:"match,omitempty"` // FULL, PARTIAL or SIMPLE
+	Deferrable        bool     `json:"deferrable,omitempty" yaml:"deferrable,omitempty"`
+	InitiallyDeferred bool     `json:"initially_deferred,omitempty" yaml:"initially_deferred,omitempty"`
 }
 
 // Index represents a database index.
+//
+// Columns lists the index keys by name, or by expression text for
+// expression keys. Keys carries the full description of each key and is
+// only set when some key has an expression, sort order, operator class,
+// collation or prefix length.
 type Index struct {
-	Name      string   `json:"name" yaml:"name"`
-	Table     string   `json:"table" yaml:"table"`
-	Schema    string   `json:"schema,omitempty" yaml:"schema,omitempty"`
-	Columns   []string `json:"columns" yaml:"columns"`
-	IsUnique  bool     `json:"is_unique,omitempty" yaml:"is_unique,omitempty"`
-	IsPrimary bool     `json:"is_primary,omitempty" yaml:"is_primary,omitempty"`
-	Type      string   `json:"type,omitempty" yaml:"type,omitempty"` // btree, hash, gin, etc.
+	Name      string        `json:"name" yaml:"name"`
+	Table     string        `json:"table" yaml:"table"`
+	Schema    string        `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Columns   []string      `json:"columns" yaml:"columns"`
+	Keys      []IndexColumn `json:"keys,omitempty" yaml:"keys,omitempty"`
+	Include   []string      `json:"include,omitempty" yaml:"include,omitempty"`
+	Where     string        `json:"where,omitempty" yaml:"where,omitempty"` // partial index predicate
+	IsUnique  bool          `json:"is_unique,omitempty" yaml:"is_unique,omitempty"`
+	IsPrimary bool          `json:"is_primary,omitempty" yaml:"is_primary,omitempty"`
+	Type      string        `json:"type,omitempty" yaml:"type,omitempty"` // btree, hash, gin, etc.
+}
+
+// IndexColumn describes a single index key.
+type IndexColumn struct {
+	Name       string `json:"name,omitempty" yaml:"name,omitempty"`
+	Expression string `json:"expression,omitempty" yaml:"expression,omitempty"`
+	Descending bool   `json:"descending,omitempty" yaml:"descending,omitempty"`
+	Nulls      string `json:"nulls,omitempty" yaml:"nulls,omitempty"` // FIRST or LAST when not the default for the direction
+	OpClass    string `json:"opclass,omitempty" yaml:"opclass,omitempty"`
+	Collation  string `json:"collation,omitempty" yaml:"collation,omitempty"`
+	Length     int    `json:"length,omitempty" yaml:"length,omitempty"` // MySQL prefix length
+}
+
+// KeyColumns returns the description of every index key, deriving plain
+// ascending keys from Columns when Keys is not set.
+func (idx *Index) KeyColumns() []IndexColumn {
+	if len(idx.Keys) > 0 {
+		return idx.Keys
+	}
+	keys := make([]IndexColumn, len(idx.Columns))
+	for i, c := range idx.Columns {
+		keys[i] = IndexColumn{Name: c}
+	}
+	return keys
+}
+
+// IsPlain reports whether the key is a bare column in default order.
+func (k IndexColumn) IsPlain() bool {
+	return k.Expression == "" && !k.Descending && k.Nulls == "" &&
+		k.OpClass == "" && k.Collation == "" && k.Length == 0
+}
+
+// SetKeys stores keys on the index, filling Columns and leaving Keys unset
+// when every key is a plain column.
+func (idx *Index) SetKeys(keys []IndexColumn) {
+	idx.Columns = make([]string, len(keys))
+	idx.Keys = nil
+	for i, k := range keys {
+		idx.Columns[i] = k.Name
+		if k.Expression != "" {
+			idx.Columns[i] = k.Expression
+		}
+		if !k.IsPlain() {
+			idx.Keys = keys
+		}
+	}
 }
 
 // Constraint represents a table constraint.
@@ -77,6 +197,25 @@ type Constraint struct {
 	Expression string   `json:"expression,omitempty" yaml:"expression,omitempty"`
 }
 
+// Enum represents a named enumerated type (PostgreSQL CREATE TYPE ... AS ENUM).
+type Enum struct {
+	Name   string   `json:"name" yaml:"name"`
+	Schema string   `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Values []string `json:"values" yaml:"values"`
+}
+
+// Domain represents a named type derived from a base type with optional
+// default and constraints (PostgreSQL CREATE DOMAIN, SQL Server alias types).
+type Domain struct {
+	Name      string       `json:"name" yaml:"name"`
+	Schema    string       `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Type      string       `json:"type" yaml:"type"`
+	NotNull   bool         `json:"not_null,omitempty" yaml:"not_null,omitempty"`
+	Default   *string      `json:"default,omitempty" yaml:"default,omitempty"`
+	Collation string       `json:"collation,omitempty" yaml:"collation,omitempty"`
+	Checks    []Constraint `json:"checks,omitempty" yaml:"checks,omitempty"`
+}
+
 // View represents a database view.
 type View struct {
 	Name       string `json:"name" yaml:"name"`
@@ -84,13 +223,61 @@ type View struct {
 	Definition string `json:"definition" yaml:"definition"`
 }
 
+// Routine represents a stored function or procedure.
+type Routine struct {
+	Name      string `json:"name" yaml:"name"`
+	Schema    string `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Kind      string `json:"kind" yaml:"kind"` // FUNCTION or PROCEDURE
+	Signature string `json:"signature,omitempty" yaml:"signature,omitempty"`
+	Returns   string `json:"returns,omitempty" yaml:"returns,omitempty"`
+	Language  string `json:"language,omitempty" yaml:"language,omitempty"`
+	Body      string `json:"body" yaml:"body"`
+
+	// Definition is the statement as written after CREATE [OR REPLACE],
+	// starting at the FUNCTION or PROCEDURE keyword
+	Definition string `json:"definition" yaml:"definition"`
+}
+
+// Trigger represents a trigger on a table.
+type Trigger struct {
+	Name    string   `json:"name" yaml:"name"`
+	Schema  string   `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Table   string   `json:"table" yaml:"table"`
+	Timing  string   `json:"timing" yaml:"timing"` // BEFORE, AFTER or INSTEAD OF
+	Events  []string `json:"events" yaml:"events"`
+	ForEach string   `json:"for_each,omitempty" yaml:"for_each,omitempty"` // ROW or STATEMENT
+	When    string   `json:"when,omitempty" yaml:"when,omitempty"`
+	Body    string   `json:"body" yaml:"body"`
+
+	// Definition is the statement as written after CREATE [OR REPLACE],
+	// starting at the TRIGGER keyword
+	Definition string `json:"definition" yaml:"definition"`
+}
+
 // ParseFile reads and parses a SQL schema file.
 func ParseFile(path string, dialect string) (*Schema, error) {
+	s, _, err := ParseFileWithOptions(path, dialect, ParseOptions{})
+	return s, err
+}
+
+// ParseFileWithOptions reads and parses a SQL schema file. In lenient mode
+// the returned diagnostics describe the statements that were skipped; in
+// strict mode the first one is returned as a *ParseError.
+func ParseFileWithOptions(path string, dialect string, opts ParseOptions) (*Schema, []*ParseError, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("reading file: %w", err)
+		return nil, nil, fmt.Errorf("reading file: %w", err)
+	}
+	if opts.File == "" {
+		opts.File = path
 	}
-	return Parse(string(content), dialect)
+
+	parser := NewParserWithOptions(dialect, opts)
+	s, err := parser.Parse(string(content))
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, parser.Diagnostics(), nil
 }
 
 // Parse parses SQL content into a Schema.
@@ -128,8 +315,16 @@ func WriteText(w io.Writer, s *Schema) error {
 	sb.WriteString(fmt.Sprintf("Schema: %d tables, %d indexes, %d views\n\n",
 		len(s.Tables), len(s.Indexes), len(s.Views)))
 
+	if len(s.Schemas) > 0 {
+		sb.WriteString(fmt.Sprintf("Schemas: %s\n\n", strings.Join(s.Schemas, ", ")))
+	}
+
 	for _, t := range s.Tables {
-		sb.WriteString(fmt.Sprintf("Table: %s\n", t.Name))
+		name := t.Name
+		if t.Schema != "" {
+			name = t.Schema + "." + name
+		}
+		sb.WriteString(fmt.Sprintf("Table: %s\n", name))
 		sb.WriteString(strings.Repeat("-", 40) + "\n")
 
 		for _, c := range t.Columns {
@@ -0,0 +1,338 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseCreateSequence handles CREATE SEQUENCE [IF NOT EXISTS] name [options].
+func (p *Parser) parseCreateSequence(s *Schema, ts *tokenStream) error {
+	ifNotExists := ts.accept("IF", "NOT", "EXISTS")
+
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+
+	seq := Sequence{Name: name, Schema: schemaName}
+	if err := p.parseSequenceOptions(ts, &seq.SequenceOptions, &seq); err != nil {
+		return err
+	}
+	seq.clearDefaults()
+
+	if existing := s.sequence(schemaName, name); existing != nil {
+		if !ifNotExists {
+			*existing = seq
+		}
+		return nil
+	}
+	s.Sequences = append(s.Sequences, seq)
+	return nil
+}
+
+// parseAlterSequence handles ALTER SEQUENCE name {options | RENAME TO name}.
+func (p *Parser) parseAlterSequence(s *Schema, ts *tokenStream) error {
+	ts.accept("IF", "EXISTS")
+
+	nameTok := ts.peek()
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+	seq := s.sequence(schemaName, name)
+	if seq == nil {
+		return ts.errorAt(nameTok, "ALTER SEQUENCE of undefined sequence %q", name)
+	}
+
+	if ts.accept("RENAME", "TO") {
+		newName, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		seq.Name = newName
+		return nil
+	}
+	if ts.accept("OWNER", "TO") {
+		return nil
+	}
+
+	if err := p.parseSequenceOptions(ts, &seq.SequenceOptions, seq); err != nil {
+		return err
+	}
+	seq.clearDefaults()
+	return nil
+}
+
+// parseSequenceOptions parses sequence generation options up to the end of
+// the stream or a closing parenthesis. The AS type and OWNED BY clauses
+// are only recorded when seq is given; identity columns pass nil.
+func (p *Parser) parseSequenceOptions(ts *tokenStream, opts *SequenceOptions, seq *Sequence) error {
+	for !ts.atEnd() && !ts.peek().IsPunct(")") {
+		if err := p.parseSequenceOption(ts, opts, seq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseSequenceOption parses a single sequence generation option.
+func (p *Parser) parseSequenceOption(ts *tokenStream, opts *SequenceOptions, seq *Sequence) error {
+	var err error
+	switch {
+	case ts.accept("AS"):
+		dataType, err := p.parseDataType(ts)
+		if err != nil {
+			return err
+		}
+		if seq != nil {
+			seq.Type = dataType
+		}
+
+	case ts.accept("INCREMENT"):
+		ts.accept("BY")
+		opts.Increment, err = parseSignedInt(ts)
+
+	case ts.accept("START"):
+		ts.accept("WITH")
+		opts.Start, err = parseSignedInt(ts)
+
+	case ts.accept("RESTART"):
+		// Only moves the current value
+		if ts.accept("WITH") || ts.peek().Kind == TokenNumber || ts.peek().IsPunct("-") {
+			_, err = parseSignedInt(ts)
+		}
+
+	case ts.accept("MINVALUE"):
+		opts.MinValue, err = parseSignedInt(ts)
+
+	case ts.accept("MAXVALUE"):
+		opts.MaxValue, err = parseSignedInt(ts)
+
+	case ts.accept("NO", "MINVALUE"):
+		opts.MinValue = nil
+
+	case ts.accept("NO", "MAXVALUE"):
+		opts.MaxValue = nil
+
+	case ts.accept("CACHE"):
+		opts.Cache, err = parseSignedInt(ts)
+
+	case ts.accept("NO", "CACHE") || ts.accept("NOCACHE"):
+		opts.Cache = nil
+
+	case ts.accept("CYCLE"):
+		opts.Cycle = true
+
+	case ts.accept("NO", "CYCLE") || ts.accept("NOCYCLE"):
+		opts.Cycle = false
+
+	case ts.accept("OWNED", "BY"):
+		if ts.accept("NONE") {
+			if seq != nil {
+				seq.OwnedBy = ""
+			}
+			return nil
+		}
+		var parts []string
+		for {
+			part, err := ts.parseIdent()
+			if err != nil {
+				return err
+			}
+			parts = append(parts, part)
+			if !ts.acceptPunct(".") {
+				break
+			}
+		}
+		if len(parts) > 2 {
+			parts = parts[len(parts)-2:]
+		}
+		if seq != nil {
+			seq.OwnedBy = strings.Join(parts, ".")
+		}
+
+	case ts.accept("SEQUENCE", "NAME"):
+		// pg_dump names the sequence backing an identity column
+		_, _, err = ts.parseQualifiedName()
+
+	case ts.accept("LOGGED") || ts.accept("UNLOGGED"):
+
+	default:
+		return ts.errorf(ts.peek(), "unexpected sequence option")
+	}
+	return err
+}
+
+// isIdentityAlteration reports whether an ALTER COLUMN action changes the
+// options of an identity column.
+func isIdentityAlteration(ts *tokenStream) bool {
+	tok := ts.peek()
+	if tok.Is("RESTART") {
+		return true
+	}
+	if !tok.Is("SET") {
+		return false
+	}
+	next := ts.peekN(1)
+	for _, kw := range []string{"GENERATED", "INCREMENT", "START", "MINVALUE", "MAXVALUE", "NO", "CACHE", "CYCLE"} {
+		if next.Is(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIdentityOptions parses the parenthesized options of
+// GENERATED ... AS IDENTITY, if present.
+func (p *Parser) parseIdentityOptions(ts *tokenStream, identity *Identity) error {
+	if !ts.acceptPunct("(") {
+		return nil
+	}
+	if err := p.parseSequenceOptions(ts, &identity.SequenceOptions, nil); err != nil {
+		return err
+	}
+	identity.clearDefaults()
+	return ts.expectPunct(")")
+}
+
+// clearDefaults unsets options that spell out the default for an
+// ascending sequence, as pg_dump writes them, so they compare equal to
+// omitted options.
+func (o *SequenceOptions) clearDefaults() {
+	isOne := func(v *int64) bool { return v != nil && *v == 1 }
+	if isOne(o.Increment) {
+		o.Increment = nil
+	}
+	if o.Increment == nil && isOne(o.Start) {
+		o.Start = nil
+	}
+	if o.Increment == nil && isOne(o.MinValue) {
+		o.MinValue = nil
+	}
+	if isOne(o.Cache) {
+		o.Cache = nil
+	}
+}
+
+// parseIdentityGeneration parses {ALWAYS | BY DEFAULT} after GENERATED.
+func parseIdentityGeneration(ts *tokenStream) string {
+	if ts.accept("ALWAYS") {
+		return "ALWAYS"
+	}
+	if ts.accept("BY", "DEFAULT") {
+		return "BY DEFAULT"
+	}
+	return ""
+}
+
+func parseSignedInt(ts *tokenStream) (*int64, error) {
+	sign := int64(1)
+	if ts.acceptPunct("-") {
+		sign = -1
+	} else {
+		ts.acceptPunct("+")
+	}
+
+	tok := ts.peek()
+	if tok.Kind != TokenNumber {
+		return nil, ts.errorf(tok, "expected integer")
+	}
+	n, err := strconv.ParseInt(tok.Value, 10, 64)
+	if err != nil {
+		return nil, ts.errorf(tok, "expected integer")
+	}
+	ts.next()
+
+	n *= sign
+	return &n, nil
+}
+
+func (s *Schema) sequence(schemaName, name string) *Sequence {
+	for i := range s.Sequences {
+		seq := &s.Sequences[i]
+		if sameObject(schemaName, name, seq.Schema, seq.Name) {
+			return seq
+		}
+	}
+	return nil
+}
+
+// dropSequence removes a sequence.
+func (s *Schema) dropSequence(schemaName, name string) {
+	var sequences []Sequence
+	for _, seq := range s.Sequences {
+		if !sameObject(schemaName, name, seq.Schema, seq.Name) {
+			sequences = append(sequences, seq)
+		}
+	}
+	s.Sequences = sequences
+}
+
+// SequenceDefaultName returns the sequence a nextval('seq'::regclass)
+// default draws from.
+func SequenceDefaultName(expr string) (string, string, bool) {
+	if !isSequenceDefault(expr) {
+		return "", "", false
+	}
+	start := strings.Index(expr, "'")
+	end := strings.LastIndex(expr, "'")
+	if start < 0 || end <= start {
+		return "", "", false
+	}
+	schemaName, name := splitTypeName(expr[start+1 : end])
+	return schemaName, name, true
+}
+
+// CollapseSerials folds sequences that implement SERIAL columns, as
+// pg_dump and the catalogs describe them (a sequence OWNED BY the column
+// plus a nextval default), back into SERIAL column types.
+func (s *Schema) CollapseSerials() {
+	var sequences []Sequence
+	for _, seq := range s.Sequences {
+		if !s.collapseSerial(&seq) {
+			sequences = append(sequences, seq)
+		}
+	}
+	s.Sequences = sequences
+}
+
+func (s *Schema) collapseSerial(seq *Sequence) bool {
+	if seq.OwnedBy == "" || !isDefaultSerialSequence(seq) {
+		return false
+	}
+	tableName, colName := splitTypeName(seq.OwnedBy)
+	table := s.table(seq.Schema, tableName)
+	if table == nil {
+		return false
+	}
+	col := table.column(colName)
+	if col == nil || col.Default == nil {
+		return false
+	}
+	seqSchema, seqName, ok := SequenceDefaultName(*col.Default)
+	if !ok || !sameObject(seqSchema, seqName, seq.Schema, seq.Name) {
+		return false
+	}
+
+	serial := map[string]string{
+		"INTEGER": "SERIAL", "INT": "SERIAL", "INT4": "SERIAL",
+		"BIGINT": "BIGSERIAL", "INT8": "BIGSERIAL",
+		"SMALLINT": "SMALLSERIAL", "INT2": "SMALLSERIAL",
+	}[strings.ToUpper(col.Type)]
+	if serial == "" {
+		return false
+	}
+
+	col.Type = serial
+	col.Default = nil
+	col.IsIdentity = true
+	return true
+}
+
+// isDefaultSerialSequence reports whether a sequence has the options
+// CREATE TABLE gives the sequence of a SERIAL column.
+func isDefaultSerialSequence(seq *Sequence) bool {
+	isOne := func(v *int64) bool { return v == nil || *v == 1 }
+	return isOne(seq.Start) && isOne(seq.Increment) && isOne(seq.Cache) &&
+		isOne(seq.MinValue) && seq.MaxValue == nil && !seq.Cycle
+}
@@ -0,0 +1,196 @@
+package schema
+
+import "strings"
+
+// splitter groups the tokens of a script into statements.
+type splitter struct {
+	src     string
+	dialect string
+
+	// delimiter ends a statement; MySQL scripts change it with DELIMITER
+	delimiter string
+
+	// depth counts the BEGIN ... END and CASE ... END blocks open in the
+	// body of a routine, within which semicolons do not end the statement
+	depth int
+
+	statements [][]Token
+	current    []Token
+}
+
+// splitStatements groups tokens into statements. Statements end at a
+// semicolon outside routine bodies, or at the delimiter set by a MySQL
+// DELIMITER directive. SQL Server GO [count] commands end a batch, and the
+// bodies of SQL Server routines and triggers run to the end of their batch.
+// Dollar-quoted bodies and nested block comments are already single tokens
+// or skipped by the lexer.
+func splitStatements(src string, tokens []Token, dialect string) [][]Token {
+	sp := &splitter{src: src, dialect: dialect, delimiter: ";"}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Kind == TokenEOF {
+			break
+		}
+
+		switch {
+		case dialect == "mysql" && len(sp.current) == 0 && tok.Is("DELIMITER"):
+			i = sp.setDelimiter(tokens, i)
+
+		case dialect == "sqlserver" && isBatchSeparator(tokens, i):
+			if i+1 < len(tokens) && tokens[i+1].Kind == TokenNumber && tokens[i+1].Pos.Line == tok.Pos.Line {
+				// GO count repeats the batch
+				i++
+			}
+			sp.flush()
+
+		case sp.delimiter != ";":
+			i = sp.splitAtDelimiter(tokens, i)
+
+		case tok.IsPunct(";") && sp.endsStatement():
+			sp.flush()
+
+		default:
+			sp.trackBlocks(tokens, i)
+			sp.current = append(sp.current, tok)
+		}
+	}
+	sp.flush()
+
+	return sp.statements
+}
+
+func (sp *splitter) flush() {
+	for len(sp.current) > 0 && sp.current[len(sp.current)-1].IsPunct(";") {
+		sp.current = sp.current[:len(sp.current)-1]
+	}
+	if len(sp.current) > 0 {
+		sp.statements = append(sp.statements, sp.current)
+	}
+	sp.current = nil
+	sp.depth = 0
+}
+
+// setDelimiter handles the MySQL client directive DELIMITER x, which sets
+// the statement terminator to the rest of its line. It returns the index of
+// the directive's last token.
+func (sp *splitter) setDelimiter(tokens []Token, i int) int {
+	tok := tokens[i]
+	line := sp.src[tok.End():]
+	if end := strings.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+	if d := strings.TrimSpace(line); d != "" {
+		sp.delimiter = d
+	}
+	for i+1 < len(tokens) && tokens[i+1].Kind != TokenEOF && tokens[i+1].Pos.Line == tok.Pos.Line {
+		i++
+	}
+	return i
+}
+
+// splitAtDelimiter adds the token at i to the current statement, ending it
+// if the token starts the custom delimiter. It returns the index of the
+// last token consumed.
+func (sp *splitter) splitAtDelimiter(tokens []Token, i int) int {
+	tok := tokens[i]
+	switch {
+	case tok.Kind == TokenPunct && strings.HasPrefix(sp.src[tok.Pos.Offset:], sp.delimiter):
+		// The delimiter may span several tokens, as // or ;; do
+		end := tok.Pos.Offset + len(sp.delimiter)
+		for i+1 < len(tokens) && tokens[i+1].Kind != TokenEOF && tokens[i+1].Pos.Offset < end {
+			i++
+		}
+		sp.flush()
+
+	case tok.Kind == TokenIdent && len(tok.Raw) > len(sp.delimiter) && strings.HasSuffix(tok.Raw, sp.delimiter):
+		// A delimiter made of identifier characters, as in END$$
+		tok.Raw = tok.Raw[:len(tok.Raw)-len(sp.delimiter)]
+		tok.Value = tok.Raw
+		sp.current = append(sp.current, tok)
+		sp.flush()
+
+	default:
+		sp.current = append(sp.current, tok)
+	}
+	return i
+}
+
+// endsStatement reports whether a semicolon ends the current statement.
+// Inside a routine or trigger it does so only outside BEGIN ... END blocks;
+// SQL Server bodies run to the end of the batch.
+func (sp *splitter) endsStatement() bool {
+	if !isRoutineStatement(sp.current) {
+		return true
+	}
+	return sp.dialect != "sqlserver" && sp.depth == 0
+}
+
+// trackBlocks updates the block depth of a routine body for the token at i.
+// MySQL's END IF, END LOOP, END WHILE and END REPEAT close blocks that are
+// not counted, and END CASE closes the counted CASE.
+func (sp *splitter) trackBlocks(tokens []Token, i int) {
+	if sp.dialect == "sqlserver" || !isRoutineStatement(sp.current) {
+		return
+	}
+	tok := tokens[i]
+	next := Token{}
+	if i+1 < len(tokens) {
+		next = tokens[i+1]
+	}
+
+	switch {
+	case tok.Is("BEGIN"):
+		if !next.Is("TRANSACTION") && !next.Is("WORK") {
+			sp.depth++
+		}
+	case tok.Is("CASE"):
+		if i == 0 || !tokens[i-1].Is("END") {
+			sp.depth++
+		}
+	case tok.Is("END"):
+		if !next.Is("IF") && !next.Is("LOOP") && !next.Is("WHILE") && !next.Is("REPEAT") && sp.depth > 0 {
+			sp.depth--
+		}
+	}
+}
+
+// isBatchSeparator reports whether the token at i is a SQL Server GO
+// command, which must stand alone on its line apart from a repeat count.
+func isBatchSeparator(tokens []Token, i int) bool {
+	tok := tokens[i]
+	if !tok.Is("GO") {
+		return false
+	}
+	if i > 0 && tokens[i-1].Pos.Line == tok.Pos.Line {
+		return false
+	}
+	rest := tokens[i+1:]
+	if len(rest) > 0 && rest[0].Kind == TokenNumber && rest[0].Pos.Line == tok.Pos.Line {
+		rest = rest[1:]
+	}
+	return len(rest) == 0 || rest[0].Kind == TokenEOF || rest[0].Pos.Line != tok.Pos.Line
+}
+
+// isRoutineStatement reports whether a statement creates a routine or
+// trigger, whose body may contain semicolons.
+func isRoutineStatement(tokens []Token) bool {
+	ts := newTokenStream(tokens)
+	if !ts.accept("CREATE") && !ts.accept("ALTER") {
+		return false
+	}
+	ts.accept("OR", "ALTER")
+	ts.accept("OR", "REPLACE")
+	ts.acceptAny("TEMP", "TEMPORARY")
+	for ts.accept("DEFINER") {
+		// MySQL: DEFINER = user@host
+		ts.acceptPunct("=")
+		ts.next()
+		if ts.acceptPunct("@") {
+			ts.next()
+		}
+	}
+	ts.accept("CONSTRAINT")
+	_, ok := ts.acceptAny("FUNCTION", "PROCEDURE", "PROC", "TRIGGER")
+	return ok
+}
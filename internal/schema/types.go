@@ -0,0 +1,402 @@
+package schema
+
+import "strings"
+
+// parseCreateType handles CREATE TYPE. PostgreSQL enums become Enum
+// objects and SQL Server alias types (CREATE TYPE name FROM base) become
+// Domain objects; composite and range types are not modeled.
+func (p *Parser) parseCreateType(s *Schema, ts *tokenStream) error {
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case ts.accept("AS", "ENUM"):
+		groups, err := ts.parseParenGroups()
+		if err != nil {
+			return err
+		}
+		enum := Enum{Name: name, Schema: schemaName, Values: []string{}}
+		for _, g := range groups {
+			if len(g) != 1 || g[0].Kind != TokenString {
+				return newTokenStream(g).errorf(firstToken(g, ts.peek()), "expected enum label")
+			}
+			enum.Values = append(enum.Values, g[0].Value)
+		}
+		if existing := s.enum(schemaName, name); existing != nil {
+			*existing = enum
+			return nil
+		}
+		s.Enums = append(s.Enums, enum)
+
+	case p.dialect == "sqlserver" && ts.accept("FROM"):
+		domain := Domain{Name: name, Schema: schemaName}
+		domain.Type, err = p.parseDataType(ts)
+		if err != nil {
+			return err
+		}
+		if ts.accept("NOT", "NULL") {
+			domain.NotNull = true
+		}
+		s.addDomain(domain)
+	}
+
+	return nil
+}
+
+// parseCreateDomain handles PostgreSQL's
+//
+//	CREATE DOMAIN name [AS] type [COLLATE c] [DEFAULT expr]
+//	    [[CONSTRAINT name] {NOT NULL | NULL | CHECK (expr)}]...
+func (p *Parser) parseCreateDomain(s *Schema, ts *tokenStream) error {
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+	ts.accept("AS")
+
+	domain := Domain{Name: name, Schema: schemaName}
+	domain.Type, err = p.parseDataType(ts)
+	if err != nil {
+		return err
+	}
+
+	constraintName := ""
+	for !ts.atEnd() {
+		switch {
+		case ts.accept("CONSTRAINT"):
+			constraintName, err = ts.parseIdent()
+			if err != nil {
+				return err
+			}
+			continue
+
+		case ts.accept("COLLATE"):
+			domain.Collation = ts.next().Raw
+
+		case ts.accept("DEFAULT"):
+			expr := ts.parseExpr(isDomainConstraintStart)
+			if len(expr) == 0 {
+				return ts.errorf(ts.peek(), "expected default expression")
+			}
+			def := joinTokens(expr)
+			domain.Default = &def
+
+		case ts.accept("NOT", "NULL"):
+			domain.NotNull = true
+
+		case ts.accept("NULL"):
+			domain.NotNull = false
+
+		case ts.accept("CHECK"):
+			check, err := p.parseCheckConstraint(ts)
+			if err != nil {
+				return err
+			}
+			check.Name = constraintName
+			domain.Checks = append(domain.Checks, *check)
+
+		default:
+			return ts.errorf(ts.peek(), "unexpected domain option")
+		}
+		constraintName = ""
+	}
+
+	s.addDomain(domain)
+	return nil
+}
+
+func isDomainConstraintStart(ts *tokenStream) bool {
+	tok := ts.peek()
+	return tok.Is("CONSTRAINT") || tok.Is("CHECK") || tok.Is("COLLATE") ||
+		tok.Is("NULL") || (tok.Is("NOT") && ts.peekN(1).Is("NULL"))
+}
+
+// parseAlterType handles ALTER TYPE ... ADD VALUE, RENAME VALUE and
+// RENAME TO for enums.
+func (p *Parser) parseAlterType(s *Schema, ts *tokenStream) error {
+	nameTok := ts.peek()
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+	enum := s.enum(schemaName, name)
+
+	switch {
+	case ts.accept("ADD", "VALUE"):
+		ifNotExists := ts.accept("IF", "NOT", "EXISTS")
+		label, err := parseStringLiteral(ts)
+		if err != nil {
+			return err
+		}
+		if enum == nil {
+			return ts.errorAt(nameTok, "ALTER TYPE of undefined enum %q", name)
+		}
+		if containsValue(enum.Values, label) {
+			if ifNotExists {
+				return nil
+			}
+			return ts.errorAt(nameTok, "enum label %q already exists in %q", label, name)
+		}
+
+		pos := len(enum.Values)
+		if where, ok := ts.acceptAny("BEFORE", "AFTER"); ok {
+			neighbor, err := parseStringLiteral(ts)
+			if err != nil {
+				return err
+			}
+			for i, v := range enum.Values {
+				if v == neighbor {
+					pos = i
+					if where == "AFTER" {
+						pos++
+					}
+				}
+			}
+		}
+		enum.Values = append(enum.Values[:pos], append([]string{label}, enum.Values[pos:]...)...)
+
+	case ts.accept("RENAME", "VALUE"):
+		oldLabel, err := parseStringLiteral(ts)
+		if err != nil {
+			return err
+		}
+		if err := ts.expect("TO"); err != nil {
+			return err
+		}
+		newLabel, err := parseStringLiteral(ts)
+		if err != nil {
+			return err
+		}
+		if enum == nil {
+			return ts.errorAt(nameTok, "ALTER TYPE of undefined enum %q", name)
+		}
+		for i, v := range enum.Values {
+			if v == oldLabel {
+				enum.Values[i] = newLabel
+			}
+		}
+
+	case ts.accept("RENAME", "TO"):
+		newName, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		if enum != nil {
+			s.renameType(enum.Name, newName)
+			enum.Name = newName
+		} else if domain := s.domain(schemaName, name); domain != nil {
+			s.renameType(domain.Name, newName)
+			domain.Name = newName
+		}
+	}
+
+	return nil
+}
+
+// parseAlterDomain handles ALTER DOMAIN ... {SET|DROP} DEFAULT,
+// {SET|DROP} NOT NULL, ADD CONSTRAINT and DROP CONSTRAINT.
+func (p *Parser) parseAlterDomain(s *Schema, ts *tokenStream) error {
+	nameTok := ts.peek()
+	schemaName, name, err := ts.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+	domain := s.domain(schemaName, name)
+	if domain == nil {
+		return ts.errorAt(nameTok, "ALTER DOMAIN of undefined domain %q", name)
+	}
+
+	switch {
+	case ts.accept("SET", "DEFAULT"):
+		def := joinTokens(ts.rest())
+		domain.Default = &def
+	case ts.accept("DROP", "DEFAULT"):
+		domain.Default = nil
+	case ts.accept("SET", "NOT", "NULL"):
+		domain.NotNull = true
+	case ts.accept("DROP", "NOT", "NULL"):
+		domain.NotNull = false
+	case ts.accept("ADD"):
+		constraintName := ""
+		if ts.accept("CONSTRAINT") {
+			constraintName, err = ts.parseIdent()
+			if err != nil {
+				return err
+			}
+		}
+		if ts.accept("NOT", "NULL") {
+			domain.NotNull = true
+			return nil
+		}
+		if err := ts.expect("CHECK"); err != nil {
+			return err
+		}
+		check, err := p.parseCheckConstraint(ts)
+		if err != nil {
+			return err
+		}
+		check.Name = constraintName
+		domain.Checks = append(domain.Checks, *check)
+	case ts.accept("DROP", "CONSTRAINT"):
+		ts.accept("IF", "EXISTS")
+		constraintName, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		var checks []Constraint
+		for _, c := range domain.Checks {
+			if !strings.EqualFold(c.Name, constraintName) {
+				checks = append(checks, c)
+			}
+		}
+		domain.Checks = checks
+	case ts.accept("RENAME", "TO"):
+		newName, err := ts.parseIdent()
+		if err != nil {
+			return err
+		}
+		s.renameType(domain.Name, newName)
+		domain.Name = newName
+	}
+
+	return nil
+}
+
+func parseStringLiteral(ts *tokenStream) (string, error) {
+	tok := ts.peek()
+	if tok.Kind != TokenString {
+		return "", ts.errorf(tok, "expected string literal")
+	}
+	ts.next()
+	return tok.Value, nil
+}
+
+func firstToken(tokens []Token, fallback Token) Token {
+	if len(tokens) > 0 {
+		return tokens[0]
+	}
+	return fallback
+}
+
+// InlineEnumValues returns the labels of a MySQL ENUM('a','b') type.
+func InlineEnumValues(dataType string) []string {
+	if !strings.HasPrefix(strings.ToUpper(dataType), "ENUM(") {
+		return nil
+	}
+	tokens, err := NewLexer(dataType, "mysql").Tokenize()
+	if err != nil {
+		return nil
+	}
+	values := []string{}
+	for _, tok := range tokens {
+		if tok.Kind == TokenString {
+			values = append(values, tok.Value)
+		}
+	}
+	return values
+}
+
+func containsValue(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) enum(schemaName, name string) *Enum {
+	for i := range s.Enums {
+		e := &s.Enums[i]
+		if sameObject(schemaName, name, e.Schema, e.Name) {
+			return e
+		}
+	}
+	return nil
+}
+
+func (s *Schema) domain(schemaName, name string) *Domain {
+	for i := range s.Domains {
+		d := &s.Domains[i]
+		if sameObject(schemaName, name, d.Schema, d.Name) {
+			return d
+		}
+	}
+	return nil
+}
+
+func (s *Schema) addDomain(domain Domain) {
+	if existing := s.domain(domain.Schema, domain.Name); existing != nil {
+		*existing = domain
+		return
+	}
+	s.Domains = append(s.Domains, domain)
+}
+
+// renameType updates the columns declared with a renamed enum or domain.
+func (s *Schema) renameType(oldName, newName string) {
+	for i := range s.Tables {
+		for j := range s.Tables[i].Columns {
+			col := &s.Tables[i].Columns[j]
+			schemaName, name := splitTypeName(col.Type)
+			if !strings.EqualFold(name, oldName) {
+				continue
+			}
+			suffix := ""
+			if k := strings.Index(col.Type, "["); k >= 0 {
+				suffix = col.Type[k:]
+			}
+			col.Type = newName + suffix
+			if schemaName != "" {
+				col.Type = schemaName + "." + col.Type
+			}
+		}
+	}
+}
+
+// dropEnum removes an enum type.
+func (s *Schema) dropEnum(schemaName, name string) {
+	var enums []Enum
+	for _, e := range s.Enums {
+		if !sameObject(schemaName, name, e.Schema, e.Name) {
+			enums = append(enums, e)
+		}
+	}
+	s.Enums = enums
+}
+
+// dropDomain removes a domain.
+func (s *Schema) dropDomain(schemaName, name string) {
+	var domains []Domain
+	for _, d := range s.Domains {
+		if !sameObject(schemaName, name, d.Schema, d.Name) {
+			domains = append(domains, d)
+		}
+	}
+	s.Domains = domains
+}
+
+// LookupEnum returns the enum a column type refers to, or nil.
+func (s *Schema) LookupEnum(columnType string) *Enum {
+	schemaName, name := splitTypeName(columnType)
+	return s.enum(schemaName, name)
+}
+
+// LookupDomain returns the domain a column type refers to, or nil.
+func (s *Schema) LookupDomain(columnType string) *Domain {
+	schemaName, name := splitTypeName(columnType)
+	return s.domain(schemaName, name)
+}
+
+func splitTypeName(t string) (string, string) {
+	if i := strings.Index(t, "["); i >= 0 {
+		t = t[:i]
+	}
+	t = strings.ReplaceAll(t, `"`, "")
+	if i := strings.LastIndex(t, "."); i >= 0 {
+		return t[:i], t[i+1:]
+	}
+	return "", t
+}
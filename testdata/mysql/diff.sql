@@ -0,0 +1,29 @@
+-- Migration SQL
+-- Dialect: mysql
+
+CREATE TABLE `tags` (
+    `id` INT AUTO_INCREMENT NOT NULL PRIMARY KEY,
+    `name` VARCHAR(50) NOT NULL UNIQUE
+);
+
+
+DROP INDEX `idx_comments_post_id` ON `comments`;
+DROP INDEX `idx_posts_user_id` ON `posts`;
+ALTER TABLE `users` RENAME COLUMN `name` TO `display_name`;
+ALTER TABLE `users` DROP COLUMN `updated_at`;
+ALTER TABLE `users` MODIFY COLUMN `email` VARCHAR(320) NOT NULL;
+
+ALTER TABLE `users` MODIFY COLUMN `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE `users` ADD CHECK (email <> '');
+
+ALTER TABLE `posts` DROP FOREIGN KEY `posts_ibfk_1`;
+ALTER TABLE `posts` MODIFY COLUMN `published` TINYINT(1) DEFAULT 1;
+
+ALTER TABLE `posts` ADD FOREIGN KEY (`user_id`) REFERENCES `users` (`id`);
+
+CREATE INDEX `idx_posts_user_id` ON `posts` (`user_id`, `created_at`);
+CREATE VIEW `published_posts` AS
+SELECT id, title FROM posts WHERE published = 1;
+
+DROP TABLE `comments`;
@@ -0,0 +1,28 @@
+-- The simple PostgreSQL schema after a round of changes
+
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(320) NOT NULL UNIQUE,
+    display_name VARCHAR(100),
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    CHECK (email <> '')
+);
+
+CREATE TABLE posts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    title VARCHAR(255) NOT NULL,
+    content TEXT,
+    published BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE
+);
+
+CREATE INDEX idx_posts_user_id ON posts(user_id, created_at);
+CREATE INDEX idx_posts_published ON posts(published) WHERE published = true;
+
+CREATE VIEW published_posts AS SELECT id, title FROM posts WHERE published;
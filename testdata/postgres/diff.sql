@@ -0,0 +1,29 @@
+-- Migration SQL
+-- Dialect: postgres
+
+CREATE TABLE "tags" (
+    "id" SERIAL NOT NULL PRIMARY KEY,
+    "name" VARCHAR(50) NOT NULL UNIQUE
+);
+
+
+DROP INDEX "idx_comments_post_id";
+DROP INDEX "idx_posts_user_id";
+ALTER TABLE "users" RENAME COLUMN "name" TO "display_name";
+ALTER TABLE "users" DROP COLUMN "updated_at";
+ALTER TABLE "users" ALTER COLUMN "email" TYPE VARCHAR(320);
+
+ALTER TABLE "users" ALTER COLUMN "created_at" SET NOT NULL;
+
+ALTER TABLE "users" ADD CHECK (email <> '');
+
+ALTER TABLE "posts" DROP CONSTRAINT "posts_user_id_fkey";
+ALTER TABLE "posts" ALTER COLUMN "published" SET DEFAULT true;
+
+ALTER TABLE "posts" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+CREATE INDEX "idx_posts_user_id" ON "posts" ("user_id", "created_at");
+CREATE VIEW "published_posts" AS
+SELECT id, title FROM posts WHERE published;
+
+DROP TABLE "comments";
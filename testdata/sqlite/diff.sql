@@ -0,0 +1,54 @@
+-- Migration SQL
+-- Dialect: sqlite
+
+CREATE TABLE "tags" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    "name" TEXT NOT NULL UNIQUE
+);
+
+
+DROP INDEX "idx_comments_post_id";
+DROP INDEX "idx_posts_user_id";
+ALTER TABLE "users" RENAME COLUMN "name" TO "display_name";
+-- SQLite cannot alter "users" in place; rebuild it
+PRAGMA foreign_keys = OFF;
+PRAGMA legacy_alter_table = ON;
+CREATE TABLE "_users_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    "email" TEXT NOT NULL UNIQUE,
+    "display_name" TEXT,
+    "created_at" TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (email <> '')
+);
+INSERT INTO "_users_new" ("id", "email", "display_name", "created_at") SELECT "id", "email", "display_name", "created_at" FROM "users";
+DROP TABLE "users";
+ALTER TABLE "_users_new" RENAME TO "users";
+PRAGMA legacy_alter_table = OFF;
+PRAGMA foreign_key_check("users");
+PRAGMA foreign_keys = ON;
+
+-- SQLite cannot alter "posts" in place; rebuild it
+PRAGMA foreign_keys = OFF;
+PRAGMA legacy_alter_table = ON;
+CREATE TABLE "_posts_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    "user_id" INTEGER NOT NULL,
+    "title" TEXT NOT NULL,
+    "content" TEXT,
+    "published" INTEGER DEFAULT 1,
+    "created_at" TEXT DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+INSERT INTO "_posts_new" ("id", "user_id", "title", "content", "published", "created_at") SELECT "id", "user_id", "title", "content", "published", "created_at" FROM "posts";
+DROP TABLE "posts";
+ALTER TABLE "_posts_new" RENAME TO "posts";
+CREATE INDEX "idx_posts_published" ON "posts" ("published") WHERE published = 1;
+PRAGMA legacy_alter_table = OFF;
+PRAGMA foreign_key_check("posts");
+PRAGMA foreign_keys = ON;
+
+CREATE INDEX "idx_posts_user_id" ON "posts" ("user_id", "created_at");
+CREATE VIEW "published_posts" AS
+SELECT id, title FROM posts WHERE published = 1;
+
+DROP TABLE "comments";
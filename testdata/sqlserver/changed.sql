@@ -0,0 +1,34 @@
+-- The simple SQL Server schema after a round of changes
+
+CREATE TABLE users (
+    id INT IDENTITY(1,1) PRIMARY KEY,
+    email NVARCHAR(320) NOT NULL UNIQUE,
+    display_name NVARCHAR(100),
+    created_at DATETIME2 NOT NULL DEFAULT GETDATE(),
+    CHECK (email <> '')
+);
+GO
+
+CREATE TABLE posts (
+    id INT IDENTITY(1,1) PRIMARY KEY,
+    user_id INT NOT NULL,
+    title NVARCHAR(255) NOT NULL,
+    content NVARCHAR(MAX),
+    published BIT DEFAULT 1,
+    created_at DATETIME2 DEFAULT GETDATE(),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+GO
+
+CREATE TABLE tags (
+    id INT IDENTITY(1,1) PRIMARY KEY,
+    name NVARCHAR(50) NOT NULL UNIQUE
+);
+GO
+
+CREATE INDEX idx_posts_user_id ON posts(user_id, created_at);
+CREATE INDEX idx_posts_published ON posts(published) WHERE published = 1;
+GO
+
+CREATE VIEW published_posts AS SELECT id, title FROM posts WHERE published = 1;
+GO
@@ -0,0 +1,40 @@
+-- Migration SQL
+-- Dialect: sqlserver
+
+CREATE TABLE [tags] (
+    [id] INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    [name] NVARCHAR(50) NOT NULL UNIQUE
+);
+
+
+DROP INDEX [idx_comments_post_id] ON [comments];
+DROP INDEX [idx_posts_user_id] ON [posts];
+EXEC sp_rename '[users].[name]', 'display_name', 'COLUMN';
+GO
+DECLARE @sql nvarchar(max) = N'ALTER TABLE [users] DROP CONSTRAINT ' + QUOTENAME((SELECT dc.name FROM sys.default_constraints dc WHERE dc.parent_object_id = OBJECT_ID(N'[users]') AND dc.parent_column_id = COLUMNPROPERTY(OBJECT_ID(N'[users]'), N'updated_at', 'ColumnId')));
+EXEC sp_executesql @sql;
+GO
+ALTER TABLE [users] DROP COLUMN [updated_at];
+ALTER TABLE [users] ALTER COLUMN [email] NVARCHAR(320) NOT NULL;
+
+ALTER TABLE [users] ALTER COLUMN [created_at] DATETIME2 NOT NULL;
+
+ALTER TABLE [users] ADD CHECK (email <> '');
+
+GO
+DECLARE @sql nvarchar(max) = N'ALTER TABLE [posts] DROP CONSTRAINT ' + QUOTENAME((SELECT fk.name FROM sys.foreign_keys fk WHERE fk.parent_object_id = OBJECT_ID(N'[posts]') AND (SELECT STRING_AGG(COL_NAME(fc.parent_object_id, fc.parent_column_id), ',') WITHIN GROUP (ORDER BY fc.constraint_column_id) FROM sys.foreign_key_columns fc WHERE fc.constraint_object_id = fk.object_id) = N'user_id'));
+EXEC sp_executesql @sql;
+GO
+GO
+DECLARE @sql nvarchar(max) = N'ALTER TABLE [posts] DROP CONSTRAINT ' + QUOTENAME((SELECT dc.name FROM sys.default_constraints dc WHERE dc.parent_object_id = OBJECT_ID(N'[posts]') AND dc.parent_column_id = COLUMNPROPERTY(OBJECT_ID(N'[posts]'), N'published', 'ColumnId')));
+EXEC sp_executesql @sql;
+GO
+ALTER TABLE [posts] ADD DEFAULT 1 FOR [published];
+
+ALTER TABLE [posts] ADD FOREIGN KEY ([user_id]) REFERENCES [users] ([id]);
+
+CREATE INDEX [idx_posts_user_id] ON [posts] ([user_id], [created_at]);
+CREATE VIEW [published_posts] AS
+SELECT id, title FROM posts WHERE published = 1;
+
+DROP TABLE [comments];